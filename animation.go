@@ -0,0 +1,110 @@
+package tiled
+
+import "time"
+
+// ======================================================
+// Animation
+// ======================================================
+
+// Frame is a single frame of a tile animation.
+type Frame struct {
+	TileID   uint32 `xml:"tileid,attr"`   // local tile ID shown during the frame
+	Duration int32  `xml:"duration,attr"` // in milliseconds
+}
+
+// Animation is the looping sequence of frames a tileset defines for an animated tile.
+type Animation []Frame
+
+// Duration returns the length of one loop of the animation.
+func (a Animation) Duration() time.Duration {
+	var total int64
+	for _, f := range a {
+		total += int64(max(f.Duration, 0))
+	}
+	return time.Duration(total) * time.Millisecond
+}
+
+// FrameIndex returns the index of the frame shown after the elapsed time, looping the
+// animation. It returns -1 for an empty animation, and 0 if every frame has zero duration.
+func (a Animation) FrameIndex(elapsed time.Duration) int {
+	if len(a) == 0 {
+		return -1
+	}
+
+	total := a.Duration()
+	if total <= 0 {
+		return 0
+	}
+
+	t := elapsed % total
+	if t < 0 {
+		t += total
+	}
+
+	for i, f := range a {
+		t -= time.Duration(max(f.Duration, 0)) * time.Millisecond
+		if t < 0 {
+			return i
+		}
+	}
+	return len(a) - 1
+}
+
+// TileID returns the local tile ID shown after the elapsed time, or fallback for an
+// empty animation.
+func (a Animation) TileID(elapsed time.Duration, fallback uint32) uint32 {
+	i := a.FrameIndex(elapsed)
+	if i < 0 {
+		return fallback
+	}
+	return a[i].TileID
+}
+
+// ======================================================
+// Animator
+// ======================================================
+
+// Animator resolves the animated tiles of a tileset. Every animation runs off the same clock,
+// so tiles sharing an animation stay in sync, the same as in Tiled.
+type Animator struct {
+	animations map[uint32]Animation
+}
+
+// NewAnimator collects the animations of a tileset. A nil tileset yields an Animator
+// without animations.
+func NewAnimator(tsx *Tsx) *Animator {
+	a := &Animator{
+		animations: make(map[uint32]Animation),
+	}
+	if tsx == nil {
+		return a
+	}
+
+	for i := range tsx.Tiles {
+		if tsx.Tiles[i].IsAnimated() {
+			a.animations[tsx.Tiles[i].ID] = tsx.Tiles[i].Animation
+		}
+	}
+	return a
+}
+
+// IsAnimated reports whether the tileset animates a local tile ID.
+func (a *Animator) IsAnimated(tileID uint32) bool {
+	_, ok := a.animations[tileID]
+	return ok
+}
+
+// TileID returns the local tile ID to draw for a tile after the elapsed time.
+// Tiles without an animation are returned as is.
+func (a *Animator) TileID(tileID uint32, elapsed time.Duration) uint32 {
+	anim, ok := a.animations[tileID]
+	if !ok {
+		return tileID
+	}
+	return anim.TileID(elapsed, tileID)
+}
+
+// Len returns the number of animated tiles.
+func (a *Animator) Len() int {
+	return len(a.animations)
+}
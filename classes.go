@@ -0,0 +1,148 @@
+package tiled
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrUnregisteredClass = errors.New("class is not registered")
+
+// ======================================================
+// Class registry
+// ======================================================
+
+// ClassRegistry maps the class names authored in Tiled to Go constants, so gameplay code can
+// switch over a closed set of values instead of strings, and check at load time that the
+// content doesn't use classes the code doesn't handle. Use one registry per kind of class,
+// e.g. one for tiles and one for objects:
+//
+//	type Pickup int
+//
+//	const (
+//		PickupCoin Pickup = iota + 1
+//		PickupHeart
+//	)
+//
+//	var pickups = tiled.NewClassRegistry(map[string]Pickup{
+//		"coin":  PickupCoin,
+//		"heart": PickupHeart,
+//	})
+//
+//	if err := pickups.Validate(tiled.ObjectClasses(tmx)).Err(); err != nil {
+type ClassRegistry[T comparable] struct {
+	values map[string]T
+	names  map[T]string
+}
+
+// NewClassRegistry returns a registry of the given classes. The empty class is never
+// registered; objects and tiles without a class are left to the caller.
+func NewClassRegistry[T comparable](classes map[string]T) *ClassRegistry[T] {
+	r := &ClassRegistry[T]{
+		values: make(map[string]T, len(classes)),
+		names:  make(map[T]string, len(classes)),
+	}
+	for class, value := range classes {
+		if class == "" {
+			continue
+		}
+		r.values[class] = value
+		r.names[value] = class
+	}
+	return r
+}
+
+// Lookup returns the constant of a class, and false if the class isn't registered.
+func (r *ClassRegistry[T]) Lookup(class string) (T, bool) {
+	value, ok := r.values[class]
+	return value, ok
+}
+
+// Class returns the class name of a constant, and false if the constant isn't registered.
+// When several classes map to the same constant, any of them is returned.
+func (r *ClassRegistry[T]) Class(value T) (string, bool) {
+	class, ok := r.names[value]
+	return class, ok
+}
+
+// Classes returns the registered class names, sorted.
+func (r *ClassRegistry[T]) Classes() []string {
+	classes := make([]string, 0, len(r.values))
+	for class := range r.values {
+		classes = append(classes, class)
+	}
+	slices.Sort(classes)
+	return classes
+}
+
+// Validate compares the classes used by some content, e.g. from TileClasses or ObjectClasses,
+// with the registered ones.
+func (r *ClassRegistry[T]) Validate(used []string) ClassReport {
+	var report ClassReport
+	seen := make(map[string]bool, len(used))
+	for _, class := range used {
+		if class == "" || seen[class] {
+			continue
+		}
+		seen[class] = true
+		if _, ok := r.values[class]; !ok {
+			report.Unregistered = append(report.Unregistered, class)
+		}
+	}
+	for _, class := range r.Classes() {
+		if !seen[class] {
+			report.Unused = append(report.Unused, class)
+		}
+	}
+	slices.Sort(report.Unregistered)
+	return report
+}
+
+// ClassReport is the result of validating content against a ClassRegistry.
+type ClassReport struct {
+	Unregistered []string // classes used by the content but not registered, sorted
+	Unused       []string // registered classes the content doesn't use, sorted
+}
+
+// Err returns an error wrapping ErrUnregisteredClass that lists the unregistered classes, or
+// nil if there are none. Unused classes are not an error, since a map rarely uses every class
+// of a game.
+func (r ClassReport) Err() error {
+	if len(r.Unregistered) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnregisteredClass, strings.Join(r.Unregistered, ", "))
+}
+
+// TileClasses returns the distinct classes of the tiles of every tileset of the map with data
+// attached, sorted.
+func TileClasses(tmx *Tmx) []string {
+	var classes []string
+	for _, tsx := range tmx.AllTilesets() {
+		for i := range tsx.Tiles {
+			classes = append(classes, tsx.Tiles[i].Class)
+		}
+	}
+	return sortedClasses(classes)
+}
+
+// ObjectClasses returns the distinct classes of the objects of every object group of the
+// map, sorted.
+func ObjectClasses(tmx *Tmx) []string {
+	var classes []string
+	for _, obj := range tmx.AllObjects() {
+		classes = append(classes, obj.Class)
+	}
+	return sortedClasses(classes)
+}
+
+// sortedClasses sorts classes and removes duplicates and the empty class.
+func sortedClasses(classes []string) []string {
+	slices.Sort(classes)
+	classes = slices.Compact(classes)
+	if len(classes) > 0 && classes[0] == "" {
+		classes = classes[1:]
+	}
+	return classes
+}
@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"compress/zlib"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -13,6 +14,11 @@ import (
 	"github.com/klauspost/compress/zstd"
 )
 
+var (
+	ErrUnsupportedEncoding    = errors.New("unsupported encoding")
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+)
+
 const (
 	FlipHorizontalFlag uint32 = 0x80000000
 	FlipVerticalFlag   uint32 = 0x40000000
@@ -40,14 +46,14 @@ func DecodeGID(gid uint32) (tileID uint32, flags FlipFlag) {
 
 func DecodeContent(content string, encoding Encoding, compression Compression) ([]uint32, error) {
 	switch encoding {
-	case EncodingCSV:
+	case EncodingCSV, EncodingXML:
+		// XML encoded data is converted to CSV when the layer is unmarshalled.
 		return decodeCSV(content)
 
 	case EncodingBase64:
 		return decodeBase64(content, compression)
 	}
-	// Note: XML encoding is not supported.
-	panic(fmt.Sprintf("unsupported encoding: %s", encoding))
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
 }
 
 func decodeCSV(content string) ([]uint32, error) {
@@ -93,7 +99,7 @@ func decodeBase64(content string, compression Compression) ([]uint32, error) {
 				return nil, err
 			}
 		default:
-			return nil, fmt.Errorf("unsupported compression: %s", compression)
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
 		}
 	}
 
@@ -163,3 +169,99 @@ func decompressZstd(data []byte) ([]byte, error) {
 
 	return decompressed.Bytes(), nil
 }
+
+// EncodeGID packs a tile ID and its flip flags into a GID, the inverse of DecodeGID.
+func EncodeGID(tileID uint32, flags FlipFlag) uint32 {
+	gid := tileID & GIDMask
+	if flags.Horizontal() {
+		gid |= FlipHorizontalFlag
+	}
+	if flags.Vertical() {
+		gid |= FlipVerticalFlag
+	}
+	if flags.Diagonal() {
+		gid |= FlipDiagonalFlag
+	}
+	if flags.Hex() {
+		gid |= RotateHexFlag
+	}
+	return gid
+}
+
+// EncodeContent encodes GIDs as layer data content, the inverse of DecodeContent.
+// XML encoded content is produced as CSV, the form Data keeps it in once unmarshalled.
+func EncodeContent(data []uint32, encoding Encoding, compression Compression) (string, error) {
+	switch encoding {
+	case EncodingCSV, EncodingXML:
+		return encodeCSV(data), nil
+
+	case EncodingBase64:
+		return encodeBase64(data, compression)
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+}
+
+func encodeCSV(data []uint32) string {
+	var sb strings.Builder
+	for i, gid := range data {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(gid), 10))
+	}
+	return sb.String()
+}
+
+func encodeBase64(data []uint32, compression Compression) (string, error) {
+	raw := make([]byte, len(data)*4)
+	for i, gid := range data {
+		raw[i*4] = byte(gid)
+		raw[i*4+1] = byte(gid >> 8)
+		raw[i*4+2] = byte(gid >> 16)
+		raw[i*4+3] = byte(gid >> 24)
+	}
+
+	var err error
+	switch compression {
+	case CompressionNone:
+	case CompressionGzip:
+		raw, err = compress(raw, func(w io.Writer) io.WriteCloser {
+			return gzip.NewWriter(w)
+		})
+	case CompressionZlib:
+		raw, err = compress(raw, func(w io.Writer) io.WriteCloser {
+			return zlib.NewWriter(w)
+		})
+	case CompressionZstd:
+		raw, err = compressZstd(raw)
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func compress(data []byte, compressFunc func(io.Writer) io.WriteCloser) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := compressFunc(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+func compressZstd(data []byte) ([]byte, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer encoder.Close()
+
+	return encoder.EncodeAll(data, nil), nil
+}
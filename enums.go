@@ -8,19 +8,22 @@ type DrawOrder uint8
 
 const (
 	DrawOrderIndex DrawOrder = iota
+	DrawOrderTopDown
 )
 
 func (do DrawOrder) String() string {
 	switch do {
 	case DrawOrderIndex:
 		return "index"
+	case DrawOrderTopDown:
+		return "topdown"
 	default:
 		return "unknown"
 	}
 }
 
 func (do DrawOrder) IsValid() bool {
-	return do >= DrawOrderIndex && do <= DrawOrderIndex
+	return do >= DrawOrderIndex && do <= DrawOrderTopDown
 }
 
 // ======================================================
@@ -64,6 +67,7 @@ type Encoding uint8
 const (
 	EncodingCSV Encoding = iota
 	EncodingBase64
+	EncodingXML // legacy <tile gid="..."/> elements
 )
 
 func (e Encoding) String() string {
@@ -72,13 +76,15 @@ func (e Encoding) String() string {
 		return "csv"
 	case EncodingBase64:
 		return "base64"
+	case EncodingXML:
+		return "xml"
 	default:
 		return "unknown"
 	}
 }
 
 func (e Encoding) IsValid() bool {
-	return e >= EncodingCSV && e <= EncodingBase64
+	return e >= EncodingCSV && e <= EncodingXML
 }
 
 // ======================================================
@@ -194,3 +200,226 @@ func (ro RenderOrder) String() string {
 func (ro RenderOrder) IsValid() bool {
 	return ro >= RenderOrderRightDown && ro <= RenderOrderLeftUp
 }
+
+// ======================================================
+// StaggerAxis
+// ======================================================
+
+// StaggerAxis is the axis along which every other row or column of a staggered or hexagonal
+// map is shifted. Tiled defaults to y.
+type StaggerAxis uint8
+
+const (
+	StaggerAxisY StaggerAxis = iota
+	StaggerAxisX
+)
+
+func (sa StaggerAxis) String() string {
+	switch sa {
+	case StaggerAxisY:
+		return "y"
+	case StaggerAxisX:
+		return "x"
+	default:
+		return "unknown"
+	}
+}
+
+func (sa StaggerAxis) IsValid() bool {
+	return sa >= StaggerAxisY && sa <= StaggerAxisX
+}
+
+// ======================================================
+// StaggerIndex
+// ======================================================
+
+// StaggerIndex selects whether the odd or the even rows or columns of a staggered or
+// hexagonal map are shifted. Tiled defaults to odd.
+type StaggerIndex uint8
+
+const (
+	StaggerIndexOdd StaggerIndex = iota
+	StaggerIndexEven
+)
+
+func (si StaggerIndex) String() string {
+	switch si {
+	case StaggerIndexOdd:
+		return "odd"
+	case StaggerIndexEven:
+		return "even"
+	default:
+		return "unknown"
+	}
+}
+
+func (si StaggerIndex) IsValid() bool {
+	return si >= StaggerIndexOdd && si <= StaggerIndexEven
+}
+
+// ======================================================
+// HAlign
+// ======================================================
+
+type HAlign uint8
+
+const (
+	HAlignLeft HAlign = iota
+	HAlignCenter
+	HAlignRight
+	HAlignJustify
+)
+
+func (ha HAlign) String() string {
+	switch ha {
+	case HAlignLeft:
+		return "left"
+	case HAlignCenter:
+		return "center"
+	case HAlignRight:
+		return "right"
+	case HAlignJustify:
+		return "justify"
+	default:
+		return "unknown"
+	}
+}
+
+func (ha HAlign) IsValid() bool {
+	return ha >= HAlignLeft && ha <= HAlignJustify
+}
+
+// ======================================================
+// VAlign
+// ======================================================
+
+type VAlign uint8
+
+const (
+	VAlignTop VAlign = iota
+	VAlignCenter
+	VAlignBottom
+)
+
+func (va VAlign) String() string {
+	switch va {
+	case VAlignTop:
+		return "top"
+	case VAlignCenter:
+		return "center"
+	case VAlignBottom:
+		return "bottom"
+	default:
+		return "unknown"
+	}
+}
+
+func (va VAlign) IsValid() bool {
+	return va >= VAlignTop && va <= VAlignBottom
+}
+
+// ======================================================
+// WangType
+// ======================================================
+
+type WangType uint8
+
+const (
+	WangTypeCorner WangType = iota
+	WangTypeEdge
+	WangTypeMixed
+)
+
+func (wt WangType) String() string {
+	switch wt {
+	case WangTypeCorner:
+		return "corner"
+	case WangTypeEdge:
+		return "edge"
+	case WangTypeMixed:
+		return "mixed"
+	default:
+		return "unknown"
+	}
+}
+
+func (wt WangType) IsValid() bool {
+	return wt >= WangTypeCorner && wt <= WangTypeMixed
+}
+
+// ======================================================
+// LayerKind
+// ======================================================
+
+type LayerKind uint8
+
+const (
+	LayerKindTile LayerKind = iota
+	LayerKindObject
+	LayerKindImage
+	LayerKindGroup
+)
+
+func (lk LayerKind) String() string {
+	switch lk {
+	case LayerKindTile:
+		return "layer"
+	case LayerKindObject:
+		return "objectgroup"
+	case LayerKindImage:
+		return "imagelayer"
+	case LayerKindGroup:
+		return "group"
+	default:
+		return "unknown"
+	}
+}
+
+func (lk LayerKind) IsValid() bool {
+	return lk >= LayerKindTile && lk <= LayerKindGroup
+}
+
+// ======================================================
+// PropertyKind
+// ======================================================
+
+// PropertyKind is the type of a property value, from the property's type attribute.
+type PropertyKind uint8
+
+const (
+	PropertyKindString PropertyKind = iota
+	PropertyKindInt
+	PropertyKindFloat
+	PropertyKindBool
+	PropertyKindColor
+	PropertyKindFile
+	PropertyKindObject
+	PropertyKindClass
+)
+
+func (pk PropertyKind) String() string {
+	switch pk {
+	case PropertyKindString:
+		return "string"
+	case PropertyKindInt:
+		return "int"
+	case PropertyKindFloat:
+		return "float"
+	case PropertyKindBool:
+		return "bool"
+	case PropertyKindColor:
+		return "color"
+	case PropertyKindFile:
+		return "file"
+	case PropertyKindObject:
+		return "object"
+	case PropertyKindClass:
+		return "class"
+	default:
+		return "unknown"
+	}
+}
+
+func (pk PropertyKind) IsValid() bool {
+	return pk >= PropertyKindString && pk <= PropertyKindClass
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/examples/shared"
+	"github.com/adm87/tiled/tilemap"
+)
+
+// The benchmark measures how long maps take to parse and decode, how much memory they hold
+// once decoded, and how long looking a tile up takes once warmed, for every layer data format
+// Tiled can export. The tiles are the example map repeated to each size, so the data
+// compresses like a real map would.
+//
+//	go run . -sizes 64,256,1024 -format markdown
+//	go run . -format csv -o baseline.csv
+//	go run . -baseline baseline.csv -tolerance 0.25
+
+type format struct {
+	encoding    tiled.Encoding
+	compression tiled.Compression
+}
+
+func (f format) String() string {
+	if f.encoding != tiled.EncodingBase64 {
+		return f.encoding.String()
+	}
+	return f.encoding.String() + "+" + f.compression.String()
+}
+
+var formats = []format{
+	{tiled.EncodingCSV, tiled.CompressionNone},
+	{tiled.EncodingBase64, tiled.CompressionNone},
+	{tiled.EncodingBase64, tiled.CompressionGzip},
+	{tiled.EncodingBase64, tiled.CompressionZlib},
+	{tiled.EncodingBase64, tiled.CompressionZstd},
+}
+
+// result is one row of the report.
+type result struct {
+	Size   int32
+	Format string
+
+	FileBytes    int
+	ParseNs      int64 // xml.Unmarshal of the whole file
+	DecodeNs     int64 // building the map and decoding every layer
+	AllocBytes   int64 // allocated per parse and decode
+	Allocs       int64
+	DecodedBytes uint64 // heap held by the decoded tiles
+	LookupNs     int64  // TileAt of one tile of a warmed map
+}
+
+var errRegression = errors.New("decode performance regressed")
+
+func main() {
+	sizes := flag.String("sizes", "64,256,1024", "comma separated map sizes, in tiles per side")
+	out := flag.String("o", "", "write the report to a file instead of stdout")
+	reportFormat := flag.String("format", "markdown", "report format: markdown or csv")
+	baseline := flag.String("baseline", "", "csv report to compare against")
+	tolerance := flag.Float64("tolerance", 0.2, "allowed slowdown against the baseline, as a fraction")
+	flag.Parse()
+
+	src := shared.MustLoadTmx(shared.TilemapExampleA)
+
+	var results []result
+	for _, field := range strings.Split(*sizes, ",") {
+		size, err := strconv.ParseInt(strings.TrimSpace(field), 10, 32)
+		if err != nil || size <= 0 {
+			log.Fatalf("invalid size %q", field)
+		}
+		for _, f := range formats {
+			r, err := measure(src, int32(size), f)
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("%dx%d %s: %.2fms", r.Size, r.Size, r.Format, float64(r.ParseNs+r.DecodeNs)/1e6)
+			results = append(results, r)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	var err error
+	switch *reportFormat {
+	case "markdown":
+		err = writeMarkdown(w, results)
+	case "csv":
+		err = writeCSV(w, results)
+	default:
+		err = fmt.Errorf("unknown report format %q", *reportFormat)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *baseline != "" {
+		if err := compare(*baseline, results, *tolerance); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// ====================== Measure =====================
+
+func measure(src *tiled.Tmx, size int32, f format) (result, error) {
+	tmx, err := generate(src, size, f)
+	if err != nil {
+		return result{}, err
+	}
+	file, err := tiled.MarshalTmx(tmx)
+	if err != nil {
+		return result{}, err
+	}
+
+	parse := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := parseFile(file, tmx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// Decoding is timed apart from parsing, on a file parsed outside the timer.
+	var decodeErr error
+	decode := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			b.StopTimer()
+			parsed, err := parseFile(file, tmx)
+			if err != nil {
+				decodeErr = err
+				return
+			}
+			b.StartTimer()
+
+			tm := tilemap.NewMap()
+			if err := decodeMap(tm, parsed); err != nil {
+				decodeErr = err
+				return
+			}
+			tm.Release()
+		}
+	})
+	if decodeErr != nil {
+		return result{}, decodeErr
+	}
+
+	decoded, err := decodedBytes(file, tmx)
+	if err != nil {
+		return result{}, err
+	}
+
+	lookup, err := lookupNs(file, tmx)
+	if err != nil {
+		return result{}, err
+	}
+
+	return result{
+		Size:         size,
+		Format:       f.String(),
+		FileBytes:    len(file),
+		ParseNs:      parse.NsPerOp(),
+		DecodeNs:     decode.NsPerOp(),
+		AllocBytes:   parse.AllocedBytesPerOp() + decode.AllocedBytesPerOp(),
+		Allocs:       parse.AllocsPerOp() + decode.AllocsPerOp(),
+		DecodedBytes: decoded,
+		LookupNs:     lookup,
+	}, nil
+}
+
+// generate builds a square map with the tile layers of src repeated to fill it, its layer
+// data stored in a format.
+func generate(src *tiled.Tmx, size int32, f format) (*tiled.Tmx, error) {
+	tmx := *src
+	tmx.Width, tmx.Height = size, size
+	tmx.Layers = make([]tiled.Layer, len(src.Layers))
+
+	for i := range src.Layers {
+		gids, err := tiled.DecodeContent(src.Layers[i].Data.Content, src.Layers[i].Data.Encoding, src.Layers[i].Data.Compression)
+		if err != nil {
+			return nil, err
+		}
+
+		tiles := make([]uint32, size*size)
+		for y := range size {
+			for x := range size {
+				tiles[y*size+x] = gids[(y%src.Height)*src.Width+x%src.Width]
+			}
+		}
+
+		content, err := tiled.EncodeContent(tiles, f.encoding, f.compression)
+		if err != nil {
+			return nil, err
+		}
+
+		layer := src.Layers[i]
+		layer.Width, layer.Height = size, size
+		layer.Data = tiled.Data{Encoding: f.encoding, Compression: f.compression, Content: content}
+		tmx.Layers[i] = layer
+	}
+	return &tmx, nil
+}
+
+// parseFile unmarshals a generated map. Its tilesets are external and the benchmark doesn't
+// measure loading them, so they are taken from the map the file was generated from.
+func parseFile(file []byte, generated *tiled.Tmx) (*tiled.Tmx, error) {
+	var tmx tiled.Tmx
+	if err := xml.Unmarshal(file, &tmx); err != nil {
+		return nil, err
+	}
+	for i := range tmx.Tilesets {
+		tmx.Tilesets[i].Tsx = generated.Tilesets[i].Tsx
+	}
+	return &tmx, nil
+}
+
+func decodeMap(tm *tilemap.Map, tmx *tiled.Tmx) error {
+	if err := tm.SetTmx(tmx); err != nil {
+		return err
+	}
+	return tm.DecodeAllChunks()
+}
+
+// decodedBytes returns how much the heap grows by when a parsed map is decoded.
+func decodedBytes(file []byte, generated *tiled.Tmx) (uint64, error) {
+	tmx, err := parseFile(file, generated)
+	if err != nil {
+		return 0, err
+	}
+
+	// Two collections empty the pools the tilemap package recycles buffers through, so the
+	// decoded map can't reuse memory freed by the benchmarks.
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	tm := tilemap.NewMap()
+	if err := decodeMap(tm, tmx); err != nil {
+		return 0, err
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(tm)
+
+	if after.HeapAlloc < before.HeapAlloc {
+		return 0, nil
+	}
+	return after.HeapAlloc - before.HeapAlloc, nil
+}
+
+// lookupNs returns how long TileAt takes per tile on a parsed map, decoded and warmed, when
+// sweeping every tile of every layer.
+func lookupNs(file []byte, generated *tiled.Tmx) (int64, error) {
+	tmx, err := parseFile(file, generated)
+	if err != nil {
+		return 0, err
+	}
+
+	tm := tilemap.NewMap()
+	if err := decodeMap(tm, tmx); err != nil {
+		return 0, err
+	}
+	defer tm.Release()
+	if err := tm.Warm(tilemap.Region{MaxX: tmx.Width, MaxY: tmx.Height}); err != nil {
+		return 0, err
+	}
+
+	sweep := testing.Benchmark(func(b *testing.B) {
+		for b.Loop() {
+			for layer := range tmx.Layers {
+				for y := range tmx.Height {
+					for x := range tmx.Width {
+						tm.TileAt(layer, x, y)
+					}
+				}
+			}
+		}
+	})
+	return sweep.NsPerOp() / int64(len(tmx.Layers)*int(tmx.Width*tmx.Height)), nil
+}
+
+// ====================== Report =====================
+
+func writeMarkdown(w io.Writer, results []result) error {
+	if _, err := fmt.Fprintln(w, "| Size | Format | File | Parse | Decode | Allocated | Allocs | Decoded | Lookup |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "|---:|---|---:|---:|---:|---:|---:|---:|---:|"); err != nil {
+		return err
+	}
+	for _, r := range results {
+		_, err := fmt.Fprintf(w, "| %dx%d | %s | %s | %.2fms | %.2fms | %s | %d | %s | %dns |\n",
+			r.Size, r.Size, r.Format, formatBytes(uint64(r.FileBytes)),
+			float64(r.ParseNs)/1e6, float64(r.DecodeNs)/1e6,
+			formatBytes(uint64(r.AllocBytes)), r.Allocs, formatBytes(r.DecodedBytes), r.LookupNs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var csvHeader = []string{"size", "format", "file_bytes", "parse_ns", "decode_ns", "alloc_bytes", "allocs", "decoded_bytes", "lookup_ns"}
+
+func writeCSV(w io.Writer, results []result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range results {
+		record := []string{
+			strconv.Itoa(int(r.Size)),
+			r.Format,
+			strconv.Itoa(r.FileBytes),
+			strconv.FormatInt(r.ParseNs, 10),
+			strconv.FormatInt(r.DecodeNs, 10),
+			strconv.FormatInt(r.AllocBytes, 10),
+			strconv.FormatInt(r.Allocs, 10),
+			strconv.FormatUint(r.DecodedBytes, 10),
+			strconv.FormatInt(r.LookupNs, 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
+
+// ====================== Baseline =====================
+
+// compare checks the parse and decode times against a csv report from an earlier run. Rows
+// missing from either side are skipped, so the sizes measured may change between runs.
+func compare(path string, results []result, tolerance float64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	baseline := make(map[string]int64, len(records))
+	for _, record := range records[min(1, len(records)):] {
+		// Reports from before the lookup column have one column less.
+		if len(record) != len(csvHeader) && len(record) != len(csvHeader)-1 {
+			return fmt.Errorf("%s: malformed row %v", path, record)
+		}
+		parse, err1 := strconv.ParseInt(record[3], 10, 64)
+		decode, err2 := strconv.ParseInt(record[4], 10, 64)
+		if err := errors.Join(err1, err2); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		baseline[record[0]+"/"+record[1]] = parse + decode
+	}
+
+	var regressions []string
+	for _, r := range results {
+		base, ok := baseline[strconv.Itoa(int(r.Size))+"/"+r.Format]
+		if !ok || base == 0 {
+			continue
+		}
+		ratio := float64(r.ParseNs+r.DecodeNs) / float64(base)
+		if ratio > 1+tolerance {
+			regressions = append(regressions, fmt.Sprintf("%dx%d %s: %.0f%% slower", r.Size, r.Size, r.Format, (ratio-1)*100))
+		}
+	}
+	if len(regressions) > 0 {
+		return fmt.Errorf("%w:\n%s", errRegression, strings.Join(regressions, "\n"))
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ module github.com/adm87/tiled/examples/ebiten
 
 go 1.25.2
 
+replace github.com/adm87/tiled => ../../
+
 replace github.com/adm87/tiled/examples/shared => ../shared
 
 require (
@@ -11,7 +13,6 @@ require (
 )
 
 require (
-	github.com/adm87/enum v0.0.1 // indirect
 	github.com/ebitengine/gomobile v0.0.0-20250923094054-ea854a63cce1 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
 	github.com/ebitengine/purego v0.9.0 // indirect
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"image"
 	"math"
+	"time"
 
 	"github.com/adm87/tiled"
 	"github.com/adm87/tiled/examples/shared"
+	"github.com/adm87/tiled/tilemap"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -31,6 +33,11 @@ func (c *Camera) Viewport() (minX, minY, maxX, maxY int32) {
 	return int32(left), int32(top), int32(left + 2*halfW), int32(top + 2*halfH)
 }
 
+func (c *Camera) Frame() image.Rectangle {
+	minX, minY, maxX, maxY := c.Viewport()
+	return image.Rect(int(minX), int(minY), int(maxX), int(maxY))
+}
+
 func (c *Camera) ViewMatrix() ebiten.GeoM {
 	m := ebiten.GeoM{}
 	m.Translate(-float64(c.X), -float64(c.Y))
@@ -47,15 +54,22 @@ func (c *Camera) ClampToMapBounds(mapMinX, mapMinY, mapMaxX, mapMaxY int32) {
 }
 
 type Game struct {
-	tilemap    *tiled.Tilemap
-	camera     Camera
-	op         ebiten.DrawImageOptions
-	currentMap int
+	tilemap     *tilemap.Map
+	camera      Camera
+	op          ebiten.DrawImageOptions
+	currentMap  int
+	placeholder *ebiten.Image // filled with tilemap.PlaceholderColor
+}
+
+// materialTints stands in for per-layer shaders. Layers select one by setting the
+// "material" property in Tiled, which the iterator exposes without looking up the layer.
+var materialTints = map[string][3]float32{
+	"water": {0.6, 0.8, 1.0},
+	"glow":  {1.2, 1.2, 0.9},
 }
 
 var (
 	loadedTmx = make([]*tiled.Tmx, 0)
-	loadedTsx = make(map[string]*tiled.Tsx)
 	loadedImg = make(map[string]*ebiten.Image)
 )
 
@@ -68,27 +82,38 @@ func NewGame() *Game {
 			Height: screenHeight,
 			Zoom:   1,
 		},
-		tilemap: tiled.NewTilemap(),
+		tilemap: tilemap.NewMap(),
 		op:      ebiten.DrawImageOptions{},
 	}
 }
 
 func main() {
-	loadedTmx = append(loadedTmx, shared.MustLoadTiledAsset[tiled.Tmx](shared.TilemapExampleA))
-	loadedTmx = append(loadedTmx, shared.MustLoadTiledAsset[tiled.Tmx](shared.TilemapExampleB))
-
-	loadedTsx[shared.TilesetCharacters] = shared.MustLoadTiledAsset[tiled.Tsx](shared.TilesetCharacters)
-	loadedTsx[shared.TilesetTiles] = shared.MustLoadTiledAsset[tiled.Tsx](shared.TilesetTiles)
+	// The loader attaches the tilesets referenced by each map to its Tilesets.
+	loadedTmx = append(loadedTmx, shared.MustLoadTmx(shared.TilemapExampleA))
+	loadedTmx = append(loadedTmx, shared.MustLoadTmx(shared.TilemapExampleB))
+
+	for _, tmx := range loadedTmx {
+		for _, ts := range tmx.Tilesets {
+			if err := ts.Tsx.Validate(); err != nil {
+				println(ts.Source + ": " + err.Error())
+			}
+		}
+	}
 
 	loadedImg[shared.TilemapPacked] = mustLoadImage(shared.TilemapPacked)
 	loadedImg[shared.TilemapCharactersPacked] = mustLoadImage(shared.TilemapCharactersPacked)
 
 	game := NewGame()
 	// A Tmx reference must be set in the tilemap before using it.
-	// The tilemap will panic if it doesn't have a Tmx before attempting to draw tiles.
-	game.tilemap.SetTmx(loadedTmx[0])
+	// Buffering a frame without a Tmx returns tilemap.ErrNoTmxData.
+	if err := game.tilemap.SetTmx(loadedTmx[0]); err != nil {
+		panic(err)
+	}
+
+	// Tiles whose tileset is missing show up as magenta cells instead of disappearing.
+	game.tilemap.SetFallbackTile(true)
 
-	minX, minY, maxX, maxY := game.tilemap.Bounds()
+	minX, minY, maxX, maxY := mapBounds(game.tilemap.Tmx)
 	game.camera.X = (minX + maxX) / 2
 	game.camera.Y = (minY + maxY) / 2
 
@@ -97,6 +122,10 @@ func main() {
 	}
 }
 
+func mapBounds(tmx *tiled.Tmx) (minX, minY, maxX, maxY int32) {
+	return 0, 0, tmx.Width * tmx.TileWidth, tmx.Height * tmx.TileHeight
+}
+
 func mustLoadImage(filename string) *ebiten.Image {
 	data := shared.MustLoadImageAsset(filename)
 	img, _, err := ebitenutil.NewImageFromReader(bytes.NewReader(data))
@@ -146,27 +175,40 @@ func (g *Game) Update() error {
 		// Reuse an existing tilemap to avoid allocations.
 		// The tilemap.SetTmx method will clear any existing data.
 		// This is more efficient than creating a new tilemap each time.
-		g.tilemap.SetTmx(loadedTmx[g.currentMap])
+		if err := g.tilemap.SetTmx(loadedTmx[g.currentMap]); err != nil {
+			return err
+		}
 	}
 
-	g.camera.ClampToMapBounds(g.tilemap.Bounds())
+	g.camera.ClampToMapBounds(mapBounds(g.tilemap.Tmx))
+	g.tilemap.Update(time.Second / time.Duration(ebiten.TPS()))
 
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	// GetTiles() returns an iterator that yields tiles for each layer in the Tmx.
-	// Calls to Next() will return the tiles for the next layer. If nil is returned,
-	// there are no more layers.
+	// BufferFrame() collects the tiles within the frame for each layer in the Tmx.
+	// Calls to Next() on the iterator will return the tiles for the next layer. If nil
+	// is returned, there are no more layers.
 	// This allows drawing tiles in layer order without needing to sort them manually.
 	// Layer order is determined by the order they are defined within the Tmx file.
-	itr, err := g.tilemap.GetTiles(g.camera.Viewport())
-	if err != nil {
+	g.tilemap.Frame().SetRectangle(g.camera.Frame())
+	if err := g.tilemap.BufferFrame(); err != nil {
 		panic(err)
 	}
+
+	itr := g.tilemap.Itr()
 	for tiles := itr.Next(); tiles != nil; tiles = itr.Next() {
-		for _, tile := range tiles {
-			g.DrawTile(screen, &tile)
+		// Per-layer render state comes from the iterator, so there is no need to
+		// look up the Tmx layer the tiles belong to.
+		g.op.ColorScale.Reset()
+		if tint, ok := materialTints[itr.Material()]; ok {
+			g.op.ColorScale.Scale(tint[0], tint[1], tint[2], 1)
+		}
+		g.op.ColorScale.ScaleAlpha(itr.Visibility() * itr.Opacity())
+
+		for i := range tiles {
+			g.DrawTile(screen, &tiles[i])
 		}
 	}
 }
@@ -175,53 +217,58 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return screenWidth, screenHeight
 }
 
-func (g *Game) DrawTile(screen *ebiten.Image, tile *tiled.TileData) {
+func (g *Game) DrawTile(screen *ebiten.Image, tile *tilemap.Data) {
+	if tile.IsPlaceholder() {
+		g.DrawPlaceholder(screen, tile)
+		return
+	}
+
 	tileset, err := g.tilemap.GetTileset(tile.TsIdx)
 	if err != nil {
 		println(err.Error())
 		return
 	}
 
-	tsx, exists := loadedTsx[tileset.Source]
-	if !exists {
-		println("missing tsx: " + tileset.Source)
+	// The map resolved the tile, but the renderer may still lack its image.
+	tsx := tileset.Tsx
+	if tsx == nil {
+		g.DrawPlaceholder(screen, tile)
 		return
 	}
 
 	img, exists := loadedImg[tsx.Image.Source]
 	if !exists {
-		println("missing image: " + tsx.Image.Source)
+		g.DrawPlaceholder(screen, tile)
 		return
 	}
 
-	srcX := (int32(tile.TileID) % tsx.Columns) * tsx.TileWidth
-	srcY := (int32(tile.TileID) / tsx.Columns) * tsx.TileHeight
-	srcRect := image.Rect(int(srcX), int(srcY), int(srcX+tsx.TileWidth), int(srcY+tsx.TileHeight))
+	srcRect := tsx.TileBounds(tile.TileID)
 
-	distX := float64(tile.X) + float64(tsx.TileOffset.X)
-	distY := float64(tile.Y) + float64(tsx.TileOffset.Y)
-	distY -= float64(tsx.TileHeight) - float64(g.tilemap.Tmx.TileHeight) // Align to bottom of tile
+	m := g.tilemap.TileTransform(tile, tsx)
 
 	g.op.GeoM.Reset()
+	g.op.GeoM.SetElement(0, 0, m[0])
+	g.op.GeoM.SetElement(0, 1, m[1])
+	g.op.GeoM.SetElement(1, 0, m[2])
+	g.op.GeoM.SetElement(1, 1, m[3])
+	g.op.GeoM.SetElement(0, 2, m[4])
+	g.op.GeoM.SetElement(1, 2, m[5])
+	g.op.GeoM.Concat(g.camera.ViewMatrix())
 
-	if tile.FlipFlag.Diagonal() {
-		g.op.GeoM.Rotate(math.Pi * 0.5)
-		g.op.GeoM.Scale(-1, 1)
-		g.op.GeoM.Translate(float64(tsx.TileHeight-tsx.TileWidth), 0)
-	}
-
-	if tile.FlipFlag.Horizontal() {
-		g.op.GeoM.Scale(-1, 1)
-		g.op.GeoM.Translate(float64(tsx.TileWidth), 0)
-	}
+	screen.DrawImage(img.SubImage(srcRect).(*ebiten.Image), &g.op)
+}
 
-	if tile.FlipFlag.Vertical() {
-		g.op.GeoM.Scale(1, -1)
-		g.op.GeoM.Translate(0, float64(tsx.TileHeight))
+// DrawPlaceholder fills the cell of a tile that can't be drawn, so missing content stands out.
+func (g *Game) DrawPlaceholder(screen *ebiten.Image, tile *tilemap.Data) {
+	if g.placeholder == nil {
+		g.placeholder = ebiten.NewImage(1, 1)
+		g.placeholder.Fill(tilemap.PlaceholderColor)
 	}
 
-	g.op.GeoM.Translate(distX, distY)
+	g.op.GeoM.Reset()
+	g.op.GeoM.Scale(float64(g.tilemap.Tmx.TileWidth), float64(g.tilemap.Tmx.TileHeight))
+	g.op.GeoM.Translate(float64(tile.X), float64(tile.Y))
 	g.op.GeoM.Concat(g.camera.ViewMatrix())
 
-	screen.DrawImage(img.SubImage(srcRect).(*ebiten.Image), &g.op)
+	screen.DrawImage(g.placeholder, &g.op)
 }
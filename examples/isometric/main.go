@@ -0,0 +1,247 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/examples/shared"
+	"github.com/adm87/tiled/tilemap"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	screenWidth  = 800 * 0.4
+	screenHeight = 600 * 0.4
+)
+
+type Camera struct {
+	X, Y          int32
+	Width, Height int32
+	Zoom          float64
+}
+
+func (c *Camera) Viewport() (minX, minY, maxX, maxY int32) {
+	halfW := float64(c.Width) / (2 * c.Zoom)
+	halfH := float64(c.Height) / (2 * c.Zoom)
+	left := float64(c.X) - halfW
+	top := float64(c.Y) - halfH
+	return int32(left), int32(top), int32(left + 2*halfW), int32(top + 2*halfH)
+}
+
+// View returns the viewport in world space, the form tilemap.Map.IsoFrame expects.
+func (c *Camera) View() [4]float32 {
+	minX, minY, maxX, maxY := c.Viewport()
+	return [4]float32{float32(minX), float32(minY), float32(maxX), float32(maxY)}
+}
+
+func (c *Camera) ViewMatrix() ebiten.GeoM {
+	m := ebiten.GeoM{}
+	m.Translate(-float64(c.X), -float64(c.Y))
+	m.Scale(c.Zoom, c.Zoom)
+	m.Translate(float64(c.Width)/2, float64(c.Height)/2)
+	return m
+}
+
+func (c *Camera) ClampToMapBounds(mapMinX, mapMinY, mapMaxX, mapMaxY int32) {
+	halfW := float64(c.Width) / (2 * c.Zoom)
+	halfH := float64(c.Height) / (2 * c.Zoom)
+	c.X = int32(math.Max(float64(mapMinX)+halfW, math.Min(float64(c.X), float64(mapMaxX)-halfW)))
+	c.Y = int32(math.Max(float64(mapMinY)+halfH, math.Min(float64(c.Y), float64(mapMaxY)-halfH)))
+}
+
+type Game struct {
+	tilemap *tilemap.Map
+	camera  Camera
+	op      ebiten.DrawImageOptions
+}
+
+// tileImages holds a generated image for each tile class of the fixture tileset, so the
+// example doesn't need an isometric tileset image.
+var tileImages = make(map[string]*ebiten.Image)
+
+var tileColors = map[string]color.RGBA{
+	"grass":   {0x5a, 0x9e, 0x4b, 0xff},
+	"flowers": {0x7c, 0xb3, 0x5a, 0xff},
+	"path":    {0xc8, 0xa8, 0x6b, 0xff},
+	"block":   {0x8a, 0x8f, 0x9c, 0xff},
+}
+
+func NewGame() *Game {
+	return &Game{
+		camera: Camera{
+			Width:  screenWidth,
+			Height: screenHeight,
+			Zoom:   1,
+		},
+		tilemap: tilemap.NewMap(),
+		op:      ebiten.DrawImageOptions{},
+	}
+}
+
+func main() {
+	tmx := shared.MustLoadTmx(shared.TilemapIsometric)
+	if tmx.Orientation != tiled.OrientationIsometric {
+		panic("expected an isometric map")
+	}
+
+	tsx := tmx.Tilesets[0].Tsx
+	for i := range tsx.Tiles {
+		tile := &tsx.Tiles[i]
+		tileImages[tile.Class] = newTileImage(tile.Class, tsx.TileWidth, tsx.TileHeight, tmx.TileHeight)
+	}
+
+	game := NewGame()
+	if err := game.tilemap.SetTmx(tmx); err != nil {
+		panic(err)
+	}
+
+	minX, minY, maxX, maxY := mapBounds(tmx)
+	game.camera.X = (minX + maxX) / 2
+	game.camera.Y = (minY + maxY) / 2
+
+	if err := ebiten.RunGame(game); err != nil {
+		panic(err)
+	}
+}
+
+// mapBounds returns the world bounds of the isometric map: a diamond whose left corner
+// is at x 0 and top corner at y 0.
+func mapBounds(tmx *tiled.Tmx) (minX, minY, maxX, maxY int32) {
+	span := tmx.Width + tmx.Height
+	return 0, 0, span * tmx.TileWidth / 2, span * tmx.TileHeight / 2
+}
+
+// newTileImage draws a diamond, or a block for the "block" class, bottom aligned in a
+// tile image the size of the tileset's tiles.
+func newTileImage(class string, width, height, gridHeight int32) *ebiten.Image {
+	base := tileColors[class]
+
+	// The top face sits on the cell for flat tiles and fills the image for blocks.
+	top := float64(height - gridHeight)
+	if class == "block" {
+		top = 0
+	}
+
+	pixels := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	halfW := float64(width) / 2
+	halfH := float64(gridHeight) / 2
+	for y := range height {
+		for x := range width {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			dx := math.Abs(px-halfW) / halfW
+
+			switch {
+			case dx+math.Abs(py-top-halfH)/halfH <= 1:
+				pixels.SetRGBA(int(x), int(y), base)
+			case top == 0 && py > halfH && py <= float64(height)-halfH*dx:
+				pixels.SetRGBA(int(x), int(y), shade(base, px < halfW))
+			}
+		}
+	}
+	return ebiten.NewImageFromImage(pixels)
+}
+
+func shade(c color.RGBA, left bool) color.RGBA {
+	f := uint16(160)
+	if left {
+		f = 200
+	}
+	return color.RGBA{uint8(uint16(c.R) * f / 255), uint8(uint16(c.G) * f / 255), uint8(uint16(c.B) * f / 255), c.A}
+}
+
+func (g *Game) Update() error {
+	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
+		return ebiten.Termination
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+		g.camera.X -= 2
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+		g.camera.X += 2
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
+		g.camera.Y -= 2
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
+		g.camera.Y += 2
+	}
+
+	if _, y := ebiten.Wheel(); y != 0 {
+		g.camera.Zoom = min(max(g.camera.Zoom+y*0.1, 1), 4)
+	}
+
+	g.camera.ClampToMapBounds(mapBounds(g.tilemap.Tmx))
+	return nil
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	// The frame of an isometric map buffers the tiles whose diamonds it intersects. Tiles
+	// taller than the grid reach above their diamond, which the margin accounts for.
+	tsx := g.tilemap.Tmx.Tilesets[0].Tsx
+	margin := float32(tsx.TileHeight - g.tilemap.Tmx.TileHeight)
+
+	g.tilemap.Frame().Set(g.tilemap.IsoFrame(g.camera.View(), margin))
+	if err := g.tilemap.BufferFrame(); err != nil {
+		panic(err)
+	}
+
+	itr := g.tilemap.Itr()
+	for tiles := itr.Next(); tiles != nil; tiles = itr.Next() {
+		// The iterator returns tiles chunk by chunk. Isometric tiles overlap their
+		// neighbours, so they must be drawn back to front.
+		g.tilemap.SortIsometric(tiles)
+
+		g.op.ColorScale.Reset()
+		g.op.ColorScale.ScaleAlpha(itr.Visibility() * itr.Opacity())
+
+		for i := range tiles {
+			g.DrawTile(screen, &tiles[i])
+		}
+	}
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+func (g *Game) DrawTile(screen *ebiten.Image, tile *tilemap.Data) {
+	tileset, err := g.tilemap.GetTileset(tile.TsIdx)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	tsx := tileset.Tsx
+	tsxTile := tsx.TileByID(tile.TileID)
+	if tsxTile == nil {
+		return
+	}
+
+	img, exists := tileImages[tsxTile.Class]
+	if !exists {
+		println("missing image: " + tsxTile.Class)
+		return
+	}
+
+	// Buffered tiles are positioned at the top-left of their diamond's bounding box. Tile
+	// images are aligned to its left and bottom edges, the same as in Tiled.
+	g.op.GeoM.Reset()
+	g.op.GeoM.Translate(
+		float64(tile.X),
+		float64(tile.Y)+float64(g.tilemap.Tmx.TileHeight-tsx.TileHeight),
+	)
+	g.op.GeoM.Concat(g.camera.ViewMatrix())
+
+	screen.DrawImage(img, &g.op)
+}
@@ -0,0 +1,23 @@
+module github.com/adm87/tiled/examples/physics
+
+go 1.25.2
+
+replace github.com/adm87/tiled => ../../
+
+replace github.com/adm87/tiled/examples/shared => ../shared
+
+require (
+	github.com/adm87/tiled v0.1.3
+	github.com/adm87/tiled/examples/shared v0.0.0-00010101000000-000000000000
+	github.com/hajimehoshi/ebiten/v2 v2.9.1
+)
+
+require (
+	github.com/ebitengine/gomobile v0.0.0-20250923094054-ea854a63cce1 // indirect
+	github.com/ebitengine/hideconsole v1.0.0 // indirect
+	github.com/ebitengine/purego v0.9.0 // indirect
+	github.com/jezek/xgb v1.1.1 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+)
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/examples/shared"
+	"github.com/adm87/tiled/tilemap"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	screenWidth  = 800 * 0.6
+	screenHeight = 600 * 0.6
+
+	// solidLayer is the layer the player collides with.
+	solidLayer = 0
+
+	gravity   = 0.35
+	jumpSpeed = 6
+	runSpeed  = 2
+)
+
+// spawn places the player in an open column of the map.
+var spawn = Capsule{X: 171, Top: 20, Bottom: 28, Radius: 6}
+
+type Game struct {
+	tilemap   *tilemap.Map
+	occluders *tilemap.Occluders
+	space     Space
+	images    map[string]*ebiten.Image
+	op        ebiten.DrawImageOptions
+
+	player   Capsule
+	velocity [2]float64
+	grounded bool
+	debug    bool
+}
+
+func main() {
+	tmx := shared.MustLoadTmx(shared.TilemapExampleA)
+
+	g := &Game{
+		tilemap: tilemap.NewMap(),
+		images: map[string]*ebiten.Image{
+			shared.TilemapPacked:           mustLoadImage(shared.TilemapPacked),
+			shared.TilemapCharactersPacked: mustLoadImage(shared.TilemapCharactersPacked),
+		},
+		player: spawn,
+		debug:  true,
+	}
+	if err := g.tilemap.SetTmx(tmx); err != nil {
+		panic(err)
+	}
+
+	// Tiles without collision shapes of their own are solid blocks. Adjacent blocks are
+	// merged into a few large rectangles, which is far cheaper for the physics engine than
+	// a body per tile. The set is kept up to date when tiles change.
+	occluders, err := g.tilemap.NewOccluders(solidLayer, func(tsIdx int, tileID uint32) bool {
+		tile := g.tilemap.Tmx.Tilesets[tsIdx].Tsx.TileByID(tileID)
+		return tile == nil || !tile.HasCollision()
+	})
+	if err != nil {
+		panic(err)
+	}
+	g.occluders = occluders
+
+	if err := ebiten.RunGame(g); err != nil {
+		panic(err)
+	}
+}
+
+func mustLoadImage(filename string) *ebiten.Image {
+	img, _, err := ebitenutil.NewImageFromReader(bytes.NewReader(shared.MustLoadImageAsset(filename)))
+	if err != nil {
+		panic(err)
+	}
+	return img
+}
+
+func (g *Game) Update() error {
+	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
+		return ebiten.Termination
+	}
+
+	g.velocity[0] = 0
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+		g.velocity[0] = -runSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+		g.velocity[0] = runSpeed
+	}
+	if g.grounded && (ebiten.IsKeyPressed(ebiten.KeySpace) || ebiten.IsKeyPressed(ebiten.KeyUp)) {
+		g.velocity[1] = -jumpSpeed
+	}
+	g.velocity[1] = min(g.velocity[1]+gravity, 8)
+
+	g.buildStatics()
+
+	g.grounded = g.space.Move(&g.player, g.velocity[0], g.velocity[1])
+	if g.grounded {
+		g.velocity[1] = 0
+	}
+
+	// Respawn when falling off the map.
+	if g.player.Top > float64(g.tilemap.Tmx.Height*g.tilemap.Tmx.TileHeight) {
+		g.player = spawn
+		g.velocity = [2]float64{}
+	}
+	return nil
+}
+
+// buildStatics collects the static bodies around the player: the merged rectangles of solid
+// tiles, plus the shapes authored in Tiled's collision editor for tiles that have them.
+func (g *Game) buildStatics() {
+	rects, err := g.occluders.Rects()
+	if err != nil {
+		panic(err)
+	}
+	g.space.Statics = g.space.Statics[:0]
+	for _, r := range rects {
+		g.space.Statics = append(g.space.Statics, [4]float64{float64(r[0]), float64(r[1]), float64(r[2]), float64(r[3])})
+	}
+
+	// Only tiles near the player can collide with it, so the per-tile shapes come from a
+	// small frame around it.
+	b := g.player.Bounds()
+	const reach = 32
+	g.tilemap.Frame().Set([4]float32{float32(b[0] - reach), float32(b[1] - reach), float32(b[2] + reach), float32(b[3] + reach)})
+	if err := g.tilemap.BufferFrame(); err != nil {
+		panic(err)
+	}
+
+	itr := g.tilemap.Itr()
+	for layer := 0; ; layer++ {
+		tiles := itr.Next()
+		if tiles == nil {
+			break
+		}
+		if layer != solidLayer {
+			continue
+		}
+
+		for i := range tiles {
+			tile := &tiles[i]
+			shapes, err := g.tilemap.GetTileCollision(tile.TsIdx, tile.TileID, tile.FlipFlag)
+			if err != nil {
+				continue
+			}
+			for j := range shapes {
+				g.space.Statics = append(g.space.Statics, shapeBounds(tile, &shapes[j]))
+			}
+		}
+	}
+}
+
+// shapeBounds places a tile's collision shape in the world. Polygons are reduced to their
+// bounding box, which is all the small physics step handles; an engine with convex shapes
+// would take the points as they are.
+func shapeBounds(tile *tilemap.Data, shape *tiled.Object) [4]float64 {
+	x, y := float64(tile.X)+float64(shape.X), float64(tile.Y)+float64(shape.Y)
+	if shape.Polygon.IsEmpty() {
+		return [4]float64{x, y, x + float64(shape.Width), y + float64(shape.Height)}
+	}
+
+	bounds := [4]float64{x, y, x, y}
+	for i := range shape.Polygon.VertexCount() {
+		px, py := shape.Polygon.GetVertex(i)
+		bounds[0] = min(bounds[0], x+float64(px))
+		bounds[1] = min(bounds[1], y+float64(py))
+		bounds[2] = max(bounds[2], x+float64(px))
+		bounds[3] = max(bounds[3], y+float64(py))
+	}
+	return bounds
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	g.tilemap.Frame().SetRectangle(image.Rect(0, 0, screenWidth, screenHeight))
+	if err := g.tilemap.BufferFrame(); err != nil {
+		panic(err)
+	}
+
+	itr := g.tilemap.Itr()
+	for tiles := itr.Next(); tiles != nil; tiles = itr.Next() {
+		for i := range tiles {
+			g.DrawTile(screen, &tiles[i])
+		}
+	}
+
+	if g.debug {
+		for _, r := range g.space.Statics {
+			vector.StrokeRect(screen, float32(r[0]), float32(r[1]), float32(r[2]-r[0]), float32(r[3]-r[1]), 1, color.RGBA{0xff, 0x40, 0x40, 0xff}, false)
+		}
+	}
+
+	p := &g.player
+	vector.FillCircle(screen, float32(p.X), float32(p.Top), float32(p.Radius), color.White, true)
+	vector.FillCircle(screen, float32(p.X), float32(p.Bottom), float32(p.Radius), color.White, true)
+	vector.FillRect(screen, float32(p.X-p.Radius), float32(p.Top), float32(2*p.Radius), float32(p.Bottom-p.Top), color.White, true)
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+func (g *Game) DrawTile(screen *ebiten.Image, tile *tilemap.Data) {
+	tileset, err := g.tilemap.GetTileset(tile.TsIdx)
+	if err != nil {
+		return
+	}
+
+	tsx := tileset.Tsx
+	img, exists := g.images[tsx.Image.Source]
+	if !exists {
+		return
+	}
+
+	m := g.tilemap.TileTransform(tile, tsx)
+
+	g.op.GeoM.Reset()
+	g.op.GeoM.SetElement(0, 0, m[0])
+	g.op.GeoM.SetElement(0, 1, m[1])
+	g.op.GeoM.SetElement(1, 0, m[2])
+	g.op.GeoM.SetElement(1, 1, m[3])
+	g.op.GeoM.SetElement(0, 2, m[4])
+	g.op.GeoM.SetElement(1, 2, m[5])
+
+	screen.DrawImage(img.SubImage(tsx.TileBounds(tile.TileID)).(*ebiten.Image), &g.op)
+}
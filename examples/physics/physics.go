@@ -0,0 +1,93 @@
+package main
+
+import "math"
+
+// This file is a deliberately small physics step standing in for an engine such as resolv or
+// Chipmunk. The tilemap side of the example doesn't change with the engine: statics are
+// axis-aligned rectangles in world space, which every 2D engine can take as static bodies.
+
+// Capsule is a vertical capsule: a segment from (X, Top) to (X, Bottom) swept by Radius.
+type Capsule struct {
+	X, Top, Bottom float64
+	Radius         float64
+}
+
+// Bounds returns the capsule's bounding box as minX, minY, maxX, maxY.
+func (c *Capsule) Bounds() [4]float64 {
+	return [4]float64{c.X - c.Radius, c.Top - c.Radius, c.X + c.Radius, c.Bottom + c.Radius}
+}
+
+func (c *Capsule) translate(dx, dy float64) {
+	c.X += dx
+	c.Top += dy
+	c.Bottom += dy
+}
+
+// Space holds the static rectangles bodies collide with, rebuilt every step from the map.
+type Space struct {
+	Statics [][4]float64
+}
+
+// maxStep is the largest distance a body moves before collisions are resolved, so fast
+// bodies don't tunnel through thin statics.
+const maxStep = 4
+
+// Move moves the capsule by dx, dy and pushes it out of every static it ends up in. It
+// reports whether the capsule rests on something below it.
+func (s *Space) Move(c *Capsule, dx, dy float64) (grounded bool) {
+	steps := int(math.Ceil(math.Max(math.Abs(dx), math.Abs(dy)) / maxStep))
+	steps = max(steps, 1)
+
+	for range steps {
+		c.translate(dx/float64(steps), dy/float64(steps))
+		for _, rect := range s.Statics {
+			nx, ny, depth := penetration(c, rect)
+			if depth <= 0 {
+				continue
+			}
+			c.translate(nx*depth, ny*depth)
+			if ny < -0.5 {
+				grounded = true
+			}
+		}
+	}
+	return grounded
+}
+
+// penetration returns the direction and depth to push the capsule out of a rectangle.
+func penetration(c *Capsule, rect [4]float64) (nx, ny, depth float64) {
+	// The point of the capsule's segment closest to the rectangle.
+	py := clamp((rect[1]+rect[3])/2, math.Max(c.Top, rect[1]), math.Min(c.Bottom, rect[3]))
+	if c.Bottom < rect[1] {
+		py = c.Bottom
+	} else if c.Top > rect[3] {
+		py = c.Top
+	}
+
+	qx := clamp(c.X, rect[0], rect[2])
+	qy := clamp(py, rect[1], rect[3])
+	dx, dy := c.X-qx, py-qy
+	dist := math.Hypot(dx, dy)
+
+	if dist > 0 {
+		return dx / dist, dy / dist, c.Radius - dist
+	}
+
+	// The segment is inside the rectangle: push out along the shallowest axis.
+	left, right := c.X-rect[0], rect[2]-c.X
+	up, down := c.Bottom-rect[1], rect[3]-c.Top
+	switch math.Min(math.Min(left, right), math.Min(up, down)) {
+	case left:
+		return -1, 0, left + c.Radius
+	case right:
+		return 1, 0, right + c.Radius
+	case up:
+		return 0, -1, up + c.Radius
+	default:
+		return 0, 1, down + c.Radius
+	}
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(v, hi))
+}
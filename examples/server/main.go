@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/examples/shared"
+	"github.com/adm87/tiled/pathfind"
+	"github.com/adm87/tiled/tilemap"
+)
+
+// The service loads maps once and answers queries against them as JSON. Nothing here draws:
+// the tilemap and pathfind packages are used on their own, as a game server would.
+//
+//	GET /maps
+//	GET /tile?map=a&x=3&y=7
+//	GET /objects?map=a&minx=0&miny=0&maxx=200&maxy=200
+//	GET /path?map=a&fromx=8&fromy=0&tox=21&toy=2
+
+// solidLayer is the layer whose tiles block paths. Empty cells are walkable.
+const solidLayer = 0
+
+var errUnknownMap = errors.New("unknown map")
+
+// served is a loaded map. A Map is not safe for concurrent use, so requests on the same map
+// are serialized; requests on different maps run in parallel.
+type served struct {
+	mu    sync.Mutex
+	tm    *tilemap.Map
+	paths *pathfind.Cache
+}
+
+type server struct {
+	maps map[string]*served
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	flag.Parse()
+
+	s := &server{maps: make(map[string]*served)}
+	s.mustLoad("a", shared.TilemapExampleA)
+	s.mustLoad("b", shared.TilemapExampleB)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /maps", s.handleMaps)
+	mux.HandleFunc("GET /tile", s.handleTile)
+	mux.HandleFunc("GET /objects", s.handleObjects)
+	mux.HandleFunc("GET /path", s.handlePath)
+
+	log.Printf("Map query service at http://%s/", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (s *server) mustLoad(name, filename string) {
+	tm := tilemap.NewMap()
+	if err := tm.SetTmx(shared.MustLoadTmx(filename)); err != nil {
+		panic(err)
+	}
+
+	// Tiles on the solid layer are walls, everything else is open floor.
+	region := tilemap.Region{MaxX: tm.Tmx.Width, MaxY: tm.Tmx.Height}
+	rules := tilemap.CostRules{
+		Layers: []int{solidLayer},
+		Cost: func(int, uint32) float32 {
+			return float32(math.Inf(1))
+		},
+		Diagonal: true,
+	}
+
+	s.maps[name] = &served{
+		tm:    tm,
+		paths: pathfind.NewCache(tm, region, rules),
+	}
+}
+
+// lookup returns the map named by the request's "map" parameter, locked. The caller unlocks it.
+func (s *server) lookup(r *http.Request) (*served, error) {
+	m, ok := s.maps[r.URL.Query().Get("map")]
+	if !ok {
+		return nil, errUnknownMap
+	}
+	m.mu.Lock()
+	return m, nil
+}
+
+// ====================== Maps =====================
+
+type mapInfo struct {
+	Name       string `json:"name"`
+	Width      int32  `json:"width"`
+	Height     int32  `json:"height"`
+	TileWidth  int32  `json:"tilewidth"`
+	TileHeight int32  `json:"tileheight"`
+	Layers     int    `json:"layers"`
+}
+
+func (s *server) handleMaps(w http.ResponseWriter, r *http.Request) {
+	infos := make([]mapInfo, 0, len(s.maps))
+	for name, m := range s.maps {
+		m.mu.Lock()
+		tmx := m.tm.Tmx
+		infos = append(infos, mapInfo{
+			Name:       name,
+			Width:      tmx.Width,
+			Height:     tmx.Height,
+			TileWidth:  tmx.TileWidth,
+			TileHeight: tmx.TileHeight,
+			Layers:     len(tmx.Layers),
+		})
+		m.mu.Unlock()
+	}
+	writeJSON(w, infos)
+}
+
+// ====================== Tile =====================
+
+type tileInfo struct {
+	Layer   int    `json:"layer"`
+	Name    string `json:"name"`
+	Tileset int    `json:"tileset"`
+	TileID  uint32 `json:"tileid"`
+	Class   string `json:"class,omitempty"`
+	FlipH   bool   `json:"fliph,omitempty"`
+	FlipV   bool   `json:"flipv,omitempty"`
+}
+
+// handleTile lists the tiles stacked on a cell, bottom layer first.
+func (s *server) handleTile(w http.ResponseWriter, r *http.Request) {
+	x, y, err := intParams(r, "x", "y")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	m, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	defer m.mu.Unlock()
+
+	tmx := m.tm.Tmx
+	tiles := make([]tileInfo, 0, len(tmx.Layers))
+	for layer := range tmx.Layers {
+		// A one-cell stamp reads a gid without buffering a frame.
+		stamp, err := m.tm.CopyRegion(layer, tilemap.Region{MinX: x, MinY: y, MaxX: x + 1, MaxY: y + 1})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		tile, ok := tilemap.GetTileData(stamp.At(0, 0), tmx, float32(x), float32(y))
+		if !ok {
+			continue
+		}
+
+		info := tileInfo{
+			Layer:   layer,
+			Name:    tmx.Layers[layer].Name,
+			Tileset: tile.TsIdx,
+			TileID:  tile.TileID,
+			FlipH:   tile.FlipFlag.Horizontal(),
+			FlipV:   tile.FlipFlag.Vertical(),
+		}
+		if t := tmx.Tilesets[tile.TsIdx].Tsx.TileByID(tile.TileID); t != nil {
+			info.Class = t.Class
+		}
+		tiles = append(tiles, info)
+	}
+	writeJSON(w, tiles)
+}
+
+// ====================== Objects =====================
+
+type objectInfo struct {
+	ID     int32      `json:"id"`
+	Name   string     `json:"name,omitempty"`
+	Class  string     `json:"class,omitempty"`
+	Bounds [4]float32 `json:"bounds"`
+}
+
+// handleObjects lists the visible objects whose bounds overlap a region in pixels, using the
+// map's object index.
+func (s *server) handleObjects(w http.ResponseWriter, r *http.Request) {
+	minX, minY, err := intParams(r, "minx", "miny")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	maxX, maxY, err := intParams(r, "maxx", "maxy")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	m, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	defer m.mu.Unlock()
+
+	objects := make([]objectInfo, 0)
+	for _, obj := range m.tm.GetObjects(float32(minX), float32(minY), float32(maxX), float32(maxY), 0) {
+		objects = append(objects, objectInfo{ID: obj.ID, Name: obj.Name, Class: obj.Class, Bounds: objectBounds(obj)})
+	}
+	writeJSON(w, objects)
+}
+
+// objectBounds returns the bounds of an unrotated object in pixels. Tile objects are anchored
+// at their bottom-left corner, every other object at its top-left.
+func objectBounds(obj *tiled.Object) [4]float32 {
+	if obj.GID != 0 {
+		return [4]float32{obj.X, obj.Y - obj.Height, obj.X + obj.Width, obj.Y}
+	}
+
+	points := obj.Polygon
+	if points.IsEmpty() {
+		points = obj.Polyline
+	}
+	if points.IsEmpty() {
+		return [4]float32{obj.X, obj.Y, obj.X + obj.Width, obj.Y + obj.Height}
+	}
+
+	b := [4]float32{obj.X, obj.Y, obj.X, obj.Y}
+	for i := range points.VertexCount() {
+		px, py := points.GetVertex(i)
+		b[0] = min(b[0], obj.X+px)
+		b[1] = min(b[1], obj.Y+py)
+		b[2] = max(b[2], obj.X+px)
+		b[3] = max(b[3], obj.Y+py)
+	}
+	return b
+}
+
+// ====================== Path =====================
+
+type pathInfo struct {
+	Cost  float32    `json:"cost"`
+	Tiles [][2]int32 `json:"tiles"`
+}
+
+// handlePath returns the cheapest path between two cells, both included. The flow field
+// toward the target is cached, so repeated queries to the same target are only a walk.
+func (s *server) handlePath(w http.ResponseWriter, r *http.Request) {
+	fromX, fromY, err := intParams(r, "fromx", "fromy")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	toX, toY, err := intParams(r, "tox", "toy")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	m, err := s.lookup(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	defer m.mu.Unlock()
+
+	field, err := m.paths.FlowField(toX, toY)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	cost := field.Distance(fromX, fromY)
+	if math.IsInf(float64(cost), 1) {
+		writeError(w, http.StatusUnprocessableEntity, errors.New("no path"))
+		return
+	}
+
+	// Every step moves to a neighbor with a lower distance, so the walk ends at the target
+	// within as many steps as the field has cells.
+	path := pathInfo{Cost: cost, Tiles: [][2]int32{{fromX, fromY}}}
+	for x, y := fromX, fromY; (x != toX || y != toY) && len(path.Tiles) <= len(field.Dist); {
+		dx, dy, _ := field.Direction(x, y)
+		x += int32(math.Round(float64(dx)))
+		y += int32(math.Round(float64(dy)))
+		path.Tiles = append(path.Tiles, [2]int32{x, y})
+	}
+	writeJSON(w, path)
+}
+
+// ====================== Helpers =====================
+
+func intParams(r *http.Request, nameX, nameY string) (x, y int32, err error) {
+	query := r.URL.Query()
+	vx, err := strconv.ParseInt(query.Get(nameX), 10, 32)
+	if err != nil {
+		return 0, 0, errors.New("invalid " + nameX)
+	}
+	vy, err := strconv.ParseInt(query.Get(nameY), 10, 32)
+	if err != nil {
+		return 0, 0, errors.New("invalid " + nameY)
+	}
+	return int32(vx), int32(vy), nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -3,6 +3,7 @@ package shared
 import (
 	"embed"
 	"encoding/xml"
+	"io/fs"
 	"strings"
 
 	"github.com/adm87/tiled"
@@ -13,6 +14,7 @@ const (
 	TilemapCharactersPacked = "tilemap-characters_packed.png"
 	TilemapExampleA         = "tilemap-example-a.tmx"
 	TilemapExampleB         = "tilemap-example-b.tmx"
+	TilemapIsometric        = "tilemap-iso.tmx"
 	TilesetCharacters       = "tileset-characters.tsx"
 	TilesetTiles            = "tileset-tiles.tsx"
 )
@@ -20,6 +22,25 @@ const (
 //go:embed assets
 var assets embed.FS
 
+// Loader loads the example maps together with the tilesets and templates they reference.
+var Loader = tiled.NewLoaderFS(mustSub(assets, "assets"))
+
+func MustLoadTmx(filename string) *tiled.Tmx {
+	tmx, err := Loader.LoadTmx(filename)
+	if err != nil {
+		panic(err)
+	}
+	return tmx
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
 func LoadTiledAsset[T tiled.Tmx | tiled.Tsx | tiled.Tx](filename string) (*T, error) {
 	file, err := LoadAsset(filename)
 	if err != nil {
@@ -2,9 +2,10 @@ module github.com/adm87/tiled/examples/shared
 
 go 1.25.2
 
+replace github.com/adm87/tiled => ../../
+
 require github.com/adm87/tiled v0.1.3
 
 require (
-	github.com/adm87/enum v0.0.1 // indirect
 	github.com/klauspost/compress v1.18.0 // indirect
 )
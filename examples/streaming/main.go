@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"math"
+	"os"
+
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/examples/shared"
+	"github.com/adm87/tiled/tilemap"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	screenWidth  = 800 * 0.5
+	screenHeight = 600 * 0.5
+
+	// The generated world is worldChunks x worldChunks chunks of chunkSize tiles.
+	worldChunks = 64
+	chunkSize   = 16
+
+	// Chunks further than streamMargin tiles outside the camera are evicted.
+	streamMargin = 2 * chunkSize
+
+	// Layer edited by the brush.
+	paintLayer = 1
+
+	savePath = "streaming-save.json"
+
+	// Local tile IDs of the tiles tileset used for the ground.
+	grassTile = 122
+	dirtTile  = 104
+)
+
+// brushTiles are local tile IDs of the tiles tileset to paint with, selected with 1-5.
+var brushTiles = []uint32{22, 23, 24, 38, 104}
+
+// firstGID of the tiles tileset in the generated map.
+const firstGID = 1
+
+type Camera struct {
+	X, Y          int32
+	Width, Height int32
+	Zoom          float64
+}
+
+func (c *Camera) Viewport() (minX, minY, maxX, maxY int32) {
+	halfW := float64(c.Width) / (2 * c.Zoom)
+	halfH := float64(c.Height) / (2 * c.Zoom)
+	left := float64(c.X) - halfW
+	top := float64(c.Y) - halfH
+	return int32(left), int32(top), int32(left + 2*halfW), int32(top + 2*halfH)
+}
+
+func (c *Camera) Frame() image.Rectangle {
+	minX, minY, maxX, maxY := c.Viewport()
+	return image.Rect(int(minX), int(minY), int(maxX), int(maxY))
+}
+
+func (c *Camera) ViewMatrix() ebiten.GeoM {
+	m := ebiten.GeoM{}
+	m.Translate(-float64(c.X), -float64(c.Y))
+	m.Scale(c.Zoom, c.Zoom)
+	m.Translate(float64(c.Width)/2, float64(c.Height)/2)
+	return m
+}
+
+// ScreenToWorld converts a screen position, e.g. the cursor, to world space.
+func (c *Camera) ScreenToWorld(x, y int) (float64, float64) {
+	m := c.ViewMatrix()
+	m.Invert()
+	return m.Apply(float64(x), float64(y))
+}
+
+type Game struct {
+	tilemap *tilemap.Map
+	camera  Camera
+	op      ebiten.DrawImageOptions
+	tiles   *ebiten.Image
+
+	brush   int
+	stroke  tilemap.MapPatch   // changes of the stroke being painted
+	history []tilemap.MapPatch // finished strokes, most recent last
+	status  string
+}
+
+func NewGame(tmx *tiled.Tmx, tiles *ebiten.Image) *Game {
+	g := &Game{
+		camera: Camera{
+			Width:  screenWidth,
+			Height: screenHeight,
+			Zoom:   1,
+		},
+		tilemap: tilemap.NewMap(),
+		tiles:   tiles,
+	}
+	if err := g.tilemap.SetTmx(tmx); err != nil {
+		panic(err)
+	}
+
+	size := int32(worldChunks * chunkSize)
+	g.camera.X = size * tmx.TileWidth / 2
+	g.camera.Y = size * tmx.TileHeight / 2
+	return g
+}
+
+func main() {
+	tsx := shared.MustLoadTiledAsset[tiled.Tsx](shared.TilesetTiles)
+	tmx, err := generateWorld(tsx)
+	if err != nil {
+		panic(err)
+	}
+
+	img, _, err := ebitenutil.NewImageFromReader(bytes.NewReader(shared.MustLoadImageAsset(shared.TilemapPacked)))
+	if err != nil {
+		panic(err)
+	}
+
+	game := NewGame(tmx, img)
+
+	// Edits of a previous session are stored as a patch against the generated world,
+	// so restoring them is a matter of applying it.
+	if err := game.load(); err != nil {
+		panic(err)
+	}
+
+	if err := ebiten.RunGame(game); err != nil {
+		panic(err)
+	}
+}
+
+// generateWorld builds a large infinite map in memory. Chunks are stored compressed, the
+// way Tiled saves them, and are only decoded once the camera gets near them.
+func generateWorld(tsx *tiled.Tsx) (*tiled.Tmx, error) {
+	tmx := &tiled.Tmx{
+		Width:       chunkSize,
+		Height:      chunkSize,
+		TileWidth:   tsx.TileWidth,
+		TileHeight:  tsx.TileHeight,
+		Flags:       tiled.MapFlagInfinite,
+		Orientation: tiled.OrientationOrthogonal,
+		RenderOrder: tiled.RenderOrderRightDown,
+		Tilesets: []tiled.Tileset{
+			{FirstGID: firstGID, Source: shared.TilesetTiles, Tsx: tsx},
+		},
+		Layers: []tiled.Layer{
+			newLayer(1, "Ground"),
+			newLayer(2, "Paint"),
+		},
+	}
+
+	ground := make([]uint32, chunkSize*chunkSize)
+	empty := make([]uint32, chunkSize*chunkSize)
+	for cy := range int32(worldChunks) {
+		for cx := range int32(worldChunks) {
+			for i := range ground {
+				x := cx*chunkSize + int32(i)%chunkSize
+				y := cy*chunkSize + int32(i)/chunkSize
+				ground[i] = groundGID(x, y)
+			}
+
+			for layer, data := range [][]uint32{ground, empty} {
+				content, err := tiled.EncodeContent(data, tiled.EncodingBase64, tiled.CompressionZlib)
+				if err != nil {
+					return nil, err
+				}
+				tmx.Layers[layer].Data.Chunks = append(tmx.Layers[layer].Data.Chunks, tiled.Chunk{
+					X:       cx * chunkSize,
+					Y:       cy * chunkSize,
+					Width:   chunkSize,
+					Height:  chunkSize,
+					Content: content,
+				})
+			}
+		}
+	}
+	return tmx, nil
+}
+
+func newLayer(id int32, name string) tiled.Layer {
+	return tiled.Layer{
+		ID:         id,
+		Name:       name,
+		Flags:      tiled.LayerFlagVisible,
+		LayerAttrs: tiled.DefaultLayerAttrs(),
+		Data: tiled.Data{
+			Encoding:    tiled.EncodingBase64,
+			Compression: tiled.CompressionZlib,
+		},
+	}
+}
+
+// groundGID picks one of two ground tiles with a cheap deterministic pattern.
+func groundGID(x, y int32) uint32 {
+	if math.Sin(float64(x)*0.13)+math.Cos(float64(y)*0.17) > 1.2 {
+		return firstGID + dirtTile
+	}
+	return firstGID + grassTile
+}
+
+func (g *Game) Update() error {
+	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
+		return ebiten.Termination
+	}
+
+	speed := int32(4 / g.camera.Zoom)
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+		g.camera.X -= speed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+		g.camera.X += speed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
+		g.camera.Y -= speed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
+		g.camera.Y += speed
+	}
+	if _, y := ebiten.Wheel(); y != 0 {
+		g.camera.Zoom = min(max(g.camera.Zoom+y*0.1, 0.5), 4)
+	}
+
+	for i := range brushTiles {
+		if inpututil.IsKeyJustPressed(ebiten.Key1 + ebiten.Key(i)) {
+			g.brush = i
+		}
+	}
+
+	if err := g.paint(); err != nil {
+		return err
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		if err := g.undo(); err != nil {
+			return err
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		if err := g.save(); err != nil {
+			return err
+		}
+	}
+
+	g.stream()
+	return nil
+}
+
+// paint sets the tile under the cursor while the left button is held, or clears it with
+// the right button. Edits invalidate the buffered frame on their own, so the next
+// BufferFrame picks them up without flushing anything.
+func (g *Game) paint() error {
+	left := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	right := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+
+	if !left && !right {
+		// A stroke ends when the button is released and becomes one undo step.
+		if !g.stroke.IsEmpty() {
+			g.history = append(g.history, g.stroke)
+			g.stroke = tilemap.MapPatch{}
+		}
+		return nil
+	}
+
+	wx, wy := g.camera.ScreenToWorld(ebiten.CursorPosition())
+	x := int32(math.Floor(wx / float64(g.tilemap.Tmx.TileWidth)))
+	y := int32(math.Floor(wy / float64(g.tilemap.Tmx.TileHeight)))
+	cell := tilemap.Region{MinX: x, MinY: y, MaxX: x + 1, MaxY: y + 1}
+
+	var gid uint32
+	if left {
+		gid = firstGID + brushTiles[g.brush]
+	}
+
+	patch, err := g.tilemap.FillRegion(paintLayer, cell, gid)
+	if err != nil {
+		return err
+	}
+	g.stroke.Entries = append(g.stroke.Entries, patch.Entries...)
+	return nil
+}
+
+// undo reverts the most recent stroke by applying its inverse.
+func (g *Game) undo() error {
+	if len(g.history) == 0 {
+		return nil
+	}
+
+	last := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+
+	g.status = fmt.Sprintf("undid %d tiles", len(last.Entries))
+	return g.tilemap.ApplyPatch(last.Invert())
+}
+
+// save stores the difference between the edited map and the generated one. Edited chunks
+// are never evicted, so the patch holds every change made since the world was loaded.
+func (g *Game) save() error {
+	patch, err := g.tilemap.DiffFromOriginal()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(savePath, data, 0o644); err != nil {
+		return err
+	}
+
+	g.status = fmt.Sprintf("saved %d tiles to %s", len(patch.Entries), savePath)
+	return nil
+}
+
+func (g *Game) load() error {
+	data, err := os.ReadFile(savePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var patch tilemap.MapPatch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return err
+	}
+
+	g.status = fmt.Sprintf("restored %d tiles from %s", len(patch.Entries), savePath)
+	return g.tilemap.ApplyPatch(patch)
+}
+
+// stream releases the decoded data of chunks that are far from the camera, keeping memory
+// bounded while exploring. Evicted chunks decode again when the camera comes back.
+func (g *Game) stream() {
+	minX, minY, maxX, maxY := g.camera.Viewport()
+	tw, th := g.tilemap.Tmx.TileWidth, g.tilemap.Tmx.TileHeight
+
+	g.tilemap.EvictChunksOutside(tilemap.Region{
+		MinX: minX/tw - streamMargin,
+		MinY: minY/th - streamMargin,
+		MaxX: maxX/tw + streamMargin,
+		MaxY: maxY/th + streamMargin,
+	})
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	g.tilemap.Frame().SetRectangle(g.camera.Frame())
+	if err := g.tilemap.BufferFrame(); err != nil {
+		panic(err)
+	}
+
+	itr := g.tilemap.Itr()
+	for tiles := itr.Next(); tiles != nil; tiles = itr.Next() {
+		for i := range tiles {
+			g.DrawTile(screen, &tiles[i])
+		}
+	}
+
+	ebitenutil.DebugPrint(screen, fmt.Sprintf(
+		"decoded chunks: %d\nbrush: %d  undo: %d\n%s",
+		g.tilemap.DecodedChunkCount(), g.brush+1, len(g.history), g.status,
+	))
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+func (g *Game) DrawTile(screen *ebiten.Image, tile *tilemap.Data) {
+	tileset, err := g.tilemap.GetTileset(tile.TsIdx)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	tsx := tileset.Tsx
+	srcRect := tsx.TileBounds(tile.TileID)
+
+	m := g.tilemap.TileTransform(tile, tsx)
+
+	g.op.GeoM.Reset()
+	g.op.GeoM.SetElement(0, 0, m[0])
+	g.op.GeoM.SetElement(0, 1, m[1])
+	g.op.GeoM.SetElement(1, 0, m[2])
+	g.op.GeoM.SetElement(1, 1, m[3])
+	g.op.GeoM.SetElement(0, 2, m[4])
+	g.op.GeoM.SetElement(1, 2, m[5])
+	g.op.GeoM.Concat(g.camera.ViewMatrix())
+
+	screen.DrawImage(g.tiles.SubImage(srcRect).(*ebiten.Image), &g.op)
+}
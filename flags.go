@@ -1,6 +1,9 @@
 package tiled
 
-import "strings"
+import (
+	"math"
+	"strings"
+)
 
 // ======================================================
 // TmxFlag
@@ -69,8 +72,10 @@ type ObjectFlag uint8
 const (
 	ObjectFlagVisible ObjectFlag = 1 << iota
 	ObjectFlagTemplate
+	ObjectFlagEllipse
+	ObjectFlagPoint
 
-	objectFlagMax = ObjectFlagVisible | ObjectFlagTemplate
+	objectFlagMax = ObjectFlagVisible | ObjectFlagTemplate | ObjectFlagEllipse | ObjectFlagPoint
 )
 
 func (of ObjectFlag) String() string {
@@ -81,6 +86,12 @@ func (of ObjectFlag) String() string {
 	if of&ObjectFlagTemplate != 0 {
 		flags = append(flags, "template")
 	}
+	if of&ObjectFlagEllipse != 0 {
+		flags = append(flags, "ellipse")
+	}
+	if of&ObjectFlagPoint != 0 {
+		flags = append(flags, "point")
+	}
 	if len(flags) == 0 {
 		return "None"
 	}
@@ -145,3 +156,108 @@ func (ff FlipFlag) Diagonal() bool {
 func (ff FlipFlag) Hex() bool {
 	return ff&FlipHex != 0
 }
+
+// Canonical returns the flags that affect orthogonal tiles. The hex rotation flag is dropped,
+// since it only has meaning for hexagonal maps.
+//
+// Tiled applies the flips in a fixed order: the diagonal flip (swapping x and y) first, then the
+// horizontal flip, then the vertical flip. So horizontal|diagonal rotates a tile 90 degrees
+// clockwise, and vertical|diagonal rotates it 90 degrees counterclockwise.
+func (ff FlipFlag) Canonical() FlipFlag {
+	return ff & (FlipHorizontal | FlipVertical | FlipDiagonal)
+}
+
+// Apply returns the affine transform that flips a w by h tile image in place, applying the
+// flips in the order Tiled does (see Canonical).
+//
+// The matrix is laid out as {a, b, c, d, tx, ty}, mapping a source pixel (x, y) to
+// (a*x + b*y + tx, c*x + d*y + ty). A diagonally flipped tile is transposed and kept
+// aligned to the bottom of the w by h cell.
+func (ff FlipFlag) Apply(w, h float64) [6]float64 {
+	m := [6]float64{1, 0, 0, 1, 0, 0}
+
+	if ff.Diagonal() {
+		m = AffineMul([6]float64{0, 1, 1, 0, h - w, 0}, m)
+	}
+	if ff.Horizontal() {
+		m = AffineMul([6]float64{-1, 0, 0, 1, w, 0}, m)
+	}
+	if ff.Vertical() {
+		m = AffineMul([6]float64{1, 0, 0, -1, 0, h}, m)
+	}
+	return m
+}
+
+// ApplyHex returns the transform of a w by h tile image on a hexagonal map, laid out like
+// Apply. Hexagonal maps reuse the diagonal flag for a 60 degree clockwise rotation, and the
+// hex flag rotates by 120 degrees. The image is flipped first, then rotated about its center.
+func (ff FlipFlag) ApplyHex(w, h float64) [6]float64 {
+	m := [6]float64{1, 0, 0, 1, 0, 0}
+
+	if ff.Horizontal() {
+		m = AffineMul([6]float64{-1, 0, 0, 1, w, 0}, m)
+	}
+	if ff.Vertical() {
+		m = AffineMul([6]float64{1, 0, 0, -1, 0, h}, m)
+	}
+
+	var degrees float64
+	if ff.Diagonal() {
+		degrees += 60
+	}
+	if ff.Hex() {
+		degrees += 120
+	}
+	if degrees != 0 {
+		sin, cos := math.Sincos(degrees * math.Pi / 180)
+		cx, cy := w/2, h/2
+		m = AffineMul([6]float64{cos, -sin, sin, cos, cx - cos*cx + sin*cy, cy - sin*cx - cos*cy}, m)
+	}
+	return m
+}
+
+// AffineMul returns the transform applying m first and then op, both laid out as in
+// FlipFlag.Apply, e.g. to place a flipped tile image with a renderer's own transform.
+func AffineMul(op, m [6]float64) [6]float64 {
+	return [6]float64{
+		op[0]*m[0] + op[1]*m[2],
+		op[0]*m[1] + op[1]*m[3],
+		op[2]*m[0] + op[3]*m[2],
+		op[2]*m[1] + op[3]*m[3],
+		op[0]*m[4] + op[1]*m[5] + op[4],
+		op[2]*m[4] + op[3]*m[5] + op[5],
+	}
+}
+
+// ======================================================
+// QueryFlag
+// ======================================================
+
+// QueryFlag adjusts which objects the object query helpers return. By default, objects that
+// are hidden, or in hidden object groups, are skipped.
+type QueryFlag uint8
+
+const (
+	QueryFlagIncludeHidden QueryFlag = 1 << iota
+	QueryFlagExcludeLocked
+
+	queryFlagMax = QueryFlagIncludeHidden | QueryFlagExcludeLocked
+)
+
+func (qf QueryFlag) String() string {
+	var flags []string
+	if qf&QueryFlagIncludeHidden != 0 {
+		flags = append(flags, "includehidden")
+	}
+	if qf&QueryFlagExcludeLocked != 0 {
+		flags = append(flags, "excludelocked")
+	}
+	if len(flags) == 0 {
+		return "None"
+	}
+	return strings.Join(flags, "|")
+}
+
+func (qf QueryFlag) IsValid() bool {
+	return qf&^queryFlagMax == 0
+}
@@ -0,0 +1,149 @@
+package tiled
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrGIDRange = errors.New("invalid tileset gid range")
+
+// ======================================================
+// GID ranges
+// ======================================================
+
+// Validate checks the GID ranges of the map's tilesets. Tiles resolve to the tileset with the
+// highest firstgid at or below their GID, so the ranges must start above 0, be in ascending
+// order, and not overlap. Overlaps are only detected when the tileset data is attached, since
+// the range of a tileset ends at its last tile. The error wraps ErrGIDRange and lists every
+// problem found.
+func (t *Tmx) Validate() error {
+	var errs []error
+	for i := range t.Tilesets {
+		ts := &t.Tilesets[i]
+		if ts.FirstGID == 0 {
+			errs = append(errs, fmt.Errorf("%w: tileset %d has firstgid 0", ErrGIDRange, i))
+			continue
+		}
+		if i == 0 {
+			continue
+		}
+
+		prev := &t.Tilesets[i-1]
+		switch {
+		case ts.FirstGID <= prev.FirstGID:
+			errs = append(errs, fmt.Errorf("%w: tileset %d has firstgid %d, not above %d of tileset %d",
+				ErrGIDRange, i, ts.FirstGID, prev.FirstGID, i-1))
+		case prev.Tsx != nil && prev.FirstGID+prev.gidCount() > ts.FirstGID:
+			errs = append(errs, fmt.Errorf("%w: tileset %d has firstgid %d, within the range of tileset %d ending at %d",
+				ErrGIDRange, i, ts.FirstGID, i-1, prev.FirstGID+prev.gidCount()-1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RepairGIDRanges re-bases the tilesets of a map so their GID ranges follow one another in
+// document order, starting at 1, and rewrites the GIDs of every tile layer and tile object to
+// match. Each GID keeps the tile it resolves to before the repair, the way TilesetByGID
+// resolves it, along with its flip flags; GIDs below every tileset are left as is.
+//
+// Every tileset needs its data attached to know its range. The map is left untouched if any
+// tileset is missing its data, or any layer fails to decode. Maps already held by a
+// tilemap.Map must be set again after the repair.
+func RepairGIDRanges(tmx *Tmx) error {
+	oldFirst := make([]uint32, len(tmx.Tilesets))
+	newFirst := make([]uint32, len(tmx.Tilesets))
+	next := uint32(1)
+	for i := range tmx.Tilesets {
+		ts := &tmx.Tilesets[i]
+		if ts.Tsx == nil {
+			return fmt.Errorf("%w: tileset %d has no data attached", ErrGIDRange, i)
+		}
+		oldFirst[i] = ts.FirstGID
+		newFirst[i] = next
+		next += ts.gidCount()
+	}
+
+	remap := func(gid uint32) uint32 {
+		tileID := gid & GIDMask
+		for i := len(oldFirst) - 1; i >= 0; i-- {
+			if tileID >= oldFirst[i] {
+				return gid&^GIDMask | (tileID - oldFirst[i] + newFirst[i])
+			}
+		}
+		return gid
+	}
+
+	// Rewrite into copies first, so a layer failing to decode leaves the map as it was.
+	layers := make([]Data, len(tmx.Layers))
+	for i := range tmx.Layers {
+		data, err := tmx.Layers[i].Data.remapGIDs(remap)
+		if err != nil {
+			return fmt.Errorf("layer %q: %w", tmx.Layers[i].Name, err)
+		}
+		layers[i] = data
+	}
+
+	for i := range tmx.Layers {
+		tmx.Layers[i].Data = layers[i]
+	}
+	for i := range tmx.ObjectGroups {
+		objects := tmx.ObjectGroups[i].Objects
+		for j := range objects {
+			if objects[j].GID != 0 {
+				objects[j].GID = remap(objects[j].GID)
+			}
+		}
+	}
+	for i := range tmx.Tilesets {
+		tmx.Tilesets[i].FirstGID = newFirst[i]
+	}
+	return nil
+}
+
+// gidCount returns how many GIDs the tileset takes up: its tile count, or one past its
+// highest tile ID when image collections skip IDs. The tileset data must be attached.
+func (ts *Tileset) gidCount() uint32 {
+	count := uint32(max(ts.Tsx.TileCount, 0))
+	for i := range ts.Tsx.Tiles {
+		count = max(count, ts.Tsx.Tiles[i].ID+1)
+	}
+	return max(count, 1)
+}
+
+// remapGIDs returns a copy of the data with every GID passed through remap, encoded the same
+// way as the data.
+func (dt *Data) remapGIDs(remap func(gid uint32) uint32) (Data, error) {
+	rewrite := func(content string) (string, error) {
+		gids, err := DecodeContent(content, dt.Encoding, dt.Compression)
+		if err != nil {
+			return "", err
+		}
+		for i, gid := range gids {
+			if gid != 0 {
+				gids[i] = remap(gid)
+			}
+		}
+		return EncodeContent(gids, dt.Encoding, dt.Compression)
+	}
+
+	out := *dt
+	if len(dt.Chunks) == 0 {
+		content, err := rewrite(dt.Content)
+		if err != nil {
+			return Data{}, err
+		}
+		out.Content = content
+		return out, nil
+	}
+
+	out.Chunks = make([]Chunk, len(dt.Chunks))
+	for i := range dt.Chunks {
+		content, err := rewrite(dt.Chunks[i].Content)
+		if err != nil {
+			return Data{}, err
+		}
+		out.Chunks[i] = dt.Chunks[i]
+		out.Chunks[i].Content = content
+	}
+	return out, nil
+}
@@ -2,7 +2,4 @@ module github.com/adm87/tiled
 
 go 1.25.2
 
-require (
-	github.com/adm87/enum v0.0.1
-	github.com/klauspost/compress v1.18.0
-)
+require github.com/klauspost/compress v1.18.0
@@ -0,0 +1,199 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+// ======================================================
+// Group
+// ======================================================
+
+// Group is a group layer. Its offset, opacity, parallax and visibility apply to every layer
+// it contains, nested groups included. The layers themselves are kept in the Tmx slices of
+// their kind; Layers only locates them.
+type Group struct {
+	Flags LayerFlag
+
+	ID    int32
+	Name  string
+	Class string
+
+	LayerAttrs
+
+	Layers     []LayerRef // children in document order, bottom to top
+	Properties Properties
+}
+
+func (g *Group) IsLocked() bool {
+	return g.Flags&LayerFlagLocked != 0
+}
+
+func (g *Group) IsVisible() bool {
+	return g.Flags&LayerFlagVisible != 0
+}
+
+// unmarshalAttrs reads the attributes of a <group> element. Its children are decoded by the
+// Tmx, since the layers they hold are stored there.
+func (g *Group) unmarshalAttrs(attrs []xml.Attr) error {
+	g.Flags |= LayerFlagVisible
+	g.LayerAttrs = DefaultLayerAttrs()
+
+	for _, attr := range attrs {
+		var dst *float32
+		switch attr.Name.Local {
+		case "id":
+			val, err := strconv.ParseInt(attr.Value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid group id: %w", err)
+			}
+			g.ID = int32(val)
+		case "name":
+			g.Name = attr.Value
+		case "class":
+			g.Class = attr.Value
+		case "tintcolor":
+			g.TintColor = attr.Value
+		case "visible":
+			if attr.Value == "0" {
+				g.Flags &^= LayerFlagVisible
+			}
+		case "locked":
+			if attr.Value != "" {
+				g.Flags |= LayerFlagLocked
+			} else {
+				g.Flags &^= LayerFlagLocked
+			}
+		case "offsetx":
+			dst = &g.OffsetX
+		case "offsety":
+			dst = &g.OffsetY
+		case "opacity":
+			dst = &g.Opacity
+		case "parallaxx":
+			dst = &g.ParallaxX
+		case "parallaxy":
+			dst = &g.ParallaxY
+		}
+
+		if dst != nil {
+			val, err := strconv.ParseFloat(attr.Value, 32)
+			if err != nil {
+				return fmt.Errorf("invalid group %s: %w", attr.Name.Local, err)
+			}
+			*dst = float32(val)
+		}
+	}
+	return nil
+}
+
+// ======================================================
+// FlatLayer
+// ======================================================
+
+// FlatLayer is a leaf layer with the state of the groups containing it applied.
+type FlatLayer struct {
+	LayerRef
+
+	Parent int // index of the innermost group containing the layer, -1 at the top level
+
+	OffsetX   float32    // own offset plus the offsets of the groups
+	OffsetY   float32    // own offset plus the offsets of the groups
+	Opacity   float32    // own opacity times the opacity of the groups
+	ParallaxX float32    // own factor times the factors of the groups
+	ParallaxY float32    // own factor times the factors of the groups
+	Tint      color.RGBA // own tint multiplied with the tints of the groups, white for none
+	Visible   bool       // false if the layer or any of its groups is hidden
+}
+
+// FlattenLayers returns the tile layers, object groups and image layers of the map in draw
+// order, bottom to top, with the offset, opacity, parallax, tint and visibility of their
+// groups applied. Maps without a Tree are flattened from OrderedLayers, without groups.
+func (t *Tmx) FlattenLayers() []FlatLayer {
+	refs := t.Tree
+	if len(refs) == 0 {
+		refs = t.OrderedLayers()
+	}
+
+	root := FlatLayer{
+		Parent:    -1,
+		Opacity:   1,
+		ParallaxX: 1,
+		ParallaxY: 1,
+		Tint:      color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		Visible:   true,
+	}
+	layers := make([]FlatLayer, 0, len(t.LayerOrder))
+	for _, ref := range refs {
+		layers = t.flatten(layers, ref, root, 0)
+	}
+	return layers
+}
+
+// maxGroupDepth bounds the recursion on malformed trees, e.g. a group listing itself.
+const maxGroupDepth = 64
+
+func (t *Tmx) flatten(dst []FlatLayer, ref LayerRef, parent FlatLayer, depth int) []FlatLayer {
+	attrs, visible, ok := t.layerAttrs(ref)
+	if !ok || depth >= maxGroupDepth {
+		return dst
+	}
+
+	flat := parent
+	flat.LayerRef = ref
+	flat.OffsetX += attrs.OffsetX
+	flat.OffsetY += attrs.OffsetY
+	flat.Opacity *= attrs.Opacity
+	flat.ParallaxX *= attrs.ParallaxX
+	flat.ParallaxY *= attrs.ParallaxY
+	flat.Tint = multiplyColor(flat.Tint, attrs.Tint())
+	flat.Visible = flat.Visible && visible
+
+	if ref.Kind != LayerKindGroup {
+		return append(dst, flat)
+	}
+
+	flat.Parent = ref.Index
+	for _, child := range t.Groups[ref.Index].Layers {
+		dst = t.flatten(dst, child, flat, depth+1)
+	}
+	return dst
+}
+
+// layerAttrs returns the display attributes and visibility of the layer a ref locates.
+func (t *Tmx) layerAttrs(ref LayerRef) (attrs *LayerAttrs, visible, ok bool) {
+	switch ref.Kind {
+	case LayerKindTile:
+		if ref.Index >= 0 && ref.Index < len(t.Layers) {
+			l := &t.Layers[ref.Index]
+			return &l.LayerAttrs, l.IsVisible(), true
+		}
+	case LayerKindObject:
+		if ref.Index >= 0 && ref.Index < len(t.ObjectGroups) {
+			og := &t.ObjectGroups[ref.Index]
+			return &og.LayerAttrs, og.IsVisible(), true
+		}
+	case LayerKindImage:
+		if ref.Index >= 0 && ref.Index < len(t.ImageLayers) {
+			il := &t.ImageLayers[ref.Index]
+			return &il.LayerAttrs, il.IsVisible(), true
+		}
+	case LayerKindGroup:
+		if ref.Index >= 0 && ref.Index < len(t.Groups) {
+			g := &t.Groups[ref.Index]
+			return &g.LayerAttrs, g.IsVisible(), true
+		}
+	}
+	return nil, false, false
+}
+
+func multiplyColor(a, b color.RGBA) color.RGBA {
+	return color.RGBA{
+		R: uint8(uint16(a.R) * uint16(b.R) / 0xff),
+		G: uint8(uint16(a.G) * uint16(b.G) / 0xff),
+		B: uint8(uint16(a.B) * uint16(b.B) / 0xff),
+		A: uint8(uint16(a.A) * uint16(b.A) / 0xff),
+	}
+}
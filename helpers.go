@@ -1,5 +1,11 @@
 package tiled
 
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
 func LayerByName(tmx *Tmx, name string) *Layer {
 	for i := range tmx.Layers {
 		if tmx.Layers[i].Name == name {
@@ -18,6 +24,26 @@ func ObjectGroupByName(tmx *Tmx, name string) *ObjectGroup {
 	return nil
 }
 
+// Objects returns the objects of every object group that pass the query flags.
+func Objects(tmx *Tmx, flags QueryFlag) []*Object {
+	var objects []*Object
+	for i := range tmx.ObjectGroups {
+		objects = append(objects, tmx.ObjectGroups[i].QueryObjects(flags)...)
+	}
+	return objects
+}
+
+// ObjectsByClass returns the objects of a class that pass the query flags.
+func ObjectsByClass(tmx *Tmx, class string, flags QueryFlag) []*Object {
+	var objects []*Object
+	for _, obj := range Objects(tmx, flags) {
+		if obj.Class == class {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
 func TilesetByGID(tmx *Tmx, gid uint32) (*Tileset, uint32, int) {
 	for i := len(tmx.Tilesets) - 1; i >= 0; i-- {
 		if gid >= tmx.Tilesets[i].FirstGID {
@@ -45,6 +71,25 @@ func PropertyByType(props []Property, propertyType string) *Property {
 	return nil
 }
 
+// ParseColor parses a Tiled color, #RRGGBB or #AARRGGBB with the # optional.
+func ParseColor(s string) (color.RGBA, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 && len(s) != 8 {
+		return color.RGBA{}, false
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+
+	c := color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+	if len(s) == 8 {
+		c.A = uint8(v >> 24)
+	}
+	return c, true
+}
+
 func ObjectAlignmentAnchor(alignment ObjectAlignment) (ax, ay float32) {
 	switch alignment {
 	case ObjectAlignmentTop:
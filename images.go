@@ -0,0 +1,91 @@
+package tiled
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+var ErrImageSize = errors.New("image size doesn't match the image file")
+
+// ======================================================
+// Image probing
+// ======================================================
+
+// ProbeImageSize returns the dimensions of a PNG, JPEG or GIF image read from r. Only the
+// image header is decoded, not its pixels.
+func ProbeImageSize(r io.Reader) (width, height int32, err error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int32(cfg.Width), int32(cfg.Height), nil
+}
+
+// SetProbeImages controls whether the loader reads the header of every image its tilesets
+// and image layers reference, to fill in missing image dimensions and to catch images whose
+// width and height attributes don't match the file, e.g. an atlas exported again at another
+// size. Mismatches fail the load with ErrImageSize. It is off by default, so maps load
+// without their images, and must be set before the loader is used.
+func (l *Loader) SetProbeImages(enabled bool) {
+	l.probe = enabled
+}
+
+// probeTsx probes the images of a tileset stored in, or embedded in, the file at base.
+func (l *Loader) probeTsx(base string, tsx *Tsx) error {
+	if err := l.probeImage(base, &tsx.Image); err != nil {
+		return err
+	}
+	for i := range tsx.Tiles {
+		if img := tsx.Tiles[i].Image; img != nil {
+			if err := l.probeImage(base, img); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// probeImage fills in the dimensions of an image referenced from the file at base, or checks
+// them against the image file when they are set.
+func (l *Loader) probeImage(base string, img *Image) error {
+	if !l.probe || img.Source == "" {
+		return nil
+	}
+
+	name := ResolvePath(base, img.Source)
+	w, h, err := l.probeFile(name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if (img.Width != 0 && img.Width != w) || (img.Height != 0 && img.Height != h) {
+		return fmt.Errorf("%w: %s is %dx%d, %s says %dx%d", ErrImageSize, name, w, h, base, img.Width, img.Height)
+	}
+	img.Width, img.Height = w, h
+	return nil
+}
+
+// probeFile reads the dimensions of an image file, only opening it when the loader reads a
+// file system.
+func (l *Loader) probeFile(name string) (width, height int32, err error) {
+	if l.fsys == nil {
+		data, err := l.read(name)
+		if err != nil {
+			return 0, 0, err
+		}
+		return ProbeImageSize(bytes.NewReader(data))
+	}
+
+	f, err := l.fsys.Open(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	return ProbeImageSize(f)
+}
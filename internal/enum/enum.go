@@ -0,0 +1,27 @@
+// Package enum parses the text form of the package's enums. It stands in for
+// github.com/adm87/enum, so users of the module don't depend on it.
+package enum
+
+import "fmt"
+
+// Enum is an integer enum whose valid values run from 0 up, each with a distinct name.
+type Enum interface {
+	~int8 | ~int32 | ~int64 | ~uint8 | ~uint32 | ~uint64
+	String() string
+	IsValid() bool
+}
+
+// UnmarshalEnum returns the value of T whose String matches a name.
+func UnmarshalEnum[T Enum](name string) (T, error) {
+	for i := 0; ; i++ {
+		v := T(i)
+		if !v.IsValid() {
+			break
+		}
+		if v.String() == name {
+			return v, nil
+		}
+	}
+	var zero T
+	return zero, fmt.Errorf("invalid enum name: %s", name)
+}
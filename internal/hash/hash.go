@@ -0,0 +1,13 @@
+// Package hash packs grid coordinates into map keys. It stands in for
+// github.com/adm87/utilities/hash, so users of the module don't depend on it.
+package hash
+
+// EncodeGridKey packs a cell into a single key, x in the high 32 bits and y in the low.
+func EncodeGridKey(x, y int32) uint64 {
+	return uint64(uint32(x))<<32 | uint64(uint32(y))
+}
+
+// DecodeGridKey returns the cell a key was encoded from.
+func DecodeGridKey(key uint64) (x, y int32) {
+	return int32(uint32(key >> 32)), int32(uint32(key))
+}
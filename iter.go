@@ -0,0 +1,50 @@
+package tiled
+
+import "iter"
+
+// ======================================================
+// Iterators
+// ======================================================
+
+// AllLayers returns an iterator over the layers of every kind in draw order, bottom to top,
+// the same as AnyLayers, without building a slice.
+//
+//	for layer := range tmx.AllLayers() {
+func (t *Tmx) AllLayers() iter.Seq[AnyLayer] {
+	return func(yield func(AnyLayer) bool) {
+		for _, ref := range t.OrderedLayers() {
+			if layer, ok := t.anyLayer(ref); ok && !yield(layer) {
+				return
+			}
+		}
+	}
+}
+
+// AllObjects returns an iterator over the objects of every object group, group by group in
+// document order, along with the group holding them.
+//
+//	for group, obj := range tmx.AllObjects() {
+func (t *Tmx) AllObjects() iter.Seq2[*ObjectGroup, *Object] {
+	return func(yield func(*ObjectGroup, *Object) bool) {
+		for i := range t.ObjectGroups {
+			group := &t.ObjectGroups[i]
+			for j := range group.Objects {
+				if !yield(group, &group.Objects[j]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// AllTilesets returns an iterator over the tilesets of the map with their index, skipping
+// the ones without attached data.
+func (t *Tmx) AllTilesets() iter.Seq2[int, *Tsx] {
+	return func(yield func(int, *Tsx) bool) {
+		for i := range t.Tilesets {
+			if tsx := t.Tilesets[i].Tsx; tsx != nil && !yield(i, tsx) {
+				return
+			}
+		}
+	}
+}
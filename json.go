@@ -0,0 +1,849 @@
+package tiled
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adm87/tiled/internal/enum"
+)
+
+// ======================================================
+// JSON - TMJ, TSJ and TJ
+// ======================================================
+
+// UnmarshalTMJ populates a Tmx from a map exported as JSON (.tmj).
+//
+// Tile layers and object groups nested in group layers are flattened into Layers and
+// ObjectGroups in document order. Embedded tilesets are attached to their Tileset entry.
+// Layer data is kept in its encoded form, so DecodeContent works the same as for XML maps.
+func UnmarshalTMJ(data []byte, tmx *Tmx) error {
+	var jm jsonMap
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return err
+	}
+	return jm.convert(tmx)
+}
+
+// UnmarshalTSJ populates a Tsx from a tileset exported as JSON (.tsj).
+func UnmarshalTSJ(data []byte, tsx *Tsx) error {
+	var jt jsonTileset
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return err
+	}
+	return jt.convert(tsx)
+}
+
+// UnmarshalTJ populates a Tx from a template exported as JSON (.tj).
+func UnmarshalTJ(data []byte, tx *Tx) error {
+	var jt struct {
+		Tileset *jsonTileset `json:"tileset"`
+		Object  jsonObject   `json:"object"`
+	}
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return err
+	}
+
+	if jt.Tileset != nil {
+		tx.Tileset = Tileset{
+			FirstGID: jt.Tileset.FirstGID,
+			Source:   jt.Tileset.Source,
+		}
+	}
+	return jt.Object.convert(&tx.Objects)
+}
+
+// ======================================================
+// jsonMap
+// ======================================================
+
+type jsonMap struct {
+	Width         int32          `json:"width"`
+	Height        int32          `json:"height"`
+	TileWidth     int32          `json:"tilewidth"`
+	TileHeight    int32          `json:"tileheight"`
+	Infinite      bool           `json:"infinite"`
+	Orientation   string         `json:"orientation"`
+	RenderOrder   string         `json:"renderorder"`
+	HexSideLength int32          `json:"hexsidelength"`
+	StaggerAxis   string         `json:"staggeraxis"`
+	StaggerIndex  string         `json:"staggerindex"`
+	NextLayerID   int32          `json:"nextlayerid"`
+	NextObjectID  int32          `json:"nextobjectid"`
+	Tilesets      []jsonTileset  `json:"tilesets"`
+	Layers        []jsonLayer    `json:"layers"`
+	Properties    []jsonProperty `json:"properties"`
+}
+
+func (jm *jsonMap) convert(tmx *Tmx) error {
+	tmx.Width = jm.Width
+	tmx.Height = jm.Height
+	tmx.TileWidth = jm.TileWidth
+	tmx.TileHeight = jm.TileHeight
+	tmx.NextLayerID = jm.NextLayerID
+	tmx.NextObjectID = jm.NextObjectID
+
+	if jm.Infinite {
+		tmx.Flags |= MapFlagInfinite
+	}
+
+	if jm.Orientation != "" {
+		val, err := enum.UnmarshalEnum[Orientation](jm.Orientation)
+		if err != nil {
+			return err
+		}
+		tmx.Orientation = val
+	}
+
+	if jm.RenderOrder != "" {
+		val, err := enum.UnmarshalEnum[RenderOrder](jm.RenderOrder)
+		if err != nil {
+			return err
+		}
+		tmx.RenderOrder = val
+	}
+
+	tmx.HexSideLength = jm.HexSideLength
+	if jm.StaggerAxis != "" {
+		val, err := enum.UnmarshalEnum[StaggerAxis](jm.StaggerAxis)
+		if err != nil {
+			return err
+		}
+		tmx.StaggerAxis = val
+	}
+
+	if jm.StaggerIndex != "" {
+		val, err := enum.UnmarshalEnum[StaggerIndex](jm.StaggerIndex)
+		if err != nil {
+			return err
+		}
+		tmx.StaggerIndex = val
+	}
+
+	for i := range jm.Tilesets {
+		jt := &jm.Tilesets[i]
+
+		ts := Tileset{
+			FirstGID: jt.FirstGID,
+			Source:   jt.Source,
+		}
+		if jt.Source == "" {
+			ts.Tsx = &Tsx{}
+			if err := jt.convert(ts.Tsx); err != nil {
+				return err
+			}
+		}
+		tmx.Tilesets = append(tmx.Tilesets, ts)
+	}
+
+	if err := convertLayers(jm.Layers, tmx, -1); err != nil {
+		return err
+	}
+
+	props, err := convertProperties(jm.Properties)
+	if err != nil {
+		return err
+	}
+	tmx.Properties = props
+	return nil
+}
+
+// ======================================================
+// jsonTileset
+// ======================================================
+
+type jsonTileset struct {
+	FirstGID        uint32         `json:"firstgid"`
+	Source          string         `json:"source"`
+	TileWidth       int32          `json:"tilewidth"`
+	TileHeight      int32          `json:"tileheight"`
+	TileCount       int32          `json:"tilecount"`
+	Columns         int32          `json:"columns"`
+	Spacing         int32          `json:"spacing"`
+	Margin          int32          `json:"margin"`
+	Image           string         `json:"image"`
+	ImageWidth      int32          `json:"imagewidth"`
+	ImageHeight     int32          `json:"imageheight"`
+	TileOffset      Offset         `json:"tileoffset"`
+	ObjectAlignment string         `json:"objectalignment"`
+	Tiles           []jsonTile     `json:"tiles"`
+	WangSets        []jsonWangSet  `json:"wangsets"`
+	Properties      []jsonProperty `json:"properties"`
+}
+
+type jsonTile struct {
+	ID          uint32         `json:"id"`
+	Type        string         `json:"type"`
+	Class       string         `json:"class"`
+	Probability *float32       `json:"probability"`
+	Image       string         `json:"image"`
+	ImageWidth  int32          `json:"imagewidth"`
+	ImageHeight int32          `json:"imageheight"`
+	ObjectGroup *jsonLayer     `json:"objectgroup"`
+	Animation   []Frame        `json:"animation"`
+	Properties  []jsonProperty `json:"properties"`
+}
+
+func (jt *jsonTileset) convert(tsx *Tsx) error {
+	tsx.TileWidth = jt.TileWidth
+	tsx.TileHeight = jt.TileHeight
+	tsx.TileCount = jt.TileCount
+	tsx.Columns = jt.Columns
+	tsx.Spacing = jt.Spacing
+	tsx.Margin = jt.Margin
+	tsx.TileOffset = jt.TileOffset
+	tsx.Image = Image{
+		Width:  jt.ImageWidth,
+		Height: jt.ImageHeight,
+		Source: jt.Image,
+	}
+
+	if jt.ObjectAlignment != "" {
+		val, err := enum.UnmarshalEnum[ObjectAlignment](jt.ObjectAlignment)
+		if err != nil {
+			return err
+		}
+		tsx.ObjectAlignment = val
+	}
+
+	for i := range jt.Tiles {
+		tile, err := jt.Tiles[i].convert()
+		if err != nil {
+			return err
+		}
+		tsx.Tiles = append(tsx.Tiles, tile)
+	}
+
+	for i := range jt.WangSets {
+		ws, err := jt.WangSets[i].convert()
+		if err != nil {
+			return err
+		}
+		tsx.WangSets = append(tsx.WangSets, ws)
+	}
+
+	props, err := convertProperties(jt.Properties)
+	if err != nil {
+		return err
+	}
+	tsx.Properties = props
+
+	tsx.InferLayout()
+	return nil
+}
+
+func (jt *jsonTile) convert() (TsxTile, error) {
+	tile := TsxTile{
+		ID:          jt.ID,
+		Class:       jt.Class,
+		Probability: 1,
+		Animation:   jt.Animation,
+	}
+	if tile.Class == "" {
+		tile.Class = jt.Type
+	}
+	if jt.Probability != nil {
+		tile.Probability = *jt.Probability
+	}
+	if jt.Image != "" {
+		tile.Image = &Image{
+			Width:  jt.ImageWidth,
+			Height: jt.ImageHeight,
+			Source: jt.Image,
+		}
+	}
+	if jt.ObjectGroup != nil {
+		tile.ObjectGroup = &ObjectGroup{}
+		if err := jt.ObjectGroup.convertObjectGroup(tile.ObjectGroup); err != nil {
+			return tile, err
+		}
+	}
+
+	props, err := convertProperties(jt.Properties)
+	if err != nil {
+		return tile, err
+	}
+	tile.Properties = props
+	return tile, nil
+}
+
+type jsonWangSet struct {
+	Name       string          `json:"name"`
+	Class      string          `json:"class"`
+	Type       string          `json:"type"`
+	Tile       int32           `json:"tile"`
+	Colors     []jsonWangColor `json:"colors"`
+	WangTiles  []jsonWangTile  `json:"wangtiles"`
+	Properties []jsonProperty  `json:"properties"`
+}
+
+type jsonWangColor struct {
+	Name        string         `json:"name"`
+	Class       string         `json:"class"`
+	Color       string         `json:"color"`
+	Tile        int32          `json:"tile"`
+	Probability float32        `json:"probability"`
+	Properties  []jsonProperty `json:"properties"`
+}
+
+type jsonWangTile struct {
+	TileID uint32 `json:"tileid"`
+	WangID WangID `json:"wangid"`
+}
+
+func (jw *jsonWangSet) convert() (WangSet, error) {
+	ws := WangSet{
+		Name:  jw.Name,
+		Class: jw.Class,
+		Tile:  jw.Tile,
+	}
+
+	if jw.Type != "" {
+		val, err := enum.UnmarshalEnum[WangType](jw.Type)
+		if err != nil {
+			return ws, err
+		}
+		ws.Type = val
+	}
+
+	for _, jc := range jw.Colors {
+		props, err := convertProperties(jc.Properties)
+		if err != nil {
+			return ws, err
+		}
+		ws.Colors = append(ws.Colors, WangColor{
+			Name:        jc.Name,
+			Class:       jc.Class,
+			Color:       jc.Color,
+			Tile:        jc.Tile,
+			Probability: jc.Probability,
+			Properties:  props,
+		})
+	}
+
+	for _, jt := range jw.WangTiles {
+		ws.Tiles = append(ws.Tiles, WangTile{TileID: jt.TileID, WangID: jt.WangID})
+	}
+
+	props, err := convertProperties(jw.Properties)
+	if err != nil {
+		return ws, err
+	}
+	ws.Properties = props
+	return ws, nil
+}
+
+// ======================================================
+// jsonLayer
+// ======================================================
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	ID          int32           `json:"id"`
+	Name        string          `json:"name"`
+	Class       string          `json:"class"`
+	Width       int32           `json:"width"`
+	Height      int32           `json:"height"`
+	Visible     *bool           `json:"visible"`
+	Locked      bool            `json:"locked"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Chunks      []jsonChunk     `json:"chunks"`
+	DrawOrder   string          `json:"draworder"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+	Properties  []jsonProperty  `json:"properties"`
+
+	TintColor   string   `json:"tintcolor"`
+	OffsetX     float32  `json:"offsetx"`
+	OffsetY     float32  `json:"offsety"`
+	Opacity     *float32 `json:"opacity"`
+	ParallaxX   *float32 `json:"parallaxx"`
+	ParallaxY   *float32 `json:"parallaxy"`
+	RepeatX     bool     `json:"repeatx"`
+	RepeatY     bool     `json:"repeaty"`
+	Image       string   `json:"image"`
+	ImageWidth  int32    `json:"imagewidth"`
+	ImageHeight int32    `json:"imageheight"`
+}
+
+type jsonChunk struct {
+	X      int32           `json:"x"`
+	Y      int32           `json:"y"`
+	Width  int32           `json:"width"`
+	Height int32           `json:"height"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// convertLayers converts the layers of the map, or of the group at index parent.
+func convertLayers(layers []jsonLayer, tmx *Tmx, parent int) error {
+	for i := range layers {
+		jl := &layers[i]
+
+		switch jl.Type {
+		case "tilelayer":
+			var layer Layer
+			if err := jl.convertTileLayer(&layer); err != nil {
+				return err
+			}
+			tmx.addLayer(LayerRef{Kind: LayerKindTile, Index: len(tmx.Layers)}, parent)
+			tmx.Layers = append(tmx.Layers, layer)
+
+		case "objectgroup":
+			var og ObjectGroup
+			if err := jl.convertObjectGroup(&og); err != nil {
+				return err
+			}
+			tmx.addLayer(LayerRef{Kind: LayerKindObject, Index: len(tmx.ObjectGroups)}, parent)
+			tmx.ObjectGroups = append(tmx.ObjectGroups, og)
+
+		case "imagelayer":
+			var il ImageLayer
+			if err := jl.convertImageLayer(&il); err != nil {
+				return err
+			}
+			tmx.addLayer(LayerRef{Kind: LayerKindImage, Index: len(tmx.ImageLayers)}, parent)
+			tmx.ImageLayers = append(tmx.ImageLayers, il)
+
+		case "group":
+			var g Group
+			if err := jl.convertGroup(&g); err != nil {
+				return err
+			}
+			index := len(tmx.Groups)
+			tmx.addLayer(LayerRef{Kind: LayerKindGroup, Index: index}, parent)
+			tmx.Groups = append(tmx.Groups, g)
+			if err := convertLayers(jl.Layers, tmx, index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (jl *jsonLayer) flags() LayerFlag {
+	var flags LayerFlag
+	if jl.Visible == nil || *jl.Visible {
+		flags |= LayerFlagVisible
+	}
+	if jl.Locked {
+		flags |= LayerFlagLocked
+	}
+	return flags
+}
+
+func (jl *jsonLayer) attrs() LayerAttrs {
+	attrs := DefaultLayerAttrs()
+	attrs.OffsetX = jl.OffsetX
+	attrs.OffsetY = jl.OffsetY
+	attrs.TintColor = jl.TintColor
+
+	if jl.Opacity != nil {
+		attrs.Opacity = *jl.Opacity
+	}
+	if jl.ParallaxX != nil {
+		attrs.ParallaxX = *jl.ParallaxX
+	}
+	if jl.ParallaxY != nil {
+		attrs.ParallaxY = *jl.ParallaxY
+	}
+	return attrs
+}
+
+func (jl *jsonLayer) convertTileLayer(layer *Layer) error {
+	layer.ID = jl.ID
+	layer.Name = jl.Name
+	layer.Class = jl.Class
+	layer.Width = jl.Width
+	layer.Height = jl.Height
+	layer.Flags = jl.flags()
+	layer.LayerAttrs = jl.attrs()
+
+	if jl.Encoding != "" {
+		val, err := enum.UnmarshalEnum[Encoding](jl.Encoding)
+		if err != nil {
+			return err
+		}
+		layer.Data.Encoding = val
+	}
+
+	if jl.Compression != "" {
+		val, err := enum.UnmarshalEnum[Compression](jl.Compression)
+		if err != nil {
+			return err
+		}
+		layer.Data.Compression = val
+	}
+
+	content, err := jsonLayerData(jl.Data)
+	if err != nil {
+		return fmt.Errorf("layer %d: %w", jl.ID, err)
+	}
+	layer.Data.Content = content
+
+	for _, jc := range jl.Chunks {
+		content, err := jsonLayerData(jc.Data)
+		if err != nil {
+			return fmt.Errorf("layer %d chunk %d,%d: %w", jl.ID, jc.X, jc.Y, err)
+		}
+		layer.Data.Chunks = append(layer.Data.Chunks, Chunk{
+			X:       jc.X,
+			Y:       jc.Y,
+			Width:   jc.Width,
+			Height:  jc.Height,
+			Content: content,
+		})
+	}
+
+	props, err := convertProperties(jl.Properties)
+	if err != nil {
+		return err
+	}
+	layer.Properties = props
+	return nil
+}
+
+func (jl *jsonLayer) convertImageLayer(il *ImageLayer) error {
+	il.ID = jl.ID
+	il.Name = jl.Name
+	il.Class = jl.Class
+	il.Flags = jl.flags()
+	il.LayerAttrs = jl.attrs()
+	il.RepeatX = jl.RepeatX
+	il.RepeatY = jl.RepeatY
+	il.Image = Image{
+		Width:  jl.ImageWidth,
+		Height: jl.ImageHeight,
+		Source: jl.Image,
+	}
+
+	props, err := convertProperties(jl.Properties)
+	if err != nil {
+		return err
+	}
+	il.Properties = props
+	return nil
+}
+
+func (jl *jsonLayer) convertGroup(g *Group) error {
+	g.ID = jl.ID
+	g.Name = jl.Name
+	g.Class = jl.Class
+	g.Flags = jl.flags()
+	g.LayerAttrs = jl.attrs()
+
+	props, err := convertProperties(jl.Properties)
+	if err != nil {
+		return err
+	}
+	g.Properties = props
+	return nil
+}
+
+func (jl *jsonLayer) convertObjectGroup(og *ObjectGroup) error {
+	og.ID = jl.ID
+	og.Name = jl.Name
+	og.Class = jl.Class
+	og.Flags = jl.flags()
+	og.LayerAttrs = jl.attrs()
+	og.DrawOrder = DrawOrderTopDown
+
+	if jl.DrawOrder != "" {
+		val, err := enum.UnmarshalEnum[DrawOrder](jl.DrawOrder)
+		if err != nil {
+			return err
+		}
+		og.DrawOrder = val
+	}
+
+	og.Objects = make([]Object, len(jl.Objects))
+	for i := range jl.Objects {
+		if err := jl.Objects[i].convert(&og.Objects[i]); err != nil {
+			return err
+		}
+	}
+
+	props, err := convertProperties(jl.Properties)
+	if err != nil {
+		return err
+	}
+	og.Properties = props
+	return nil
+}
+
+// jsonLayerData returns layer data in the form DecodeContent expects: base64 strings are
+// kept as is, and arrays of GIDs become CSV.
+func jsonLayerData(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+
+	if raw[0] == '"' {
+		var content string
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return "", err
+		}
+		return content, nil
+	}
+
+	var gids []uint32
+	if err := json.Unmarshal(raw, &gids); err != nil {
+		return "", fmt.Errorf("invalid layer data: %w", err)
+	}
+
+	var sb strings.Builder
+	for i, gid := range gids {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(gid), 10))
+	}
+	return sb.String(), nil
+}
+
+// ======================================================
+// jsonObject
+// ======================================================
+
+type jsonObject struct {
+	ID         int32          `json:"id"`
+	Name       string         `json:"name"`
+	Class      string         `json:"class"`
+	Type       string         `json:"type"`
+	X          float32        `json:"x"`
+	Y          float32        `json:"y"`
+	Width      float32        `json:"width"`
+	Height     float32        `json:"height"`
+	Rotation   float32        `json:"rotation"`
+	GID        uint32         `json:"gid"`
+	Visible    *bool          `json:"visible"`
+	Template   string         `json:"template"`
+	Ellipse    bool           `json:"ellipse"`
+	Point      bool           `json:"point"`
+	Polygon    []jsonPoint    `json:"polygon"`
+	Polyline   []jsonPoint    `json:"polyline"`
+	Text       *jsonText      `json:"text"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type jsonText struct {
+	Text       string `json:"text"`
+	FontFamily string `json:"fontfamily"`
+	PixelSize  int32  `json:"pixelsize"`
+	Color      string `json:"color"`
+	Wrap       bool   `json:"wrap"`
+	Bold       bool   `json:"bold"`
+	Italic     bool   `json:"italic"`
+	Underline  bool   `json:"underline"`
+	HAlign     string `json:"halign"`
+	VAlign     string `json:"valign"`
+}
+
+func (jo *jsonObject) convert(o *Object) error {
+	o.ID = jo.ID
+	o.Name = jo.Name
+	o.Class = jo.Class
+	if o.Class == "" {
+		// Maps saved before Tiled 1.9 store the class as "type".
+		o.Class = jo.Type
+	}
+	o.X, o.Y = jo.X, jo.Y
+	o.Width, o.Height = jo.Width, jo.Height
+	o.Rotation = jo.Rotation
+	o.GID = jo.GID
+	o.Template = jo.Template
+
+	if jo.Visible == nil || *jo.Visible {
+		o.Flags |= ObjectFlagVisible
+	}
+	if jo.Visible != nil {
+		o.overrides |= objectAttrVisible
+	}
+	if jo.Template != "" {
+		o.Flags |= ObjectFlagTemplate
+	}
+	if jo.Ellipse {
+		o.Flags |= ObjectFlagEllipse
+	}
+	if jo.Point {
+		o.Flags |= ObjectFlagPoint
+	}
+
+	o.Polygon = jsonPolygon(jo.Polygon)
+	o.Polyline = jsonPolygon(jo.Polyline)
+
+	if jo.Text != nil {
+		text, err := jo.Text.convert()
+		if err != nil {
+			return err
+		}
+		o.Text = text
+	}
+
+	props, err := convertProperties(jo.Properties)
+	if err != nil {
+		return err
+	}
+	o.Properties = props
+	return nil
+}
+
+func jsonPolygon(points []jsonPoint) Polygon {
+	var p Polygon
+	if len(points) == 0 {
+		return p
+	}
+
+	p.Points = make([]float32, 0, len(points)*2)
+	for _, pt := range points {
+		p.Points = append(p.Points, pt.X, pt.Y)
+	}
+	return p
+}
+
+func (jt *jsonText) convert() (*Text, error) {
+	t := &Text{
+		FontFamily: jt.FontFamily,
+		PixelSize:  jt.PixelSize,
+		Color:      jt.Color,
+		Wrap:       jt.Wrap,
+		Bold:       jt.Bold,
+		Italic:     jt.Italic,
+		Underline:  jt.Underline,
+		Content:    jt.Text,
+	}
+	if t.PixelSize == 0 {
+		t.PixelSize = 16
+	}
+
+	if jt.HAlign != "" {
+		val, err := enum.UnmarshalEnum[HAlign](jt.HAlign)
+		if err != nil {
+			return nil, err
+		}
+		t.HAlign = val
+	}
+
+	if jt.VAlign != "" {
+		val, err := enum.UnmarshalEnum[VAlign](jt.VAlign)
+		if err != nil {
+			return nil, err
+		}
+		t.VAlign = val
+	}
+	return t, nil
+}
+
+// ======================================================
+// jsonProperty
+// ======================================================
+
+type jsonProperty struct {
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+	PropertyType string          `json:"propertytype"`
+	Value        json.RawMessage `json:"value"`
+}
+
+func convertProperties(props []jsonProperty) ([]Property, error) {
+	if len(props) == 0 {
+		return nil, nil
+	}
+
+	out := make([]Property, 0, len(props))
+	for i := range props {
+		prop := Property{
+			Name:         props[i].Name,
+			PropertyType: props[i].PropertyType,
+		}
+		if props[i].Type != "" {
+			kind, err := enum.UnmarshalEnum[PropertyKind](props[i].Type)
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", props[i].Name, err)
+			}
+			prop.Type = kind
+		}
+
+		if prop.Type == PropertyKindClass {
+			members, err := jsonClassMembers(props[i].Value)
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", props[i].Name, err)
+			}
+			prop.Properties = members
+		} else {
+			value, err := jsonPropertyValue(props[i].Value)
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", props[i].Name, err)
+			}
+			prop.Value = value
+		}
+
+		out = append(out, prop)
+	}
+	return out, nil
+}
+
+// jsonClassMembers converts the members of a class property value, ordered by name.
+func jsonClassMembers(raw json.RawMessage) ([]Property, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	out := make([]Property, 0, len(members))
+	for _, name := range names {
+		raw := bytes.TrimSpace(members[name])
+
+		prop := Property{Name: name}
+		if len(raw) > 0 && raw[0] == '{' {
+			nested, err := jsonClassMembers(raw)
+			if err != nil {
+				return nil, err
+			}
+			prop.Properties = nested
+		} else {
+			value, err := jsonPropertyValue(raw)
+			if err != nil {
+				return nil, err
+			}
+			prop.Value = value
+		}
+		out = append(out, prop)
+	}
+	return out, nil
+}
+
+// jsonPropertyValue returns a property value in the string form used by TMX files.
+func jsonPropertyValue(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+
+	// Numbers and booleans are written the same way in both formats.
+	return string(raw), nil
+}
@@ -0,0 +1,102 @@
+package tiled
+
+import (
+	"fmt"
+	"time"
+)
+
+// Property names of the object lifecycle conventions.
+const (
+	PropertyEnabled = "enabled" // bool, defaults to true
+	PropertyOnce    = "once"    // bool, defaults to false
+	PropertyDelay   = "delay"   // float seconds, defaults to 0
+)
+
+// ======================================================
+// ObjectLifecycle
+// ======================================================
+
+// ObjectLifecycle holds the conventional behavior toggles designers set on objects in Tiled,
+// so triggers and spawners can be switched off, limited to a single activation or delayed
+// without code changes.
+type ObjectLifecycle struct {
+	Enabled bool          // the object takes part in gameplay
+	Once    bool          // the object activates only the first time
+	Delay   time.Duration // time between activation and effect
+}
+
+// Lifecycle resolves the lifecycle properties of an object, applying defaults for missing ones.
+// It returns an error if a property is present but cannot be parsed.
+func (o *Object) Lifecycle() (ObjectLifecycle, error) {
+	lc := ObjectLifecycle{
+		Enabled: true,
+	}
+
+	if prop := PropertyByName(o.Properties, PropertyEnabled); prop != nil {
+		val, err := prop.Bool()
+		if err != nil {
+			return lc, fmt.Errorf("invalid %s property on object %d: %w", PropertyEnabled, o.ID, err)
+		}
+		lc.Enabled = val
+	}
+
+	if prop := PropertyByName(o.Properties, PropertyOnce); prop != nil {
+		val, err := prop.Bool()
+		if err != nil {
+			return lc, fmt.Errorf("invalid %s property on object %d: %w", PropertyOnce, o.ID, err)
+		}
+		lc.Once = val
+	}
+
+	if prop := PropertyByName(o.Properties, PropertyDelay); prop != nil {
+		val, err := prop.Float()
+		if err != nil {
+			return lc, fmt.Errorf("invalid %s property on object %d: %w", PropertyDelay, o.ID, err)
+		}
+		lc.Delay = time.Duration(val * float64(time.Second))
+	}
+
+	return lc, nil
+}
+
+// ======================================================
+// LifecycleTracker
+// ======================================================
+
+// LifecycleTracker applies lifecycle conventions at runtime by remembering which objects
+// already activated. Trigger and spawn systems call Activate before acting on an object.
+type LifecycleTracker struct {
+	fired map[int32]struct{}
+}
+
+func NewLifecycleTracker() *LifecycleTracker {
+	return &LifecycleTracker{
+		fired: make(map[int32]struct{}),
+	}
+}
+
+// Activate reports whether the object may activate now, and if so, after what delay.
+// Disabled objects never activate, and objects marked once activate a single time.
+func (t *LifecycleTracker) Activate(o *Object) (bool, time.Duration, error) {
+	lc, err := o.Lifecycle()
+	if err != nil {
+		return false, 0, err
+	}
+
+	if !lc.Enabled {
+		return false, 0, nil
+	}
+
+	if lc.Once {
+		if _, ok := t.fired[o.ID]; ok {
+			return false, 0, nil
+		}
+		t.fired[o.ID] = struct{}{}
+	}
+	return true, lc.Delay, nil
+}
+
+// Reset forgets every activation, e.g. when a level restarts.
+func (t *LifecycleTracker) Reset() {
+	clear(t.fired)
+}
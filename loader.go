@@ -0,0 +1,195 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ReadFunc reads the file stored at path.
+type ReadFunc func(path string) ([]byte, error)
+
+// ======================================================
+// Loader
+// ======================================================
+
+// Loader loads maps together with the tilesets and templates they reference.
+//
+// References are resolved relative to the file containing them, the way Tiled stores them.
+// Tilesets and templates are cached by their resolved path, so maps sharing them load each
+// file once. Files ending in .tmj, .tsj or .tj are parsed as JSON, everything else as XML;
+// worlds are always JSON.
+// A Loader is safe for concurrent use.
+type Loader struct {
+	read  ReadFunc
+	fsys  fs.FS // set by NewLoaderFS, to list directories
+	probe bool  // read image headers, see SetProbeImages
+
+	mu        sync.Mutex
+	tilesets  map[string]*Tsx
+	templates *TemplateStore
+}
+
+func NewLoader(read ReadFunc) *Loader {
+	l := &Loader{
+		read:     read,
+		tilesets: make(map[string]*Tsx),
+	}
+	l.templates = NewTemplateStore(l.loadTx)
+	return l
+}
+
+// NewLoaderFS creates a loader reading files from a file system.
+func NewLoaderFS(fsys fs.FS) *Loader {
+	l := NewLoader(func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+	l.fsys = fsys
+	return l
+}
+
+// Templates returns the store caching the templates loaded by the loader.
+func (l *Loader) Templates() *TemplateStore {
+	return l.templates
+}
+
+// LoadTmx loads the map stored at name, attaching the Tsx of every external tileset and
+// loading every template referenced by its objects into the template store.
+func (l *Loader) LoadTmx(name string) (*Tmx, error) {
+	name = path.Clean(name)
+
+	data, err := l.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmx := &Tmx{}
+	if path.Ext(name) == ".tmj" {
+		err = UnmarshalTMJ(data, tmx)
+	} else {
+		err = xml.Unmarshal(data, tmx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	for i := range tmx.Tilesets {
+		ts := &tmx.Tilesets[i]
+		if ts.IsEmbedded() {
+			if err := l.probeTsx(name, ts.Tsx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if ts.Source == "" {
+			continue
+		}
+		tsx, err := l.LoadTsx(ResolvePath(name, ts.Source))
+		if err != nil {
+			return nil, err
+		}
+		ts.Tsx = tsx
+	}
+
+	for i := range tmx.ImageLayers {
+		if err := l.probeImage(name, &tmx.ImageLayers[i].Image); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range tmx.ObjectGroups {
+		for j := range tmx.ObjectGroups[i].Objects {
+			obj := &tmx.ObjectGroups[i].Objects[j]
+			if obj.Template == "" {
+				continue
+			}
+			if _, err := l.LoadTx(ResolvePath(name, obj.Template)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tmx, nil
+}
+
+// LoadTsx loads the tileset stored at name, or returns the cached one.
+func (l *Loader) LoadTsx(name string) (*Tsx, error) {
+	name = path.Clean(name)
+
+	l.mu.Lock()
+	tsx, ok := l.tilesets[name]
+	l.mu.Unlock()
+	if ok {
+		return tsx, nil
+	}
+
+	data, err := l.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tsx = &Tsx{}
+	if path.Ext(name) == ".tsj" {
+		err = UnmarshalTSJ(data, tsx)
+	} else {
+		err = xml.Unmarshal(data, tsx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := l.probeTsx(name, tsx); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if cached, ok := l.tilesets[name]; ok {
+		return cached, nil
+	}
+	l.tilesets[name] = tsx
+	return tsx, nil
+}
+
+// LoadTx loads the template stored at name through the template store.
+func (l *Loader) LoadTx(name string) (*Tx, error) {
+	return l.templates.Get(path.Clean(name))
+}
+
+// loadTx reads a template and attaches the Tsx of its tileset, if it has one.
+func (l *Loader) loadTx(name string) (*Tx, error) {
+	data, err := l.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &Tx{}
+	if path.Ext(name) == ".tj" {
+		err = UnmarshalTJ(data, tx)
+	} else {
+		err = xml.Unmarshal(data, tx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if tx.Tileset.Source != "" {
+		tsx, err := l.LoadTsx(ResolvePath(name, tx.Tileset.Source))
+		if err != nil {
+			return nil, err
+		}
+		tx.Tileset.Tsx = tsx
+	}
+	return tx, nil
+}
+
+// ResolvePath resolves a reference stored in the file at base, e.g. a tileset source or an
+// object template, to a path usable with the same file system.
+func ResolvePath(base, ref string) string {
+	ref = strings.ReplaceAll(ref, "\\", "/")
+	if path.IsAbs(ref) {
+		return path.Clean(ref)
+	}
+	return path.Join(path.Dir(base), ref)
+}
@@ -0,0 +1,72 @@
+package tiled
+
+import "image/color"
+
+// Names of the map properties read by the map metadata accessors. They are ordinary custom
+// properties of the map, so levels can be configured in Tiled's map properties.
+const (
+	MapPropertyGravity = "gravity" // float, in pixels per second squared, Y down
+	MapPropertyMusic   = "music"   // file, the music track relative to the map file
+	MapPropertyAmbient = "ambient" // color, the ambient light color
+)
+
+// ======================================================
+// Map metadata
+// ======================================================
+
+// MapInfo summarizes a map and the per-level configuration authored in its properties.
+type MapInfo struct {
+	Orientation           Orientation
+	Width, Height         int32 // in tiles
+	TileWidth, TileHeight int32 // in pixels
+	Infinite              bool
+
+	Tilesets     int
+	Layers       int // tile layers
+	ObjectGroups int
+	ImageLayers  int
+
+	Gravity float64    // MapPropertyGravity, 0 when not set
+	Music   string     // MapPropertyMusic, empty when not set
+	Ambient color.RGBA // MapPropertyAmbient, white when not set
+
+	Properties Properties // every property of the map, the ones above included
+}
+
+// MapInfo returns the summary of the map.
+func (t *Tmx) MapInfo() MapInfo {
+	return MapInfo{
+		Orientation:  t.Orientation,
+		Width:        t.Width,
+		Height:       t.Height,
+		TileWidth:    t.TileWidth,
+		TileHeight:   t.TileHeight,
+		Infinite:     t.IsInfinite(),
+		Tilesets:     len(t.Tilesets),
+		Layers:       len(t.Layers),
+		ObjectGroups: len(t.ObjectGroups),
+		ImageLayers:  len(t.ImageLayers),
+		Gravity:      t.Gravity(0),
+		Music:        t.Music(),
+		Ambient:      t.Ambient(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}),
+		Properties:   t.Properties,
+	}
+}
+
+// Gravity returns the float MapPropertyGravity of the map, or def when it is missing or
+// doesn't parse.
+func (t *Tmx) Gravity(def float64) float64 {
+	return t.Properties.GetFloat(MapPropertyGravity, def)
+}
+
+// Music returns the file MapPropertyMusic of the map, relative to the map file. Use
+// ResolvePath to locate it. It is empty when the map has none.
+func (t *Tmx) Music() string {
+	return t.Properties.GetFile(MapPropertyMusic, "")
+}
+
+// Ambient returns the color MapPropertyAmbient of the map, or def when it is missing or
+// doesn't parse.
+func (t *Tmx) Ambient(def color.RGBA) color.RGBA {
+	return t.Properties.GetColor(MapPropertyAmbient, def)
+}
@@ -0,0 +1,785 @@
+package tiled
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// FormatVersion is the TMX format version written by MarshalTmx and MarshalTsx.
+const FormatVersion = "1.10"
+
+// ======================================================
+// XML - TMX, TSX and TX
+// ======================================================
+
+// MarshalTmx encodes a map as Tiled XML (.tmx).
+//
+// Layer data is written in its current encoding and compression; use Data.Reencode to
+// change them first. Embedded tilesets are written inline, external ones as a reference.
+func MarshalTmx(tmx *Tmx) ([]byte, error) {
+	return marshalDocument(tmx)
+}
+
+// MarshalTsx encodes a tileset as Tiled XML (.tsx).
+func MarshalTsx(tsx *Tsx) ([]byte, error) {
+	return marshalDocument(tsx)
+}
+
+// MarshalTx encodes a template as Tiled XML (.tx).
+func MarshalTx(tx *Tx) ([]byte, error) {
+	return marshalDocument(tx)
+}
+
+func marshalDocument(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", " ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// ======================================================
+// Reencode
+// ======================================================
+
+// Reencode decodes the layer data, including every chunk, and encodes it again with another
+// encoding and compression. Compression only applies to base64 and is dropped otherwise.
+// The data is left untouched if any of it fails to decode or encode.
+func (dt *Data) Reencode(encoding Encoding, compression Compression) error {
+	if encoding != EncodingBase64 {
+		compression = CompressionNone
+	}
+
+	reencode := func(content string) (string, error) {
+		gids, err := DecodeContent(content, dt.Encoding, dt.Compression)
+		if err != nil {
+			return "", err
+		}
+		return EncodeContent(gids, encoding, compression)
+	}
+
+	if len(dt.Chunks) == 0 {
+		content, err := reencode(dt.Content)
+		if err != nil {
+			return err
+		}
+		dt.Content = content
+	} else {
+		contents := make([]string, len(dt.Chunks))
+		for i := range dt.Chunks {
+			content, err := reencode(dt.Chunks[i].Content)
+			if err != nil {
+				return err
+			}
+			contents[i] = content
+		}
+		for i := range dt.Chunks {
+			dt.Chunks[i].Content = contents[i]
+		}
+	}
+
+	dt.Encoding = encoding
+	dt.Compression = compression
+	return nil
+}
+
+// ======================================================
+// MarshalXML
+// ======================================================
+
+func (t *Tmx) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "map"}
+	start.Attr = []xml.Attr{
+		xmlAttr("version", FormatVersion),
+		xmlAttr("orientation", t.Orientation.String()),
+		xmlAttr("renderorder", t.RenderOrder.String()),
+		xmlIntAttr("width", t.Width),
+		xmlIntAttr("height", t.Height),
+		xmlIntAttr("tilewidth", t.TileWidth),
+		xmlIntAttr("tileheight", t.TileHeight),
+		xmlBoolAttr("infinite", t.IsInfinite()),
+		xmlIntAttr("nextlayerid", t.NextLayerID),
+		xmlIntAttr("nextobjectid", t.NextObjectID),
+	}
+	if t.Orientation == OrientationHexagonal {
+		start.Attr = append(start.Attr, xmlIntAttr("hexsidelength", t.HexSideLength))
+	}
+	if t.IsStaggered() {
+		start.Attr = append(start.Attr,
+			xmlAttr("staggeraxis", t.StaggerAxis.String()),
+			xmlAttr("staggerindex", t.StaggerIndex.String()),
+		)
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, t.Properties); err != nil {
+		return err
+	}
+	for i := range t.Tilesets {
+		if err := e.EncodeElement(&t.Tilesets[i], xmlStart("tileset")); err != nil {
+			return err
+		}
+	}
+	refs := t.Tree
+	if len(refs) == 0 {
+		refs = t.OrderedLayers()
+	}
+	for _, ref := range refs {
+		if err := t.encodeLayer(e, ref, 0); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (t *Tmx) encodeLayer(e *xml.Encoder, ref LayerRef, depth int) error {
+	start := xmlStart(ref.Kind.String())
+	switch ref.Kind {
+	case LayerKindTile:
+		return e.EncodeElement(&t.Layers[ref.Index], start)
+	case LayerKindObject:
+		return e.EncodeElement(&t.ObjectGroups[ref.Index], start)
+	case LayerKindImage:
+		return e.EncodeElement(&t.ImageLayers[ref.Index], start)
+	case LayerKindGroup:
+		if depth >= maxGroupDepth {
+			return nil
+		}
+		return t.encodeGroup(e, &t.Groups[ref.Index], start, depth)
+	}
+	return nil
+}
+
+// encodeGroup writes a group with its children, which are stored in the Tmx.
+func (t *Tmx) encodeGroup(e *xml.Encoder, g *Group, start xml.StartElement, depth int) error {
+	start.Attr = []xml.Attr{
+		xmlIntAttr("id", g.ID),
+		xmlAttr("name", g.Name),
+	}
+	if g.Class != "" {
+		start.Attr = append(start.Attr, xmlAttr("class", g.Class))
+	}
+	start.Attr = appendLayerFlags(start.Attr, g.Flags)
+	start.Attr = appendLayerAttrs(start.Attr, &g.LayerAttrs)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, g.Properties); err != nil {
+		return err
+	}
+	for _, child := range g.Layers {
+		if err := t.encodeLayer(e, child, depth+1); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (t *Tsx) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "tileset"}
+	start.Attr = []xml.Attr{xmlAttr("version", FormatVersion)}
+	return t.marshalXML(e, start)
+}
+
+// marshalXML writes the tileset as the given element, after any attributes already on it.
+func (t *Tsx) marshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr,
+		xmlIntAttr("tilewidth", t.TileWidth),
+		xmlIntAttr("tileheight", t.TileHeight),
+	)
+	if t.Spacing != 0 {
+		start.Attr = append(start.Attr, xmlIntAttr("spacing", t.Spacing))
+	}
+	if t.Margin != 0 {
+		start.Attr = append(start.Attr, xmlIntAttr("margin", t.Margin))
+	}
+	start.Attr = append(start.Attr,
+		xmlIntAttr("tilecount", t.TileCount),
+		xmlIntAttr("columns", t.Columns),
+	)
+	if t.ObjectAlignment != ObjectAlignmentUnspecified {
+		start.Attr = append(start.Attr, xmlAttr("objectalignment", t.ObjectAlignment.String()))
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if t.TileOffset != (Offset{}) {
+		if err := e.EncodeElement(&t.TileOffset, xmlStart("tileoffset")); err != nil {
+			return err
+		}
+	}
+	if err := encodeProperties(e, t.Properties); err != nil {
+		return err
+	}
+	if t.Image != (Image{}) {
+		if err := e.EncodeElement(&t.Image, xmlStart("image")); err != nil {
+			return err
+		}
+	}
+	for i := range t.Tiles {
+		if err := e.EncodeElement(&t.Tiles[i], xmlStart("tile")); err != nil {
+			return err
+		}
+	}
+	if len(t.WangSets) > 0 {
+		wangsets := xmlStart("wangsets")
+		if err := e.EncodeToken(wangsets); err != nil {
+			return err
+		}
+		for i := range t.WangSets {
+			if err := e.EncodeElement(&t.WangSets[i], xmlStart("wangset")); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(wangsets.End()); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (ws *WangSet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "wangset"}
+	start.Attr = []xml.Attr{xmlAttr("name", ws.Name)}
+	if ws.Class != "" {
+		start.Attr = append(start.Attr, xmlAttr("class", ws.Class))
+	}
+	start.Attr = append(start.Attr,
+		xmlAttr("type", ws.Type.String()),
+		xmlIntAttr("tile", ws.Tile),
+	)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, ws.Properties); err != nil {
+		return err
+	}
+	for i := range ws.Colors {
+		if err := e.EncodeElement(&ws.Colors[i], xmlStart("wangcolor")); err != nil {
+			return err
+		}
+	}
+	for i := range ws.Tiles {
+		if err := e.EncodeElement(&ws.Tiles[i], xmlStart("wangtile")); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (t *TsxTile) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "tile"}
+	start.Attr = []xml.Attr{xmlAttr("id", strconv.FormatUint(uint64(t.ID), 10))}
+	if t.Class != "" {
+		// Tiled 1.10 went back to writing the tile class as "type".
+		start.Attr = append(start.Attr, xmlAttr("type", t.Class))
+	}
+	if t.Probability != 1 {
+		start.Attr = append(start.Attr, xmlFloatAttr("probability", t.Probability))
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, t.Properties); err != nil {
+		return err
+	}
+	if t.Image != nil {
+		if err := e.EncodeElement(t.Image, xmlStart("image")); err != nil {
+			return err
+		}
+	}
+	if t.ObjectGroup != nil {
+		if err := e.EncodeElement(t.ObjectGroup, xmlStart("objectgroup")); err != nil {
+			return err
+		}
+	}
+	if t.IsAnimated() {
+		anim := xmlStart("animation")
+		if err := e.EncodeToken(anim); err != nil {
+			return err
+		}
+		for i := range t.Animation {
+			if err := e.EncodeElement(&t.Animation[i], xmlStart("frame")); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(anim.End()); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (ts *Tileset) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "tileset"}
+	start.Attr = []xml.Attr{xmlAttr("firstgid", strconv.FormatUint(uint64(ts.FirstGID), 10))}
+
+	if ts.IsEmbedded() {
+		return ts.Tsx.marshalXML(e, start)
+	}
+
+	start.Attr = append(start.Attr, xmlAttr("source", ts.Source))
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (l *Layer) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "layer"}
+	start.Attr = []xml.Attr{
+		xmlIntAttr("id", l.ID),
+		xmlAttr("name", l.Name),
+	}
+	if l.Class != "" {
+		start.Attr = append(start.Attr, xmlAttr("class", l.Class))
+	}
+	start.Attr = append(start.Attr,
+		xmlIntAttr("width", l.Width),
+		xmlIntAttr("height", l.Height),
+	)
+	start.Attr = appendLayerFlags(start.Attr, l.Flags)
+	start.Attr = appendLayerAttrs(start.Attr, &l.LayerAttrs)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, l.Properties); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(&l.Data, xmlStart("data")); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (il *ImageLayer) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "imagelayer"}
+	start.Attr = []xml.Attr{
+		xmlIntAttr("id", il.ID),
+		xmlAttr("name", il.Name),
+	}
+	if il.Class != "" {
+		start.Attr = append(start.Attr, xmlAttr("class", il.Class))
+	}
+	start.Attr = appendLayerFlags(start.Attr, il.Flags)
+	start.Attr = appendLayerAttrs(start.Attr, &il.LayerAttrs)
+	if il.RepeatX {
+		start.Attr = append(start.Attr, xmlBoolAttr("repeatx", true))
+	}
+	if il.RepeatY {
+		start.Attr = append(start.Attr, xmlBoolAttr("repeaty", true))
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, il.Properties); err != nil {
+		return err
+	}
+	if il.Image != (Image{}) {
+		if err := e.EncodeElement(&il.Image, xmlStart("image")); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (dt *Data) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "data"}
+	start.Attr = nil
+
+	// XML encoded data is identified by the absence of the encoding attribute.
+	if dt.Encoding != EncodingXML {
+		start.Attr = append(start.Attr, xmlAttr("encoding", dt.Encoding.String()))
+		if dt.Compression != CompressionNone {
+			start.Attr = append(start.Attr, xmlAttr("compression", dt.Compression.String()))
+		}
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if len(dt.Chunks) == 0 {
+		if err := dt.encodeContent(e, dt.Content); err != nil {
+			return err
+		}
+	}
+
+	for i := range dt.Chunks {
+		c := &dt.Chunks[i]
+		chunk := xml.StartElement{
+			Name: xml.Name{Local: "chunk"},
+			Attr: []xml.Attr{
+				xmlIntAttr("x", c.X),
+				xmlIntAttr("y", c.Y),
+				xmlIntAttr("width", c.Width),
+				xmlIntAttr("height", c.Height),
+			},
+		}
+		if err := e.EncodeToken(chunk); err != nil {
+			return err
+		}
+		if err := dt.encodeContent(e, c.Content); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(chunk.End()); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// encodeContent writes layer or chunk content, expanding XML encoded content back into
+// <tile> elements.
+func (dt *Data) encodeContent(e *xml.Encoder, content string) error {
+	if dt.Encoding != EncodingXML {
+		return e.EncodeToken(xml.CharData(content))
+	}
+
+	gids, err := DecodeContent(content, dt.Encoding, dt.Compression)
+	if err != nil {
+		return err
+	}
+
+	tile := xmlStart("tile")
+	for _, gid := range gids {
+		tile.Attr = tile.Attr[:0]
+		if gid != 0 {
+			tile.Attr = append(tile.Attr, xmlAttr("gid", strconv.FormatUint(uint64(gid), 10)))
+		}
+		if err := e.EncodeToken(tile); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(tile.End()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (og *ObjectGroup) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "objectgroup"}
+	start.Attr = []xml.Attr{
+		xmlIntAttr("id", og.ID),
+		xmlAttr("name", og.Name),
+	}
+	if og.Class != "" {
+		start.Attr = append(start.Attr, xmlAttr("class", og.Class))
+	}
+	start.Attr = appendLayerFlags(start.Attr, og.Flags)
+	start.Attr = appendLayerAttrs(start.Attr, &og.LayerAttrs)
+	if og.DrawOrder == DrawOrderIndex {
+		// Tiled only writes the draw order when it isn't the default, topdown.
+		start.Attr = append(start.Attr, xmlAttr("draworder", og.DrawOrder.String()))
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, og.Properties); err != nil {
+		return err
+	}
+	for i := range og.Objects {
+		if err := e.EncodeElement(&og.Objects[i], xmlStart("object")); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (o *Object) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "object"}
+	start.Attr = []xml.Attr{xmlIntAttr("id", o.ID)}
+	if o.Name != "" {
+		start.Attr = append(start.Attr, xmlAttr("name", o.Name))
+	}
+	if o.Class != "" {
+		// Tiled 1.10 went back to writing the object class as "type".
+		start.Attr = append(start.Attr, xmlAttr("type", o.Class))
+	}
+	if o.Template != "" {
+		start.Attr = append(start.Attr, xmlAttr("template", o.Template))
+	}
+	if o.GID != 0 {
+		start.Attr = append(start.Attr, xmlAttr("gid", strconv.FormatUint(uint64(o.GID), 10)))
+	}
+	start.Attr = append(start.Attr,
+		xmlFloatAttr("x", o.X),
+		xmlFloatAttr("y", o.Y),
+	)
+	if o.Width != 0 {
+		start.Attr = append(start.Attr, xmlFloatAttr("width", o.Width))
+	}
+	if o.Height != 0 {
+		start.Attr = append(start.Attr, xmlFloatAttr("height", o.Height))
+	}
+	if o.Rotation != 0 {
+		start.Attr = append(start.Attr, xmlFloatAttr("rotation", o.Rotation))
+	}
+	if !o.IsVisible() {
+		start.Attr = append(start.Attr, xmlAttr("visible", "0"))
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, o.Properties); err != nil {
+		return err
+	}
+
+	switch {
+	case o.IsEllipse():
+		if err := encodeEmpty(e, "ellipse"); err != nil {
+			return err
+		}
+	case o.IsPoint():
+		if err := encodeEmpty(e, "point"); err != nil {
+			return err
+		}
+	}
+	if !o.Polygon.IsEmpty() {
+		if err := e.EncodeElement(&o.Polygon, xmlStart("polygon")); err != nil {
+			return err
+		}
+	}
+	if !o.Polyline.IsEmpty() {
+		if err := e.EncodeElement(&o.Polyline, xmlStart("polyline")); err != nil {
+			return err
+		}
+	}
+	if o.Text != nil {
+		if err := e.EncodeElement(o.Text, xmlStart("text")); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (t *Text) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "text"}
+	start.Attr = nil
+	if t.FontFamily != "" {
+		start.Attr = append(start.Attr, xmlAttr("fontfamily", t.FontFamily))
+	}
+	if t.PixelSize != 0 && t.PixelSize != 16 {
+		start.Attr = append(start.Attr, xmlIntAttr("pixelsize", t.PixelSize))
+	}
+	if t.Wrap {
+		start.Attr = append(start.Attr, xmlBoolAttr("wrap", true))
+	}
+	if t.Color != "" {
+		start.Attr = append(start.Attr, xmlAttr("color", t.Color))
+	}
+	if t.Bold {
+		start.Attr = append(start.Attr, xmlBoolAttr("bold", true))
+	}
+	if t.Italic {
+		start.Attr = append(start.Attr, xmlBoolAttr("italic", true))
+	}
+	if t.Underline {
+		start.Attr = append(start.Attr, xmlBoolAttr("underline", true))
+	}
+	if t.HAlign != HAlignLeft {
+		start.Attr = append(start.Attr, xmlAttr("halign", t.HAlign.String()))
+	}
+	if t.VAlign != VAlignTop {
+		start.Attr = append(start.Attr, xmlAttr("valign", t.VAlign.String()))
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.EncodeToken(xml.CharData(t.Content)); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (p *Polygon) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	var sb strings.Builder
+	for i := 0; i+1 < len(p.Points); i += 2 {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(formatFloat(p.Points[i]))
+		sb.WriteByte(',')
+		sb.WriteString(formatFloat(p.Points[i+1]))
+	}
+
+	start.Attr = []xml.Attr{xmlAttr("points", sb.String())}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (wc *WangColor) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "wangcolor"}
+	start.Attr = []xml.Attr{xmlAttr("name", wc.Name)}
+	if wc.Class != "" {
+		start.Attr = append(start.Attr, xmlAttr("class", wc.Class))
+	}
+	start.Attr = append(start.Attr,
+		xmlAttr("color", wc.Color),
+		xmlIntAttr("tile", wc.Tile),
+		xmlFloatAttr("probability", wc.Probability),
+	)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, wc.Properties); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (tx *Tx) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "template"}
+	start.Attr = nil
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if tx.Tileset.Source != "" || tx.Tileset.Tsx != nil {
+		if err := e.EncodeElement(&tx.Tileset, xmlStart("tileset")); err != nil {
+			return err
+		}
+	}
+	if err := e.EncodeElement(&tx.Objects, xmlStart("object")); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (p *Property) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "property"}
+	start.Attr = []xml.Attr{xmlAttr("name", p.Name)}
+	if p.Type != PropertyKindString {
+		start.Attr = append(start.Attr, xmlAttr("type", p.Type.String()))
+	}
+	if p.PropertyType != "" {
+		start.Attr = append(start.Attr, xmlAttr("propertytype", p.PropertyType))
+	}
+	// Class properties carry their members instead of a value.
+	if len(p.Properties) == 0 {
+		start.Attr = append(start.Attr, xmlAttr("value", p.Value))
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeProperties(e, p.Properties); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// ======================================================
+// Helpers
+// ======================================================
+
+func encodeProperties(e *xml.Encoder, props []Property) error {
+	if len(props) == 0 {
+		return nil
+	}
+
+	start := xmlStart("properties")
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := range props {
+		if err := e.EncodeElement(&props[i], xmlStart("property")); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func encodeEmpty(e *xml.Encoder, name string) error {
+	start := xmlStart(name)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func appendLayerFlags(attrs []xml.Attr, flags LayerFlag) []xml.Attr {
+	if flags&LayerFlagVisible == 0 {
+		attrs = append(attrs, xmlAttr("visible", "0"))
+	}
+	if flags&LayerFlagLocked != 0 {
+		attrs = append(attrs, xmlAttr("locked", "1"))
+	}
+	return attrs
+}
+
+// appendLayerAttrs appends the display attributes that differ from DefaultLayerAttrs.
+func appendLayerAttrs(attrs []xml.Attr, a *LayerAttrs) []xml.Attr {
+	if a.Opacity != 1 {
+		attrs = append(attrs, xmlFloatAttr("opacity", a.Opacity))
+	}
+	if a.TintColor != "" {
+		attrs = append(attrs, xmlAttr("tintcolor", a.TintColor))
+	}
+	if a.OffsetX != 0 {
+		attrs = append(attrs, xmlFloatAttr("offsetx", a.OffsetX))
+	}
+	if a.OffsetY != 0 {
+		attrs = append(attrs, xmlFloatAttr("offsety", a.OffsetY))
+	}
+	if a.ParallaxX != 1 {
+		attrs = append(attrs, xmlFloatAttr("parallaxx", a.ParallaxX))
+	}
+	if a.ParallaxY != 1 {
+		attrs = append(attrs, xmlFloatAttr("parallaxy", a.ParallaxY))
+	}
+	return attrs
+}
+
+func xmlStart(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+func xmlAttr(name, value string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: value}
+}
+
+func xmlIntAttr(name string, value int32) xml.Attr {
+	return xmlAttr(name, strconv.FormatInt(int64(value), 10))
+}
+
+func xmlFloatAttr(name string, value float32) xml.Attr {
+	return xmlAttr(name, formatFloat(value))
+}
+
+func xmlBoolAttr(name string, value bool) xml.Attr {
+	if value {
+		return xmlAttr(name, "1")
+	}
+	return xmlAttr(name, "0")
+}
+
+func formatFloat(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
@@ -0,0 +1,68 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestMarshalObjectGroupDrawOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		attr  string
+		order DrawOrder
+		want  string
+	}{
+		{name: "default", order: DrawOrderTopDown},
+		{name: "topdown", attr: ` draworder="topdown"`, order: DrawOrderTopDown},
+		{name: "index", attr: ` draworder="index"`, order: DrawOrderIndex, want: `draworder="index"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `<map version="1.10" orientation="orthogonal" renderorder="right-down" width="1" height="1" tilewidth="16" tileheight="16" infinite="0" nextlayerid="3" nextobjectid="2">
+ <objectgroup id="2" name="o"` + tt.attr + `>
+  <object id="1" x="0" y="0"/>
+ </objectgroup>
+</map>`
+			var tmx Tmx
+			if err := xml.Unmarshal([]byte(src), &tmx); err != nil {
+				t.Fatal(err)
+			}
+			if got := tmx.ObjectGroups[0].DrawOrder; got != tt.order {
+				t.Fatalf("decoded draw order = %v, want %v", got, tt.order)
+			}
+
+			out, err := MarshalTmx(&tmx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			has := strings.Contains(string(out), "draworder")
+			if tt.want == "" && has {
+				t.Fatalf("default draw order written:\n%s", out)
+			}
+			if tt.want != "" && !strings.Contains(string(out), tt.want) {
+				t.Fatalf("output lacks %s:\n%s", tt.want, out)
+			}
+
+			var again Tmx
+			if err := xml.Unmarshal(out, &again); err != nil {
+				t.Fatal(err)
+			}
+			if got := again.ObjectGroups[0].DrawOrder; got != tt.order {
+				t.Fatalf("round-tripped draw order = %v, want %v", got, tt.order)
+			}
+		})
+	}
+}
+
+func TestUnmarshalTMJObjectGroupDrawOrder(t *testing.T) {
+	src := `{"type":"map","orientation":"orthogonal","renderorder":"right-down","width":1,"height":1,
+		"tilewidth":16,"tileheight":16,"layers":[{"type":"objectgroup","id":2,"name":"o","visible":true,"opacity":1,"objects":[]}]}`
+	var tmx Tmx
+	if err := UnmarshalTMJ([]byte(src), &tmx); err != nil {
+		t.Fatal(err)
+	}
+	if got := tmx.ObjectGroups[0].DrawOrder; got != DrawOrderTopDown {
+		t.Fatalf("decoded draw order = %v, want %v", got, DrawOrderTopDown)
+	}
+}
@@ -2,13 +2,18 @@ package tiled
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"image"
+	"image/color"
 	"strconv"
 	"strings"
 
-	"github.com/adm87/enum"
+	"github.com/adm87/tiled/internal/enum"
 )
 
+var ErrInvalidTileset = errors.New("invalid tileset layout")
+
 // ======================================================
 // Tmx - Tiled Map XML
 // ======================================================
@@ -23,20 +28,156 @@ type Tmx struct {
 	Orientation Orientation `xml:"-"`
 	RenderOrder RenderOrder `xml:"-"`
 
+	// HexSideLength, StaggerAxis and StaggerIndex describe the layout of staggered and
+	// hexagonal maps. HexSideLength is the length of the hexagon's flat side along the
+	// stagger axis, in pixels; staggered maps leave it 0.
+	HexSideLength int32        `xml:"hexsidelength,attr,omitempty"`
+	StaggerAxis   StaggerAxis  `xml:"-"`
+	StaggerIndex  StaggerIndex `xml:"-"`
+
 	NextLayerID  int32 `xml:"nextlayerid,attr"`
 	NextObjectID int32 `xml:"nextobjectid,attr"`
 
 	Tilesets     []Tileset     `xml:"tileset,omitempty"`
 	Layers       []Layer       `xml:"layer,omitempty"`
 	ObjectGroups []ObjectGroup `xml:"objectgroup,omitempty"`
+	ImageLayers  []ImageLayer  `xml:"imagelayer,omitempty"`
+
+	Groups []Group `xml:"-"`
+
+	// LayerOrder lists the layers of every kind in document order, bottom to top, including
+	// the layers nested in groups. Groups themselves are not listed.
+	LayerOrder []LayerRef `xml:"-"`
+
+	// Tree lists the top level layers and groups in document order. Each group lists its own
+	// children in Group.Layers. Maps without groups may leave it empty.
+	Tree []LayerRef `xml:"-"`
 
-	Properties []Property `xml:"properties>property,omitempty"`
+	Properties Properties `xml:"properties>property,omitempty"`
+}
+
+// LayerRef locates a layer in the slice of its kind: Layers, ObjectGroups, ImageLayers or Groups.
+type LayerRef struct {
+	Kind  LayerKind
+	Index int
 }
 
 func (t *Tmx) IsInfinite() bool {
 	return t.Flags&MapFlagInfinite != 0
 }
 
+// IsStaggered reports whether every other row or column of the map is shifted, which is the
+// case for staggered and hexagonal maps.
+func (t *Tmx) IsStaggered() bool {
+	return t.Orientation == OrientationStaggered || t.Orientation == OrientationHexagonal
+}
+
+// OrderedLayers returns the layers of every kind in draw order, bottom to top.
+// Maps built without a LayerOrder list tile layers first, then object groups, then
+// image layers.
+func (t *Tmx) OrderedLayers() []LayerRef {
+	if len(t.LayerOrder) > 0 {
+		return t.LayerOrder
+	}
+
+	order := make([]LayerRef, 0, len(t.Layers)+len(t.ObjectGroups)+len(t.ImageLayers))
+	for i := range t.Layers {
+		order = append(order, LayerRef{Kind: LayerKindTile, Index: i})
+	}
+	for i := range t.ObjectGroups {
+		order = append(order, LayerRef{Kind: LayerKindObject, Index: i})
+	}
+	for i := range t.ImageLayers {
+		order = append(order, LayerRef{Kind: LayerKindImage, Index: i})
+	}
+	return order
+}
+
+// AnyLayers returns the layers of every kind in draw order, bottom to top, so renderers can
+// draw them exactly as authored in Tiled, e.g. tiles, then objects, then more tiles. Layers
+// nested in groups are included; use FlattenLayers for the state they inherit.
+//
+// The layers point into the slices of the Tmx, and are invalidated when those are resized.
+func (t *Tmx) AnyLayers() []AnyLayer {
+	order := t.OrderedLayers()
+	layers := make([]AnyLayer, 0, len(order))
+	for _, ref := range order {
+		if layer, ok := t.anyLayer(ref); ok {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// anyLayer returns the layer a reference points to, or false if it points to none.
+func (t *Tmx) anyLayer(ref LayerRef) (AnyLayer, bool) {
+	layer := AnyLayer{LayerRef: ref}
+	switch ref.Kind {
+	case LayerKindTile:
+		if ref.Index >= 0 && ref.Index < len(t.Layers) {
+			layer.Tile = &t.Layers[ref.Index]
+		}
+	case LayerKindObject:
+		if ref.Index >= 0 && ref.Index < len(t.ObjectGroups) {
+			layer.Objects = &t.ObjectGroups[ref.Index]
+		}
+	case LayerKindImage:
+		if ref.Index >= 0 && ref.Index < len(t.ImageLayers) {
+			layer.Image = &t.ImageLayers[ref.Index]
+		}
+	}
+	return layer, layer.Tile != nil || layer.Objects != nil || layer.Image != nil
+}
+
+// AnyLayer is a layer of any kind. Exactly one of Tile, Objects and Image is set, the one
+// matching Kind.
+type AnyLayer struct {
+	LayerRef
+
+	Tile    *Layer
+	Objects *ObjectGroup
+	Image   *ImageLayer
+}
+
+// ID returns the layer's unique ID.
+func (l AnyLayer) ID() int32 {
+	switch {
+	case l.Tile != nil:
+		return l.Tile.ID
+	case l.Objects != nil:
+		return l.Objects.ID
+	case l.Image != nil:
+		return l.Image.ID
+	}
+	return 0
+}
+
+// Name returns the layer's name.
+func (l AnyLayer) Name() string {
+	switch {
+	case l.Tile != nil:
+		return l.Tile.Name
+	case l.Objects != nil:
+		return l.Objects.Name
+	case l.Image != nil:
+		return l.Image.Name
+	}
+	return ""
+}
+
+// IsVisible reports whether the layer itself is visible, not taking its groups into account.
+func (l AnyLayer) IsVisible() bool {
+	switch {
+	case l.Tile != nil:
+		return l.Tile.IsVisible()
+	case l.Objects != nil:
+		return l.Objects.IsVisible()
+	case l.Image != nil:
+		return l.Image.IsVisible()
+	}
+	return false
+}
+
 func (t *Tmx) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	for _, attr := range start.Attr {
 		switch attr.Name.Local {
@@ -56,13 +197,147 @@ func (t *Tmx) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 				return err
 			}
 			t.RenderOrder = val
+		case "staggeraxis":
+			val, err := enum.UnmarshalEnum[StaggerAxis](attr.Value)
+			if err != nil {
+				return err
+			}
+			t.StaggerAxis = val
+		case "staggerindex":
+			val, err := enum.UnmarshalEnum[StaggerIndex](attr.Value)
+			if err != nil {
+				return err
+			}
+			t.StaggerIndex = val
+		default:
+			if dst := t.intAttr(attr.Name.Local); dst != nil {
+				val, err := strconv.ParseInt(attr.Value, 10, 32)
+				if err != nil {
+					return fmt.Errorf("invalid %s: %w", attr.Name.Local, err)
+				}
+				*dst = int32(val)
+			}
 		}
 	}
 
-	type tmxAlias Tmx
-	aux := (*tmxAlias)(t)
+	return t.decodeChildren(d, -1)
+}
+
+// decodeChildren decodes the children of the map, or of the group at index parent, one by
+// one to keep the order of the layers.
+func (t *Tmx) decodeChildren(d *xml.Decoder, parent int) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
 
-	return d.DecodeElement(aux, &start)
+		switch el := tok.(type) {
+		case xml.StartElement:
+			if err := t.decodeChild(d, el, parent); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// addLayer records a decoded layer in document order, under the group at index parent.
+func (t *Tmx) addLayer(ref LayerRef, parent int) {
+	if ref.Kind != LayerKindGroup {
+		t.LayerOrder = append(t.LayerOrder, ref)
+	}
+	if parent < 0 {
+		t.Tree = append(t.Tree, ref)
+	} else {
+		t.Groups[parent].Layers = append(t.Groups[parent].Layers, ref)
+	}
+}
+
+func (t *Tmx) intAttr(name string) *int32 {
+	switch name {
+	case "width":
+		return &t.Width
+	case "height":
+		return &t.Height
+	case "tilewidth":
+		return &t.TileWidth
+	case "tileheight":
+		return &t.TileHeight
+	case "nextlayerid":
+		return &t.NextLayerID
+	case "nextobjectid":
+		return &t.NextObjectID
+	case "hexsidelength":
+		return &t.HexSideLength
+	}
+	return nil
+}
+
+func (t *Tmx) decodeChild(d *xml.Decoder, start xml.StartElement, parent int) error {
+	switch start.Name.Local {
+	case "tileset":
+		if parent >= 0 {
+			return d.Skip()
+		}
+		var ts Tileset
+		if err := d.DecodeElement(&ts, &start); err != nil {
+			return err
+		}
+		t.Tilesets = append(t.Tilesets, ts)
+
+	case "layer":
+		var layer Layer
+		if err := d.DecodeElement(&layer, &start); err != nil {
+			return err
+		}
+		t.addLayer(LayerRef{Kind: LayerKindTile, Index: len(t.Layers)}, parent)
+		t.Layers = append(t.Layers, layer)
+
+	case "objectgroup":
+		var og ObjectGroup
+		if err := d.DecodeElement(&og, &start); err != nil {
+			return err
+		}
+		t.addLayer(LayerRef{Kind: LayerKindObject, Index: len(t.ObjectGroups)}, parent)
+		t.ObjectGroups = append(t.ObjectGroups, og)
+
+	case "imagelayer":
+		var il ImageLayer
+		if err := d.DecodeElement(&il, &start); err != nil {
+			return err
+		}
+		t.addLayer(LayerRef{Kind: LayerKindImage, Index: len(t.ImageLayers)}, parent)
+		t.ImageLayers = append(t.ImageLayers, il)
+
+	case "group":
+		var g Group
+		if err := g.unmarshalAttrs(start.Attr); err != nil {
+			return err
+		}
+		index := len(t.Groups)
+		t.addLayer(LayerRef{Kind: LayerKindGroup, Index: index}, parent)
+		t.Groups = append(t.Groups, g)
+		return t.decodeChildren(d, index)
+
+	case "properties":
+		var props struct {
+			Properties []Property `xml:"property"`
+		}
+		if err := d.DecodeElement(&props, &start); err != nil {
+			return err
+		}
+		if parent >= 0 {
+			t.Groups[parent].Properties = append(t.Groups[parent].Properties, props.Properties...)
+		} else {
+			t.Properties = append(t.Properties, props.Properties...)
+		}
+
+	default:
+		return d.Skip()
+	}
+	return nil
 }
 
 // ======================================================
@@ -74,13 +349,17 @@ type Tsx struct {
 	TileHeight int32 `xml:"tileheight,attr"`
 	TileCount  int32 `xml:"tilecount,attr"`
 	Columns    int32 `xml:"columns,attr"`
+	Spacing    int32 `xml:"spacing,attr,omitempty"`
+	Margin     int32 `xml:"margin,attr,omitempty"`
 
 	Image      Image  `xml:"image,omitempty"`
 	TileOffset Offset `xml:"tileoffset,omitempty"`
 
 	ObjectAlignment ObjectAlignment `xml:"-"`
 
-	Properties []Property `xml:"properties>property,omitempty"`
+	Tiles      []TsxTile  `xml:"tile,omitempty"`
+	WangSets   []WangSet  `xml:"wangsets>wangset,omitempty"`
+	Properties Properties `xml:"properties>property,omitempty"`
 }
 
 func (t *Tsx) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -98,13 +377,150 @@ func (t *Tsx) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	type tsxAlias Tsx
 	aux := (*tsxAlias)(t)
 
+	if err := d.DecodeElement(aux, &start); err != nil {
+		return err
+	}
+
+	t.InferLayout()
+	return nil
+}
+
+// InferLayout fills in missing columns and tile count from the image dimensions, tile size,
+// spacing and margin. Values that are present are left as is; use Validate to detect values
+// that disagree with the image.
+func (t *Tsx) InferLayout() {
+	cols, rows := t.imageGrid()
+	if t.Columns <= 0 && cols > 0 {
+		t.Columns = cols
+	}
+	if t.TileCount <= 0 && cols > 0 && rows > 0 {
+		t.TileCount = cols * rows
+	}
+}
+
+// Validate reports whether the tileset layout is consistent with its image, returning an
+// error wrapping ErrInvalidTileset if the columns or tile count don't fit the image.
+func (t *Tsx) Validate() error {
+	if t.TileWidth <= 0 || t.TileHeight <= 0 {
+		return fmt.Errorf("%w: tile size %dx%d", ErrInvalidTileset, t.TileWidth, t.TileHeight)
+	}
+
+	cols, rows := t.imageGrid()
+	if cols <= 0 || rows <= 0 {
+		// Image collections and tilesets without image dimensions can't be checked.
+		return nil
+	}
+
+	if t.Columns != cols {
+		return fmt.Errorf("%w: %d columns, image fits %d", ErrInvalidTileset, t.Columns, cols)
+	}
+	if t.TileCount > cols*rows {
+		return fmt.Errorf("%w: %d tiles, image fits %d", ErrInvalidTileset, t.TileCount, cols*rows)
+	}
+	return nil
+}
+
+// TileRect returns the source rectangle of a local tile ID within the tileset image.
+// For image collection tilesets, it is the bounds of the tile's own image, when known.
+func (t *Tsx) TileRect(tileID uint32) (x, y, w, h int32) {
+	w, h = t.TileWidth, t.TileHeight
+	if t.Columns <= 0 {
+		if tile := t.TileByID(tileID); tile != nil && tile.Image != nil && tile.Image.Width > 0 && tile.Image.Height > 0 {
+			return 0, 0, tile.Image.Width, tile.Image.Height
+		}
+		return 0, 0, w, h
+	}
+
+	col := int32(tileID) % t.Columns
+	row := int32(tileID) / t.Columns
+
+	x = t.Margin + col*(t.TileWidth+t.Spacing)
+	y = t.Margin + row*(t.TileHeight+t.Spacing)
+	return x, y, w, h
+}
+
+// TileBounds returns the source rectangle of a local tile ID as an image.Rectangle.
+func (t *Tsx) TileBounds(tileID uint32) image.Rectangle {
+	x, y, w, h := t.TileRect(tileID)
+	return image.Rect(int(x), int(y), int(x+w), int(y+h))
+}
+
+// TileByID returns the data the tileset defines for a local tile ID, or nil if it has none.
+func (t *Tsx) TileByID(tileID uint32) *TsxTile {
+	for i := range t.Tiles {
+		if t.Tiles[i].ID == tileID {
+			return &t.Tiles[i]
+		}
+	}
+	return nil
+}
+
+// imageGrid returns how many whole tiles fit the image horizontally and vertically.
+func (t *Tsx) imageGrid() (cols, rows int32) {
+	if t.TileWidth <= 0 || t.TileHeight <= 0 {
+		return 0, 0
+	}
+	if t.Image.Width > 0 {
+		cols = (t.Image.Width - 2*t.Margin + t.Spacing) / (t.TileWidth + t.Spacing)
+	}
+	if t.Image.Height > 0 {
+		rows = (t.Image.Height - 2*t.Margin + t.Spacing) / (t.TileHeight + t.Spacing)
+	}
+	return cols, rows
+}
+
+// ======================================================
+// TsxTile
+// ======================================================
+
+// TsxTile holds the data a tileset defines for one of its tiles. Only tiles with data of their
+// own, such as an animation or properties, have an entry.
+type TsxTile struct {
+	ID          uint32  `xml:"id,attr"`
+	Class       string  `xml:"class,attr,omitempty"`
+	Probability float32 `xml:"probability,attr,omitempty"` // chance of being picked by terrain and random tools, defaults to 1
+
+	Image       *Image       `xml:"image,omitempty"`       // own image of tiles in image collection tilesets
+	ObjectGroup *ObjectGroup `xml:"objectgroup,omitempty"` // collision shapes, relative to the tile's top-left corner
+	Animation   Animation    `xml:"animation>frame,omitempty"`
+	Properties  Properties   `xml:"properties>property,omitempty"`
+}
+
+func (t *TsxTile) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	t.Probability = 1
+
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "type":
+			// Tilesets saved before Tiled 1.9 store the class as "type".
+			if t.Class == "" {
+				t.Class = attr.Value
+			}
+		}
+	}
+
+	type tileAlias TsxTile
+	aux := (*tileAlias)(t)
+
 	return d.DecodeElement(aux, &start)
 }
 
+// HasCollision reports whether the tile has collision shapes.
+func (t *TsxTile) HasCollision() bool {
+	return t.ObjectGroup != nil && len(t.ObjectGroup.Objects) > 0
+}
+
+// IsAnimated reports whether the tile has an animation.
+func (t *TsxTile) IsAnimated() bool {
+	return len(t.Animation) > 0
+}
+
 // ======================================================
 // Data
 // ======================================================
 
+// Data holds the encoded tiles of a layer. With the XML encoding, Content holds the GIDs of
+// the <tile> elements, comma separated, so DecodeContent handles every encoding the same way.
 type Data struct {
 	Encoding    Encoding    `xml:"-"`
 	Compression Compression `xml:"-"`
@@ -115,6 +531,9 @@ type Data struct {
 }
 
 func (dt *Data) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	// Without an encoding attribute, tiles are stored as <tile> elements.
+	dt.Encoding = EncodingXML
+
 	for _, attr := range start.Attr {
 		switch attr.Name.Local {
 		case "encoding":
@@ -133,9 +552,37 @@ func (dt *Data) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	}
 
 	type dataAlias Data
-	aux := (*dataAlias)(dt)
+	aux := struct {
+		*dataAlias
+		Tiles xmlTiles `xml:"tile"`
+	}{
+		dataAlias: (*dataAlias)(dt),
+	}
 
-	return d.DecodeElement(aux, &start)
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+
+	if dt.Encoding == EncodingXML {
+		dt.Content = aux.Tiles.csv()
+	}
+	return nil
+}
+
+// xmlTiles are the <tile> elements of XML encoded layer data.
+type xmlTiles []struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+func (t xmlTiles) csv() string {
+	var sb strings.Builder
+	for i := range t {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(t[i].GID), 10))
+	}
+	return sb.String()
 }
 
 // ======================================================
@@ -146,15 +593,52 @@ type ObjectGroup struct {
 	Flags     LayerFlag `xml:"-"`
 	DrawOrder DrawOrder `xml:"-"`
 
-	ID   int32  `xml:"id,attr"`
-	Name string `xml:"name,attr"`
+	ID    int32  `xml:"id,attr"`
+	Name  string `xml:"name,attr"`
+	Class string `xml:"class,attr,omitempty"`
+
+	LayerAttrs
 
 	Objects    []Object   `xml:"object,omitempty"`
-	Properties []Property `xml:"properties>property,omitempty"`
+	Properties Properties `xml:"properties>property,omitempty"`
+}
+
+func (og *ObjectGroup) IsLocked() bool {
+	return og.Flags&LayerFlagLocked != 0
+}
+
+func (og *ObjectGroup) IsVisible() bool {
+	return og.Flags&LayerFlagVisible != 0
+}
+
+// VisibleObjects returns the visible objects of the group, or nil if the group is hidden.
+func (og *ObjectGroup) VisibleObjects() []*Object {
+	return og.QueryObjects(0)
+}
+
+// QueryObjects returns the objects of the group that pass the query flags.
+func (og *ObjectGroup) QueryObjects(flags QueryFlag) []*Object {
+	if flags&QueryFlagIncludeHidden == 0 && !og.IsVisible() {
+		return nil
+	}
+	if flags&QueryFlagExcludeLocked != 0 && og.IsLocked() {
+		return nil
+	}
+
+	var objects []*Object
+	for i := range og.Objects {
+		if flags&QueryFlagIncludeHidden == 0 && !og.Objects[i].IsVisible() {
+			continue
+		}
+		objects = append(objects, &og.Objects[i])
+	}
+	return objects
 }
 
 func (og *ObjectGroup) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	og.Flags |= LayerFlagVisible
+	og.DrawOrder = DrawOrderTopDown
+	og.LayerAttrs = DefaultLayerAttrs()
 
 	for _, attr := range start.Attr {
 		switch attr.Name.Local {
@@ -201,12 +685,16 @@ type Object struct {
 	ID       int32  `xml:"id,attr"`
 	GID      uint32 `xml:"gid,attr,omitempty"`
 	Name     string `xml:"name,attr,omitempty"`
+	Class    string `xml:"class,attr,omitempty"`
 	Template string `xml:"template,attr,omitempty"`
 
 	Polyline Polygon `xml:"polyline,omitempty"`
 	Polygon  Polygon `xml:"polygon,omitempty"`
+	Text     *Text   `xml:"text,omitempty"`
+
+	Properties Properties `xml:"properties>property,omitempty"`
 
-	Properties []Property `xml:"properties>property,omitempty"`
+	overrides objectAttr // attributes set by the object itself, for template instances
 }
 
 func (o *Object) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -220,19 +708,57 @@ func (o *Object) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			} else {
 				o.Flags |= ObjectFlagVisible
 			}
+			o.overrides |= objectAttrVisible
 		case "template":
 			if attr.Value != "" {
 				o.Flags |= ObjectFlagTemplate
 			} else {
 				o.Flags &^= ObjectFlagTemplate
 			}
+		case "type":
+			// Maps saved before Tiled 1.9 store the class as "type".
+			if o.Class == "" {
+				o.Class = attr.Value
+			}
+			o.overrides |= objectAttrClass
+		case "class":
+			o.overrides |= objectAttrClass
+		case "name":
+			o.overrides |= objectAttrName
+		case "width":
+			o.overrides |= objectAttrWidth
+		case "height":
+			o.overrides |= objectAttrHeight
+		case "rotation":
+			o.overrides |= objectAttrRotation
+		case "gid":
+			o.overrides |= objectAttrGID
 		}
 	}
 
 	type objectAlias Object
-	aux := (*objectAlias)(o)
+	aux := struct {
+		*objectAlias
+		Ellipse *struct{} `xml:"ellipse"`
+		Point   *struct{} `xml:"point"`
+	}{
+		objectAlias: (*objectAlias)(o),
+	}
 
-	return d.DecodeElement(aux, &start)
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+
+	if aux.Ellipse != nil {
+		o.Flags |= ObjectFlagEllipse
+	}
+	if aux.Point != nil {
+		o.Flags |= ObjectFlagPoint
+	}
+	if o.hasShape() {
+		o.overrides |= objectAttrShape
+	}
+	return nil
 }
 
 func (o *Object) IsVisible() bool {
@@ -243,6 +769,97 @@ func (o *Object) IsTemplate() bool {
 	return o.Flags&ObjectFlagTemplate != 0
 }
 
+func (o *Object) IsEllipse() bool {
+	return o.Flags&ObjectFlagEllipse != 0
+}
+
+func (o *Object) IsPoint() bool {
+	return o.Flags&ObjectFlagPoint != 0
+}
+
+// ======================================================
+// Text
+// ======================================================
+
+type Text struct {
+	FontFamily string `xml:"fontfamily,attr,omitempty"`
+	PixelSize  int32  `xml:"pixelsize,attr,omitempty"`
+	Color      string `xml:"color,attr,omitempty"`
+
+	Wrap      bool `xml:"-"`
+	Bold      bool `xml:"-"`
+	Italic    bool `xml:"-"`
+	Underline bool `xml:"-"`
+
+	HAlign HAlign `xml:"-"`
+	VAlign VAlign `xml:"-"`
+
+	Content string `xml:",chardata"`
+}
+
+func (t *Text) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	t.PixelSize = 16
+
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "wrap":
+			t.Wrap = attr.Value == "1"
+		case "bold":
+			t.Bold = attr.Value == "1"
+		case "italic":
+			t.Italic = attr.Value == "1"
+		case "underline":
+			t.Underline = attr.Value == "1"
+		case "halign":
+			val, err := enum.UnmarshalEnum[HAlign](attr.Value)
+			if err != nil {
+				return err
+			}
+			t.HAlign = val
+		case "valign":
+			val, err := enum.UnmarshalEnum[VAlign](attr.Value)
+			if err != nil {
+				return err
+			}
+			t.VAlign = val
+		}
+	}
+
+	type textAlias Text
+	aux := (*textAlias)(t)
+
+	return d.DecodeElement(aux, &start)
+}
+
+// ======================================================
+// LayerAttrs
+// ======================================================
+
+// LayerAttrs holds the display attributes every kind of layer has. Layers decoded from a map
+// default to DefaultLayerAttrs; layers built in code should start from it too.
+type LayerAttrs struct {
+	OffsetX   float32 `xml:"offsetx,attr,omitempty"`   // in pixels
+	OffsetY   float32 `xml:"offsety,attr,omitempty"`   // in pixels
+	Opacity   float32 `xml:"opacity,attr"`             // 0..1, defaults to 1
+	ParallaxX float32 `xml:"parallaxx,attr"`           // scroll factor relative to the camera, defaults to 1
+	ParallaxY float32 `xml:"parallaxy,attr"`           // scroll factor relative to the camera, defaults to 1
+	TintColor string  `xml:"tintcolor,attr,omitempty"` // #RRGGBB or #AARRGGBB, empty for none
+}
+
+// DefaultLayerAttrs returns the attributes of a layer that doesn't set any: fully opaque,
+// scrolling with the camera, without offset or tint.
+func DefaultLayerAttrs() LayerAttrs {
+	return LayerAttrs{Opacity: 1, ParallaxX: 1, ParallaxY: 1}
+}
+
+// Tint returns the tint color, or white if the layer has none.
+func (a *LayerAttrs) Tint() color.RGBA {
+	if c, ok := ParseColor(a.TintColor); ok {
+		return c
+	}
+	return color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+}
+
 // ======================================================
 // Layer
 // ======================================================
@@ -255,10 +872,13 @@ type Layer struct {
 
 	Data Data `xml:"data,omitempty"`
 
-	ID   int32  `xml:"id,attr"`
-	Name string `xml:"name,attr"`
+	ID    int32  `xml:"id,attr"`
+	Name  string `xml:"name,attr"`
+	Class string `xml:"class,attr,omitempty"`
 
-	Properties []Property `xml:"properties>property,omitempty"`
+	LayerAttrs
+
+	Properties Properties `xml:"properties>property,omitempty"`
 }
 
 func (l *Layer) IsLocked() bool {
@@ -271,6 +891,7 @@ func (l *Layer) IsVisible() bool {
 
 func (l *Layer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	l.Flags |= LayerFlagVisible
+	l.LayerAttrs = DefaultLayerAttrs()
 
 	for _, attr := range start.Attr {
 		switch attr.Name.Local {
@@ -293,6 +914,64 @@ func (l *Layer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return d.DecodeElement(aux, &start)
 }
 
+// ======================================================
+// ImageLayer
+// ======================================================
+
+// ImageLayer is a layer showing a single image, e.g. a background.
+type ImageLayer struct {
+	Flags LayerFlag `xml:"-"`
+
+	ID    int32  `xml:"id,attr"`
+	Name  string `xml:"name,attr"`
+	Class string `xml:"class,attr,omitempty"`
+
+	LayerAttrs
+
+	RepeatX bool `xml:"-"`
+	RepeatY bool `xml:"-"`
+
+	Image      Image      `xml:"image,omitempty"`
+	Properties Properties `xml:"properties>property,omitempty"`
+}
+
+func (il *ImageLayer) IsLocked() bool {
+	return il.Flags&LayerFlagLocked != 0
+}
+
+func (il *ImageLayer) IsVisible() bool {
+	return il.Flags&LayerFlagVisible != 0
+}
+
+func (il *ImageLayer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	il.Flags |= LayerFlagVisible
+	il.LayerAttrs = DefaultLayerAttrs()
+
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "visible":
+			if attr.Value == "0" {
+				il.Flags &^= LayerFlagVisible
+			}
+		case "locked":
+			if attr.Value != "" {
+				il.Flags |= LayerFlagLocked
+			} else {
+				il.Flags &^= LayerFlagLocked
+			}
+		case "repeatx":
+			il.RepeatX = attr.Value == "1"
+		case "repeaty":
+			il.RepeatY = attr.Value == "1"
+		}
+	}
+
+	type imagelayerAlias ImageLayer
+	aux := (*imagelayerAlias)(il)
+
+	return d.DecodeElement(aux, &start)
+}
+
 // ======================================================
 // Polygon
 // ======================================================
@@ -379,6 +1058,40 @@ type Offset struct {
 type Tileset struct {
 	FirstGID uint32 `xml:"firstgid,attr,omitempty"`
 	Source   string `xml:"source,attr,omitempty"`
+
+	Tsx *Tsx `xml:"-"` // Attached tileset data, if loaded or embedded
+}
+
+// IsEmbedded reports whether the tileset data is stored in the map instead of an external file.
+func (ts *Tileset) IsEmbedded() bool {
+	return ts.Source == "" && ts.Tsx != nil
+}
+
+func (ts *Tileset) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "firstgid":
+			val, err := strconv.ParseUint(attr.Value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid firstgid: %w", err)
+			}
+			ts.FirstGID = uint32(val)
+		case "source":
+			ts.Source = attr.Value
+		}
+	}
+
+	if ts.Source != "" {
+		return d.Skip()
+	}
+
+	// Embedded tilesets carry the same attributes and children as a .tsx file.
+	tsx := &Tsx{}
+	if err := d.DecodeElement(tsx, &start); err != nil {
+		return err
+	}
+	ts.Tsx = tsx
+	return nil
 }
 
 // ======================================================
@@ -395,15 +1108,54 @@ type Chunk struct {
 	Content string `xml:",chardata"`
 }
 
+func (c *Chunk) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type chunkAlias Chunk
+	aux := struct {
+		*chunkAlias
+		Tiles xmlTiles `xml:"tile"`
+	}{
+		chunkAlias: (*chunkAlias)(c),
+	}
+
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+
+	// Chunks of XML encoded layers store their tiles as <tile> elements.
+	if len(aux.Tiles) > 0 {
+		c.Content = aux.Tiles.csv()
+	}
+	return nil
+}
+
 // ======================================================
 // Property
 // ======================================================
 
 type Property struct {
-	Value        string `xml:"value,attr"`
-	PropertyType string `xml:"propertytype,attr,omitempty"`
+	Value        string       `xml:"value,attr"`
+	Type         PropertyKind `xml:"-"`
+	PropertyType string       `xml:"propertytype,attr,omitempty"` // name of the custom type, for class and enum properties
 
 	Name string `xml:"name,attr"`
 
-	Properties []Property `xml:"properties>property,omitempty"`
+	Properties Properties `xml:"properties>property,omitempty"`
+}
+
+func (p *Property) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type propertyAlias Property
+	if err := d.DecodeElement((*propertyAlias)(p), &start); err != nil {
+		return err
+	}
+
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "type" {
+			val, err := enum.UnmarshalEnum[PropertyKind](attr.Value)
+			if err != nil {
+				return fmt.Errorf("property %s: %w", p.Name, err)
+			}
+			p.Type = val
+		}
+	}
+	return nil
 }
@@ -0,0 +1,102 @@
+package pathfind
+
+import "github.com/adm87/tiled/tilemap"
+
+// ====================== Cache =====================
+
+// Cache keeps the cost graph of a map region and the flow fields computed over it.
+//
+// Tile mutations within the region drop the graph and every field, and rebuilding the map
+// (e.g. with SetTmx) does the same, so fields are always computed from current tiles.
+type Cache struct {
+	tm         *tilemap.Map
+	region     tilemap.Region
+	rules      tilemap.CostRules
+	generation uint64
+
+	graph       *tilemap.Graph
+	fields      map[[2]int32]*Field
+	unsubscribe func() // stops tile change notifications, see Close
+}
+
+// NewCache creates a flow field cache for a region (in tile coordinates) of a map. It watches
+// the map's tile changes until Close is called.
+func NewCache(tm *tilemap.Map, region tilemap.Region, rules tilemap.CostRules) *Cache {
+	c := &Cache{
+		tm:     tm,
+		region: region,
+		rules:  rules,
+		fields: make(map[[2]int32]*Field),
+	}
+	c.unsubscribe = tm.OnTileChanged(c.tileChanged)
+	return c
+}
+
+// Close stops watching the map's tile changes, releasing the cache from the map, and drops
+// the cached graph and fields. A closed cache no longer sees tile mutations, so it must not
+// be used afterwards.
+func (c *Cache) Close() {
+	c.unsubscribe()
+	c.Invalidate()
+}
+
+// Graph returns the cost graph of the cached region, building it if needed.
+func (c *Cache) Graph() (*tilemap.Graph, error) {
+	if c.generation != c.tm.Generation() {
+		c.Invalidate()
+	}
+
+	if c.graph == nil {
+		g, err := c.tm.Graph(c.region, c.rules)
+		if err != nil {
+			return nil, err
+		}
+		c.graph = g
+		c.generation = c.tm.Generation()
+	}
+	return c.graph, nil
+}
+
+// FlowField returns the flow field toward a target tile, computing it on first use.
+func (c *Cache) FlowField(targetX, targetY int32) (*Field, error) {
+	g, err := c.Graph()
+	if err != nil {
+		return nil, err
+	}
+
+	key := [2]int32{targetX, targetY}
+	if f, ok := c.fields[key]; ok {
+		return f, nil
+	}
+
+	f, err := FlowField(g, targetX, targetY)
+	if err != nil {
+		return nil, err
+	}
+	c.fields[key] = f
+	return f, nil
+}
+
+// Invalidate drops the cached graph and all fields.
+func (c *Cache) Invalidate() {
+	c.graph = nil
+	clear(c.fields)
+}
+
+func (c *Cache) tileChanged(layer int, x, y int32, _, _ uint32) {
+	r := c.region
+	if x < r.MinX || x >= r.MaxX || y < r.MinY || y >= r.MaxY {
+		return
+	}
+
+	if c.rules.Layers != nil {
+		checked := false
+		for _, l := range c.rules.Layers {
+			checked = checked || l == layer
+		}
+		if !checked {
+			return
+		}
+	}
+	c.Invalidate()
+}
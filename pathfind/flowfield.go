@@ -0,0 +1,151 @@
+package pathfind
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+
+	"github.com/adm87/tiled/tilemap"
+)
+
+var (
+	ErrNilGraph      = errors.New("graph is nil")
+	ErrTargetOutside = errors.New("target is outside of the graph")
+	ErrTargetBlocked = errors.New("target is impassable")
+)
+
+// ====================== Field =====================
+
+// Field is a flow field over the region of a graph: every reachable cell points toward the
+// neighbor on the cheapest path to the target, so any number of agents can steer by lookup.
+type Field struct {
+	Region           tilemap.Region
+	TargetX, TargetY int32
+
+	Dist []float32    // path cost to the target per cell, +Inf when unreachable
+	Dirs [][2]float32 // normalized direction per cell, zero at the target and unreachable cells
+}
+
+// FlowField computes the flow field toward a target tile over a cost graph.
+func FlowField(costs *tilemap.Graph, targetX, targetY int32) (*Field, error) {
+	if costs == nil {
+		return nil, ErrNilGraph
+	}
+
+	target := costs.Node(targetX, targetY)
+	if target == -1 {
+		return nil, ErrTargetOutside
+	}
+
+	if math.IsInf(float64(costs.Cost(target)), 1) {
+		return nil, ErrTargetBlocked
+	}
+
+	f := &Field{
+		Region:  costs.Region,
+		TargetX: targetX,
+		TargetY: targetY,
+		Dist:    make([]float32, len(costs.Costs)),
+		Dirs:    make([][2]float32, len(costs.Costs)),
+	}
+
+	inf := float32(math.Inf(1))
+	for i := range f.Dist {
+		f.Dist[i] = inf
+	}
+
+	// Dijkstra from the target outward. Edge weights are symmetric, so distances from
+	// the target equal distances to it.
+	f.Dist[target] = 0
+	open := &nodeQueue{{id: target}}
+	var edges []tilemap.Edge
+
+	for open.Len() > 0 {
+		n := heap.Pop(open).(node)
+		if n.dist > f.Dist[n.id] {
+			continue
+		}
+
+		edges = costs.Neighbors(n.id, edges[:0])
+		for _, e := range edges {
+			d := n.dist + float32(e.Weight)
+			if d < f.Dist[e.To] {
+				f.Dist[e.To] = d
+				heap.Push(open, node{id: e.To, dist: d})
+			}
+		}
+	}
+
+	// Point every reachable cell at its cheapest neighbor.
+	for id := range int64(len(f.Dist)) {
+		if id == target || math.IsInf(float64(f.Dist[id]), 1) {
+			continue
+		}
+
+		best, bestDist := int64(-1), f.Dist[id]
+		edges = costs.Neighbors(id, edges[:0])
+		for _, e := range edges {
+			if f.Dist[e.To] < bestDist {
+				best, bestDist = e.To, f.Dist[e.To]
+			}
+		}
+		if best == -1 {
+			continue
+		}
+
+		x, y := costs.Coord(id)
+		bx, by := costs.Coord(best)
+		dx, dy := float32(bx-x), float32(by-y)
+		l := float32(math.Hypot(float64(dx), float64(dy)))
+		f.Dirs[id] = [2]float32{dx / l, dy / l}
+	}
+
+	return f, nil
+}
+
+// Direction returns the direction to move in from a tile coordinate.
+// It returns false when the tile is outside the field or cannot reach the target.
+func (f *Field) Direction(x, y int32) (dx, dy float32, ok bool) {
+	i, ok := f.index(x, y)
+	if !ok || math.IsInf(float64(f.Dist[i]), 1) {
+		return 0, 0, false
+	}
+	return f.Dirs[i][0], f.Dirs[i][1], true
+}
+
+// Distance returns the path cost from a tile coordinate to the target, +Inf if unreachable.
+func (f *Field) Distance(x, y int32) float32 {
+	i, ok := f.index(x, y)
+	if !ok {
+		return float32(math.Inf(1))
+	}
+	return f.Dist[i]
+}
+
+func (f *Field) index(x, y int32) (int, bool) {
+	r := f.Region
+	if x < r.MinX || x >= r.MaxX || y < r.MinY || y >= r.MaxY {
+		return 0, false
+	}
+	return int((y-r.MinY)*(r.MaxX-r.MinX) + (x - r.MinX)), true
+}
+
+// ====================== Queue =====================
+
+type node struct {
+	id   int64
+	dist float32
+}
+
+type nodeQueue []node
+
+func (q nodeQueue) Len() int           { return len(q) }
+func (q nodeQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q nodeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x any)        { *q = append(*q, x.(node)) }
+func (q *nodeQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
@@ -0,0 +1,185 @@
+package tiled
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+var (
+	ErrPropertyKind  = errors.New("property has a different type")
+	ErrPropertyValue = errors.New("invalid property value")
+)
+
+// ======================================================
+// Property values
+// ======================================================
+
+// The accessors below parse a property value as the type they name. They return
+// ErrPropertyKind when the property's type attribute names another type, and wrap
+// ErrPropertyValue when the value cannot be parsed. Properties without a type attribute
+// are strings to Tiled, but hand-written maps often omit it, so their values are parsed
+// as requested.
+
+// Int returns the value of an int property.
+func (p *Property) Int() (int, error) {
+	if err := p.checkKind(PropertyKindInt); err != nil {
+		return 0, err
+	}
+	val, err := strconv.Atoi(p.Value)
+	if err != nil {
+		return 0, p.valueError(err)
+	}
+	return val, nil
+}
+
+// Float returns the value of a float property. Int properties are accepted as well.
+func (p *Property) Float() (float64, error) {
+	if err := p.checkKind(PropertyKindFloat, PropertyKindInt); err != nil {
+		return 0, err
+	}
+	val, err := strconv.ParseFloat(p.Value, 64)
+	if err != nil {
+		return 0, p.valueError(err)
+	}
+	return val, nil
+}
+
+// Bool returns the value of a bool property.
+func (p *Property) Bool() (bool, error) {
+	if err := p.checkKind(PropertyKindBool); err != nil {
+		return false, err
+	}
+	val, err := strconv.ParseBool(p.Value)
+	if err != nil {
+		return false, p.valueError(err)
+	}
+	return val, nil
+}
+
+// Color returns the value of a color property. Tiled writes an empty value for colors that
+// were never set, which is reported as invalid.
+func (p *Property) Color() (color.RGBA, error) {
+	if err := p.checkKind(PropertyKindColor); err != nil {
+		return color.RGBA{}, err
+	}
+	val, ok := ParseColor(p.Value)
+	if !ok {
+		return color.RGBA{}, p.valueError(nil)
+	}
+	return val, nil
+}
+
+// FileRef returns the path of a file property, relative to the file defining the property.
+func (p *Property) FileRef() (string, error) {
+	if err := p.checkKind(PropertyKindFile); err != nil {
+		return "", err
+	}
+	return p.Value, nil
+}
+
+// ObjectRef returns the ID of the object an object property refers to, 0 for none.
+func (p *Property) ObjectRef() (int32, error) {
+	if err := p.checkKind(PropertyKindObject); err != nil {
+		return 0, err
+	}
+	val, err := strconv.ParseInt(p.Value, 10, 32)
+	if err != nil {
+		return 0, p.valueError(err)
+	}
+	return int32(val), nil
+}
+
+func (p *Property) checkKind(kinds ...PropertyKind) error {
+	if p.Type == PropertyKindString {
+		return nil
+	}
+	for _, kind := range kinds {
+		if p.Type == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is %s, not %s", ErrPropertyKind, p.Name, p.Type, kinds[0])
+}
+
+func (p *Property) valueError(err error) error {
+	if err == nil {
+		return fmt.Errorf("%w %q for %s", ErrPropertyValue, p.Value, p.Name)
+	}
+	return fmt.Errorf("%w %q for %s: %w", ErrPropertyValue, p.Value, p.Name, err)
+}
+
+// ======================================================
+// Properties
+// ======================================================
+
+// Properties is a list of properties with lookups by name. The Get methods return the
+// default when the property is missing or its value doesn't parse as the requested type.
+type Properties []Property
+
+// Get returns the property with a name, or nil.
+func (ps Properties) Get(name string) *Property {
+	return PropertyByName(ps, name)
+}
+
+func (ps Properties) GetString(name, def string) string {
+	if p := ps.Get(name); p != nil {
+		return p.Value
+	}
+	return def
+}
+
+func (ps Properties) GetInt(name string, def int) int {
+	if p := ps.Get(name); p != nil {
+		if val, err := p.Int(); err == nil {
+			return val
+		}
+	}
+	return def
+}
+
+func (ps Properties) GetFloat(name string, def float64) float64 {
+	if p := ps.Get(name); p != nil {
+		if val, err := p.Float(); err == nil {
+			return val
+		}
+	}
+	return def
+}
+
+func (ps Properties) GetBool(name string, def bool) bool {
+	if p := ps.Get(name); p != nil {
+		if val, err := p.Bool(); err == nil {
+			return val
+		}
+	}
+	return def
+}
+
+func (ps Properties) GetColor(name string, def color.RGBA) color.RGBA {
+	if p := ps.Get(name); p != nil {
+		if val, err := p.Color(); err == nil {
+			return val
+		}
+	}
+	return def
+}
+
+func (ps Properties) GetFile(name, def string) string {
+	if p := ps.Get(name); p != nil {
+		if val, err := p.FileRef(); err == nil {
+			return val
+		}
+	}
+	return def
+}
+
+func (ps Properties) GetObject(name string, def int32) int32 {
+	if p := ps.Get(name); p != nil {
+		if val, err := p.ObjectRef(); err == nil {
+			return val
+		}
+	}
+	return def
+}
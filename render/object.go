@@ -0,0 +1,202 @@
+package render
+
+import (
+	"errors"
+	"math"
+
+	"github.com/adm87/tiled"
+)
+
+var (
+	ErrNilObject       = errors.New("object is nil")
+	ErrNoTmxData       = errors.New("no Tmx data set")
+	ErrTilesetNotFound = errors.New("tileset not found")
+	ErrTsxNotFound     = errors.New("tsx not found for tileset")
+)
+
+// ====================== Kind =====================
+
+// Kind identifies which fields of a Quad are meaningful to an adapter.
+type Kind uint8
+
+const (
+	KindTile Kind = iota
+	KindRectangle
+	KindEllipse
+	KindPoint
+	KindPolygon
+	KindPolyline
+	KindText
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindTile:
+		return "tile"
+	case KindRectangle:
+		return "rectangle"
+	case KindEllipse:
+		return "ellipse"
+	case KindPoint:
+		return "point"
+	case KindPolygon:
+		return "polygon"
+	case KindPolyline:
+		return "polyline"
+	case KindText:
+		return "text"
+	default:
+		return "unknown"
+	}
+}
+
+func (k Kind) IsValid() bool {
+	return k >= KindTile && k <= KindText
+}
+
+// ====================== Quad =====================
+
+// Quad is the resolved render data for a single object.
+//
+// X and Y are the object's position in world coordinates. For tile objects this is the
+// point the anchor is placed on, and Rotation (in degrees, clockwise) is applied around it.
+// Fields that do not apply to the object's Kind are left zeroed.
+type Quad struct {
+	Kind Kind
+
+	X, Y          float32
+	Width, Height float32
+	Rotation      float32
+
+	// Tile objects
+	TsIdx            int
+	TileID           uint32
+	SrcX, SrcY       int32
+	SrcW, SrcH       int32
+	FlipFlag         tiled.FlipFlag
+	AnchorX, AnchorY float32
+
+	// Polygon and polyline objects; borrowed from the object, relative to X, Y.
+	Points []float32
+
+	// Text objects; borrowed from the object.
+	Text *tiled.Text
+}
+
+// ObjectQuad resolves the render data for an object.
+//
+// The tilesets slice must be indexed the same way as tmx.Tilesets. It is only consulted for
+// objects with a GID, so adapters drawing shape-only object groups may pass nil. Tilesets
+// without an entry fall back to the Tsx attached to the map's tileset, e.g. embedded ones.
+func ObjectQuad(obj *tiled.Object, tmx *tiled.Tmx, tilesets []*tiled.Tsx) (Quad, error) {
+	if obj == nil {
+		return Quad{}, ErrNilObject
+	}
+
+	q := Quad{
+		X:        obj.X,
+		Y:        obj.Y,
+		Width:    obj.Width,
+		Height:   obj.Height,
+		Rotation: obj.Rotation,
+		TsIdx:    -1,
+	}
+
+	switch {
+	case obj.GID != 0:
+		return tileQuad(q, obj, tmx, tilesets)
+	case obj.Text != nil:
+		q.Kind = KindText
+		q.Text = obj.Text
+	case obj.IsPoint():
+		q.Kind = KindPoint
+	case obj.IsEllipse():
+		q.Kind = KindEllipse
+	case !obj.Polygon.IsEmpty():
+		q.Kind = KindPolygon
+		q.Points = obj.Polygon.Points
+	case !obj.Polyline.IsEmpty():
+		q.Kind = KindPolyline
+		q.Points = obj.Polyline.Points
+	default:
+		q.Kind = KindRectangle
+	}
+
+	return q, nil
+}
+
+func tileQuad(q Quad, obj *tiled.Object, tmx *tiled.Tmx, tilesets []*tiled.Tsx) (Quad, error) {
+	if tmx == nil {
+		return Quad{}, ErrNoTmxData
+	}
+
+	tileID, flipFlags := tiled.DecodeGID(obj.GID)
+
+	_, tileID, tsIdx := tiled.TilesetByGID(tmx, tileID)
+	if tsIdx == -1 {
+		return Quad{}, ErrTilesetNotFound
+	}
+
+	// Loaded tilesets take precedence over the data attached to the map.
+	var tsx *tiled.Tsx
+	if tsIdx < len(tilesets) {
+		tsx = tilesets[tsIdx]
+	}
+	if tsx == nil {
+		tsx = tmx.Tilesets[tsIdx].Tsx
+	}
+	if tsx == nil {
+		return Quad{}, ErrTsxNotFound
+	}
+
+	q.Kind = KindTile
+	q.TsIdx = tsIdx
+	q.TileID = tileID
+	q.FlipFlag = flipFlags
+
+	q.SrcX, q.SrcY, q.SrcW, q.SrcH = tsx.TileRect(tileID)
+
+	if q.Width == 0 {
+		q.Width = float32(tsx.TileWidth)
+	}
+	if q.Height == 0 {
+		q.Height = float32(tsx.TileHeight)
+	}
+
+	q.AnchorX, q.AnchorY = tiled.ObjectAlignmentAnchor(objectAlignment(tsx, tmx))
+	return q, nil
+}
+
+// Transform returns the world transform of a tile object's source image: the flips of its
+// FlipFlag, scaling to the object's size, the anchor offset and the rotation around X, Y.
+// It uses the matrix layout of tiled.FlipFlag.Apply.
+func (q *Quad) Transform() [6]float64 {
+	m := q.FlipFlag.Apply(float64(q.SrcW), float64(q.SrcH))
+
+	sx, sy := 1.0, 1.0
+	if q.SrcW > 0 {
+		sx = float64(q.Width) / float64(q.SrcW)
+	}
+	if q.SrcH > 0 {
+		sy = float64(q.Height) / float64(q.SrcH)
+	}
+
+	ax := -float64(q.AnchorX) * float64(q.Width)
+	ay := -float64(q.AnchorY) * float64(q.Height)
+	m = tiled.AffineMul([6]float64{sx, 0, 0, sy, ax, ay}, m)
+
+	sin, cos := math.Sincos(float64(q.Rotation) * math.Pi / 180)
+	return tiled.AffineMul([6]float64{cos, -sin, sin, cos, float64(q.X), float64(q.Y)}, m)
+}
+
+// objectAlignment resolves the unspecified alignment the same way Tiled does:
+// bottom-left for orthogonal maps and bottom-center for isometric maps.
+func objectAlignment(tsx *tiled.Tsx, tmx *tiled.Tmx) tiled.ObjectAlignment {
+	if tsx.ObjectAlignment != tiled.ObjectAlignmentUnspecified {
+		return tsx.ObjectAlignment
+	}
+	if tmx.Orientation == tiled.OrientationIsometric {
+		return tiled.ObjectAlignmentBottom
+	}
+	return tiled.ObjectAlignmentBottomLeft
+}
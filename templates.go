@@ -0,0 +1,358 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sync"
+)
+
+var (
+	ErrNoTemplateLoader = errors.New("template store has no load function")
+	ErrTemplateTileset  = errors.New("template tileset is not used by the map")
+)
+
+// TxLoadFunc loads the template stored at path.
+type TxLoadFunc func(path string) (*Tx, error)
+
+// TemplateChangeFunc is called after a template has been invalidated or replaced.
+type TemplateChangeFunc func(path string)
+
+// ======================================================
+// TemplateStore
+// ======================================================
+
+// TemplateStore caches .tx templates by path.
+//
+// Templates are loaded on first access. Invalidating a path drops the cached template and
+// bumps its version, so anything resolved from the old template can detect that it is stale
+// and re-resolve on its next access. This mirrors how the Tiled editor re-applies template
+// edits to every instance. A TemplateStore is safe for concurrent use, e.g. from a file
+// watcher goroutine.
+type TemplateStore struct {
+	mu        sync.RWMutex
+	load      TxLoadFunc
+	templates map[string]*Tx
+	versions  map[string]uint64
+	cleared   uint64 // bumped by InvalidateAll
+	listeners []templateListener
+	lastID    uint64 // ID of the last listener registered
+}
+
+// templateListener is a callback registered with OnChange.
+type templateListener struct {
+	id uint64
+	fn TemplateChangeFunc
+}
+
+func NewTemplateStore(load TxLoadFunc) *TemplateStore {
+	return &TemplateStore{
+		load:      load,
+		templates: make(map[string]*Tx),
+		versions:  make(map[string]uint64),
+	}
+}
+
+// NewTemplateStoreFS creates a template store reading .tx files from a file system.
+func NewTemplateStoreFS(fsys fs.FS) *TemplateStore {
+	return NewTemplateStore(func(name string) (*Tx, error) {
+		data, err := fs.ReadFile(fsys, path.Clean(name))
+		if err != nil {
+			return nil, err
+		}
+
+		var tx Tx
+		if err := xml.Unmarshal(data, &tx); err != nil {
+			return nil, err
+		}
+		return &tx, nil
+	})
+}
+
+// Get returns the template stored at path, loading it if it is not cached.
+//
+// Templates are loaded outside the lock. If the path is invalidated while it loads, the file
+// may have changed after it was read, so the loaded template is discarded and loaded again
+// rather than cached over the invalidation.
+func (s *TemplateStore) Get(path string) (*Tx, error) {
+	for {
+		s.mu.RLock()
+		tx, ok := s.templates[path]
+		version, cleared := s.versions[path], s.cleared
+		s.mu.RUnlock()
+		if ok {
+			return tx, nil
+		}
+
+		if s.load == nil {
+			return nil, ErrNoTemplateLoader
+		}
+
+		tx, err := s.load(path)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		if cached, ok := s.templates[path]; ok {
+			s.mu.Unlock()
+			return cached, nil
+		}
+		if s.versions[path] == version && s.cleared == cleared {
+			s.templates[path] = tx
+			s.mu.Unlock()
+			return tx, nil
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Version returns a counter that changes every time the template at path is invalidated or set.
+func (s *TemplateStore) Version(path string) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.versions[path]
+}
+
+// Set replaces the template stored at path and notifies listeners.
+func (s *TemplateStore) Set(path string, tx *Tx) {
+	s.mu.Lock()
+	s.templates[path] = tx
+	s.versions[path]++
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, l := range listeners {
+		l.fn(path)
+	}
+}
+
+// Invalidate drops the cached template at path, so it is reloaded on next access, and
+// notifies listeners. Call it when a template file changed on disk.
+func (s *TemplateStore) Invalidate(path string) {
+	s.mu.Lock()
+	delete(s.templates, path)
+	s.versions[path]++
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, l := range listeners {
+		l.fn(path)
+	}
+}
+
+// InvalidateAll drops every cached template and notifies listeners for each of them.
+func (s *TemplateStore) InvalidateAll() {
+	s.mu.Lock()
+	paths := make([]string, 0, len(s.templates))
+	for p := range s.templates {
+		paths = append(paths, p)
+		s.versions[p]++
+	}
+	clear(s.templates)
+	s.cleared++
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, p := range paths {
+		for _, l := range listeners {
+			l.fn(p)
+		}
+	}
+}
+
+// OnChange registers a callback invoked whenever a template is invalidated or replaced. It
+// returns a func unregistering the callback, so the store no longer keeps it alive. Calling
+// it more than once has no effect.
+func (s *TemplateStore) OnChange(fn TemplateChangeFunc) func() {
+	if fn == nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	s.lastID++
+	id := s.lastID
+	s.listeners = append(s.listeners, templateListener{id: id, fn: fn})
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		// A copy, since notifications iterate the slice outside of the lock.
+		s.listeners = slices.DeleteFunc(slices.Clone(s.listeners), func(l templateListener) bool {
+			return l.id == id
+		})
+		s.mu.Unlock()
+	}
+}
+
+// ResolveObject loads the template of an object instance from the store and applies it, as
+// ResolveObject does. base is the path of the map containing the object, which the template
+// path and tileset sources are resolved against. Objects without a template are returned as
+// they are.
+func (s *TemplateStore) ResolveObject(tmx *Tmx, base string, obj *Object) (Object, error) {
+	if obj.Template == "" {
+		return *obj, nil
+	}
+
+	name := ResolvePath(base, obj.Template)
+	tx, err := s.Get(name)
+	if err != nil {
+		return Object{}, err
+	}
+
+	tileset := ResolvePath(name, tx.Tileset.Source)
+	return resolveObject(obj, tx, tmx, func(ts *Tileset) bool {
+		if ts.Tsx != nil && ts.Tsx == tx.Tileset.Tsx {
+			return true
+		}
+		return ts.Source != "" && tx.Tileset.Source != "" && ResolvePath(base, ts.Source) == tileset
+	})
+}
+
+// ======================================================
+// Template resolution
+// ======================================================
+
+// objectAttr records which attributes an object sets itself. Template instances only store
+// the attributes they override, so zero values alone can't tell them apart from attributes
+// inherited from the template.
+type objectAttr uint8
+
+const (
+	objectAttrVisible objectAttr = 1 << iota
+	objectAttrName
+	objectAttrClass
+	objectAttrWidth
+	objectAttrHeight
+	objectAttrRotation
+	objectAttrGID
+	objectAttrShape // ellipse, point, polygon, polyline or text
+)
+
+// ResolveObject returns an object instance with its template applied. Attributes the instance
+// doesn't set are taken from the template object, properties are merged by name with the
+// instance's taking precedence, and the GID of a template tile object is remapped to the
+// tileset of the map sharing the template's Tsx. The position and ID are the instance's.
+//
+// It returns an error wrapping ErrTemplateTileset if the template is a tile object and the
+// map has no tileset sharing its Tsx.
+func ResolveObject(obj *Object, tx *Tx, tmx *Tmx) (Object, error) {
+	return resolveObject(obj, tx, tmx, func(ts *Tileset) bool {
+		return ts.Tsx != nil && ts.Tsx == tx.Tileset.Tsx
+	})
+}
+
+func resolveObject(obj *Object, tx *Tx, tmx *Tmx, sameTileset func(ts *Tileset) bool) (Object, error) {
+	res := tx.Objects
+	res.ID = obj.ID
+	res.X, res.Y = obj.X, obj.Y
+	res.Template = obj.Template
+	res.Flags = res.Flags&^ObjectFlagTemplate | obj.Flags&ObjectFlagTemplate
+	res.overrides = obj.overrides
+
+	if obj.sets(objectAttrVisible) {
+		res.Flags = res.Flags&^ObjectFlagVisible | obj.Flags&ObjectFlagVisible
+	}
+	if obj.sets(objectAttrName) || obj.Name != "" {
+		res.Name = obj.Name
+	}
+	if obj.sets(objectAttrClass) || obj.Class != "" {
+		res.Class = obj.Class
+	}
+	if obj.sets(objectAttrWidth) || obj.Width != 0 {
+		res.Width = obj.Width
+	}
+	if obj.sets(objectAttrHeight) || obj.Height != 0 {
+		res.Height = obj.Height
+	}
+	if obj.sets(objectAttrRotation) || obj.Rotation != 0 {
+		res.Rotation = obj.Rotation
+	}
+
+	if obj.sets(objectAttrShape) || obj.hasShape() {
+		const shapeFlags = ObjectFlagEllipse | ObjectFlagPoint
+		res.Flags = res.Flags&^shapeFlags | obj.Flags&shapeFlags
+		res.Polygon, res.Polyline, res.Text = obj.Polygon, obj.Polyline, obj.Text
+	}
+
+	// The result must not share memory with the template, which other instances apply too.
+	res.Polygon.Points = slices.Clone(res.Polygon.Points)
+	res.Polyline.Points = slices.Clone(res.Polyline.Points)
+	if res.Text != nil {
+		text := *res.Text
+		res.Text = &text
+	}
+	res.Properties = mergeProperties(tx.Objects.Properties, obj.Properties)
+
+	switch {
+	case obj.sets(objectAttrGID) || obj.GID != 0:
+		res.GID = obj.GID
+	case res.GID != 0:
+		gid, err := templateGID(res.GID, tx, tmx, sameTileset)
+		if err != nil {
+			return Object{}, err
+		}
+		res.GID = gid
+	}
+	return res, nil
+}
+
+func (o *Object) sets(attr objectAttr) bool {
+	return o.overrides&attr != 0
+}
+
+func (o *Object) hasShape() bool {
+	return o.Flags&(ObjectFlagEllipse|ObjectFlagPoint) != 0 ||
+		!o.Polygon.IsEmpty() || !o.Polyline.IsEmpty() || o.Text != nil
+}
+
+// templateGID converts the GID of a template tile object, relative to the template's own
+// tileset reference, to the GID of the same tile in the map.
+func templateGID(gid uint32, tx *Tx, tmx *Tmx, sameTileset func(ts *Tileset) bool) (uint32, error) {
+	tileID, flags := DecodeGID(gid)
+	local := tileID - tx.Tileset.FirstGID
+
+	for i := range tmx.Tilesets {
+		if sameTileset(&tmx.Tilesets[i]) {
+			return EncodeGID(tmx.Tilesets[i].FirstGID+local, flags), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrTemplateTileset, tx.Tileset.Source)
+}
+
+// mergeProperties returns the template properties with the instance's applied by name.
+// Members of class properties set on both are merged the same way.
+func mergeProperties(template, instance Properties) Properties {
+	if len(template) == 0 && len(instance) == 0 {
+		return nil
+	}
+
+	// Members are always rebuilt, so the result shares no memory with either list.
+	out := make(Properties, 0, len(template)+len(instance))
+	for i := range template {
+		prop := template[i]
+		var overrides Properties
+		if override := instance.Get(prop.Name); override != nil {
+			// A value replaces the template's members, members are merged with them.
+			members := prop.Properties
+			if len(override.Properties) == 0 {
+				members = nil
+			}
+			prop = *override
+			prop.Properties = members
+			overrides = override.Properties
+		}
+		prop.Properties = mergeProperties(prop.Properties, overrides)
+		out = append(out, prop)
+	}
+	for i := range instance {
+		if template.Get(instance[i].Name) == nil {
+			prop := instance[i]
+			prop.Properties = mergeProperties(nil, prop.Properties)
+			out = append(out, prop)
+		}
+	}
+	return out
+}
@@ -0,0 +1,20 @@
+package tiled
+
+import "testing"
+
+func TestTemplateStoreOnChangeUnsubscribe(t *testing.T) {
+	store := NewTemplateStore(func(string) (*Tx, error) { return &Tx{}, nil })
+
+	var a, b int
+	unsubscribe := store.OnChange(func(string) { a++ })
+	store.OnChange(func(string) { b++ })
+
+	store.Invalidate("a.tx")
+	unsubscribe()
+	unsubscribe()
+	store.Set("a.tx", &Tx{})
+
+	if a != 1 || b != 2 {
+		t.Fatalf("notifications = %d, %d, want 1, 2", a, b)
+	}
+}
@@ -0,0 +1,152 @@
+package tiledtest
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/tilemap"
+)
+
+// UpdateEnv is the environment variable that makes RenderGolden write goldens instead of
+// comparing against them, e.g. TILEDTEST_UPDATE=1 go test ./...
+const UpdateEnv = "TILEDTEST_UPDATE"
+
+// ====================== Tolerance =====================
+
+// Tolerance bounds how much a rendered image may differ from its golden.
+type Tolerance struct {
+	Channel uint8   // largest accepted difference of a color channel, 0..255
+	Pixels  float64 // fraction of pixels allowed to exceed Channel, 0..1
+}
+
+// Diff describes how two images differ.
+type Diff struct {
+	Pixels     int   // pixels with a channel difference above the tolerance
+	Total      int   // pixels compared
+	MaxChannel uint8 // largest channel difference found
+}
+
+// Compare compares two images pixel by pixel. Images of different sizes never match.
+func Compare(got, want image.Image, tol Tolerance) (Diff, bool) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return Diff{}, false
+	}
+
+	var d Diff
+	d.Total = gb.Dx() * gb.Dy()
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			r1, g1, b1, a1 := got.At(gb.Min.X+x, gb.Min.Y+y).RGBA()
+			r2, g2, b2, a2 := want.At(wb.Min.X+x, wb.Min.Y+y).RGBA()
+
+			delta := max(channelDiff(r1, r2), channelDiff(g1, g2), channelDiff(b1, b2), channelDiff(a1, a2))
+			d.MaxChannel = max(d.MaxChannel, delta)
+			if delta > tol.Channel {
+				d.Pixels++
+			}
+		}
+	}
+	return d, float64(d.Pixels) <= tol.Pixels*float64(d.Total)
+}
+
+func channelDiff(a, b uint32) uint8 {
+	a, b = a>>8, b>>8
+	if a > b {
+		return uint8(a - b)
+	}
+	return uint8(b - a)
+}
+
+// ====================== RenderGolden =====================
+
+// RenderGolden renders a fixture map from fsys with Render and compares it against the golden
+// PNG at the golden path, failing tb when they differ by more than the tolerance. The whole
+// map is rendered, including the content of infinite maps.
+//
+// With UpdateEnv set, the golden is written instead. On a mismatch, the rendered image is
+// written next to the golden with a .got.png suffix so the two can be inspected.
+func RenderGolden(tb testing.TB, fsys fs.FS, mapPath, golden string, tol Tolerance) {
+	tb.Helper()
+
+	got, err := RenderMap(fsys, mapPath)
+	if err != nil {
+		tb.Fatalf("render %s: %v", mapPath, err)
+	}
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := writePNG(golden, got); err != nil {
+			tb.Fatalf("update golden: %v", err)
+		}
+		return
+	}
+
+	f, err := os.Open(golden)
+	if err != nil {
+		tb.Fatalf("open golden (run with %s=1 to create it): %v", UpdateEnv, err)
+	}
+	want, err := png.Decode(f)
+	f.Close()
+	if err != nil {
+		tb.Fatalf("decode golden %s: %v", golden, err)
+	}
+
+	diff, ok := Compare(got, want, tol)
+	if ok {
+		return
+	}
+
+	gotPath := strings.TrimSuffix(golden, filepath.Ext(golden)) + ".got.png"
+	if err := writePNG(gotPath, got); err != nil {
+		tb.Logf("write %s: %v", gotPath, err)
+	}
+
+	if diff.Total == 0 {
+		tb.Fatalf("%s: rendered %v, golden %s is %v", mapPath, got.Bounds().Size(), golden, want.Bounds().Size())
+	}
+	tb.Fatalf("%s: %d of %d pixels differ from %s (max channel difference %d), see %s",
+		mapPath, diff.Pixels, diff.Total, golden, diff.MaxChannel, gotPath)
+}
+
+// RenderMap loads a map and its tilesets from fsys and renders all of its content with
+// tilemap.RenderToImage, which extends the image so tiles taller or wider than the map's
+// cells aren't clipped.
+func RenderMap(fsys fs.FS, mapPath string) (*image.RGBA, error) {
+	tmx, err := tiled.NewLoaderFS(fsys).LoadTmx(mapPath)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := LoadTilesetImages(fsys, mapPath, tmx)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := tilemap.RenderToImage(tmx, tilemap.TilesetImages(images))
+	if err != nil {
+		return nil, err
+	}
+	if img.Bounds().Empty() {
+		return nil, fmt.Errorf("%s has no tiles", mapPath)
+	}
+	return img, nil
+}
+
+func writePNG(name string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,16 @@
+package tiledtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderGolden(t *testing.T) {
+	fsys := os.DirFS("testdata")
+	for _, name := range []string{"orthogonal", "flipped", "offset", "isometric"} {
+		t.Run(name, func(t *testing.T) {
+			RenderGolden(t, fsys, name+".tmx", filepath.Join("testdata", "golden", name+".png"), Tolerance{})
+		})
+	}
+}
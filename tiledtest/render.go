@@ -0,0 +1,69 @@
+// Package tiledtest provides helpers for testing code built on tiled maps, such as a software
+// renderer and golden image comparisons, usable from go test in this module and downstream.
+package tiledtest
+
+import (
+	"fmt"
+	"image"
+	_ "image/png"
+	"io/fs"
+
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/tilemap"
+)
+
+// ====================== Images =====================
+
+// LoadTilesetImages decodes the image of every tileset of a map loaded from mapPath in fsys.
+// Image sources are resolved relative to the file of their tileset. The result is indexed
+// the same way as tmx.Tilesets; tilesets without an attached Tsx or image get a nil entry.
+func LoadTilesetImages(fsys fs.FS, mapPath string, tmx *tiled.Tmx) ([]image.Image, error) {
+	images := make([]image.Image, len(tmx.Tilesets))
+	for i := range tmx.Tilesets {
+		ts := &tmx.Tilesets[i]
+		if ts.Tsx == nil || ts.Tsx.Image.Source == "" {
+			continue
+		}
+
+		base := mapPath
+		if ts.Source != "" {
+			base = tiled.ResolvePath(mapPath, ts.Source)
+		}
+
+		img, err := decodeImage(fsys, tiled.ResolvePath(base, ts.Tsx.Image.Source))
+		if err != nil {
+			return nil, err
+		}
+		images[i] = img
+	}
+	return images, nil
+}
+
+func decodeImage(fsys fs.FS, name string) (image.Image, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+	return img, nil
+}
+
+// ====================== Render =====================
+
+// Render draws the buffered frame of a map into a new image covering the frame, using the
+// tileset images indexed the same way as the map's tilesets. It draws through
+// tilemap.Map.DrawFrame, which applies flips, tile offsets, layer opacity and visibility the
+// way tilemap.Map.TileTransform describes them, so it serves as a reference for other renderers.
+//
+// The map must use its default coordinate system, and BufferFrame must have been called.
+func Render(m *tilemap.Map, images []image.Image) *image.RGBA {
+	rect := m.Frame().Rectangle()
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	m.DrawFrame(dst, tilemap.TilesetImages(images))
+	return dst
+}
@@ -0,0 +1,5 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="tiles-offset" tilewidth="8" tileheight="8" tilecount="4" columns="2">
+ <tileoffset x="2" y="-3"/>
+ <image source="tiles.png" width="16" height="16"/>
+</tileset>
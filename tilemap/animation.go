@@ -0,0 +1,33 @@
+package tilemap
+
+import (
+	"time"
+
+	"github.com/adm87/tiled"
+)
+
+// AnimatedTileID returns the local tile ID to draw for a buffered tile after the elapsed time,
+// following the animation its tileset defines for it. Tiles without an animation, or whose
+// tileset has no Tsx attached, are returned as is.
+//
+// Animators are built once per Tsx, so swapping a tileset with SwapTsx picks up its animations.
+func (tm *Map) AnimatedTileID(tile *Data, elapsed time.Duration) uint32 {
+	if tm.Tmx == nil || tile.TsIdx < 0 || tile.TsIdx >= len(tm.Tmx.Tilesets) {
+		return tile.TileID
+	}
+
+	tsx := tm.Tmx.Tilesets[tile.TsIdx].Tsx
+	if tsx == nil || len(tsx.Tiles) == 0 {
+		return tile.TileID
+	}
+
+	animator, ok := tm.animators[tsx]
+	if !ok {
+		if tm.animators == nil {
+			tm.animators = make(map[*tiled.Tsx]*tiled.Animator)
+		}
+		animator = tiled.NewAnimator(tsx)
+		tm.animators[tsx] = animator
+	}
+	return animator.TileID(tile.TileID, elapsed)
+}
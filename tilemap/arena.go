@@ -0,0 +1,70 @@
+package tilemap
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// dataArena recycles tile caches between maps and between SetTmx swaps.
+var dataArena slab[Data]
+
+const slabClasses = 32
+
+// ====================== Slab =====================
+
+// slab hands out slices with power of two capacities and takes them back for reuse.
+//
+// Rounding capacities up means a cache growing a little at a time, or alternating between
+// differently sized maps, settles on a single buffer instead of reallocating repeatedly.
+type slab[T any] struct {
+	classes [slabClasses]sync.Pool
+}
+
+// get returns an empty slice with a capacity of at least n.
+func (s *slab[T]) get(n int) []T {
+	class := sizeClass(n)
+	if class >= slabClasses {
+		return make([]T, 0, n)
+	}
+
+	if buf, ok := s.classes[class].Get().(*[]T); ok {
+		return (*buf)[:0]
+	}
+	return make([]T, 0, 1<<class)
+}
+
+// put returns a slice obtained from get so its memory can be reused.
+func (s *slab[T]) put(buf []T) {
+	c := cap(buf)
+	if c == 0 || c&(c-1) != 0 {
+		return
+	}
+
+	class := sizeClass(c)
+	if class >= slabClasses {
+		return
+	}
+
+	clear(buf[:c])
+	buf = buf[:0]
+	s.classes[class].Put(&buf)
+}
+
+func sizeClass(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// Release returns the map's tile cache to the shared arena. Call it when discarding a map
+// so other maps can reuse the memory. The map remains usable afterwards.
+func (tm *Map) Release() {
+	dataArena.put(tm.cachedData)
+	tm.cachedData = nil
+	tm.cachedPositions = nil
+	tm.cacheGen++
+	dataArena.put(tm.staging.data)
+	tm.staging = staging{}
+	tm.dirty = true
+}
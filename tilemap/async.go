@@ -0,0 +1,184 @@
+package tilemap
+
+import (
+	"errors"
+
+	"github.com/adm87/tiled"
+)
+
+var ErrAsyncDecodeDisabled = errors.New("async decoding is disabled")
+
+// asyncQueueSize is the number of chunk decodes that can be queued per worker.
+const asyncQueueSize = 16
+
+// SetAsyncDecode moves the decoding of chunks first touched by BufferFrame to a pool of
+// worker goroutines.
+//
+// With async decoding enabled, BufferFrame skips chunks that are not decoded yet instead of
+// blocking on them, and picks them up on the first call after their decode finished. This
+// smooths hitches when panning into new parts of an infinite map, at the cost of those chunks
+// appearing a frame or two late. Other map operations still decode chunks inline.
+//
+// Passing 0 stops the workers and restores inline decoding.
+func (tm *Map) SetAsyncDecode(workers int) {
+	if tm.decoder != nil {
+		tm.decoder.stop()
+		tm.decoder = nil
+	}
+	if workers > 0 {
+		tm.decoder = newAsyncDecoder(workers)
+	}
+}
+
+// PendingDecodes returns the number of chunks queued or being decoded by the async decoder.
+func (tm *Map) PendingDecodes() int {
+	if tm.decoder == nil {
+		return 0
+	}
+	return len(tm.decoder.pending)
+}
+
+// Prefetch queues the chunks intersecting the given regions (in tile coordinates) that are
+// not decoded yet on the async decoder's workers, e.g. the chunks ahead of a moving camera, so
+// they are usually decoded by the time BufferFrame needs them and the goroutine buffering the
+// map never decompresses them itself. Decoded chunks are attached by the next BufferFrame.
+//
+// The queue is bounded: chunks that don't fit are skipped, and can be queued by calling
+// Prefetch again on a later frame. It returns the number of chunks queued or already pending,
+// and ErrAsyncDecodeDisabled unless SetAsyncDecode started the workers.
+func (tm *Map) Prefetch(regions ...Region) (int, error) {
+	if tm.Tmx == nil {
+		return 0, ErrNoTmxData
+	}
+	if tm.decoder == nil {
+		return 0, ErrAsyncDecodeDisabled
+	}
+
+	// Attach the finished decodes first, to make room in the queue.
+	tm.collectDecodes()
+
+	n := 0
+	for _, region := range regions {
+		for i := range tm.layers {
+			for _, chunk := range tm.queryChunks(i, region) {
+				if chunk.isDecoded {
+					continue
+				}
+				tm.decoder.request(chunk, tm.generation)
+				if _, ok := tm.decoder.pending[chunk]; ok {
+					n++
+				}
+			}
+		}
+	}
+	return n, nil
+}
+
+// collectDecodes attaches the chunks decoded since the last call, and marks the cache dirty
+// if any were attached.
+func (tm *Map) collectDecodes() {
+	if tm.decoder == nil {
+		return
+	}
+
+	for {
+		select {
+		case res := <-tm.decoder.results:
+			if res.generation != tm.generation {
+				// The chunk was recycled by SetTmx or Flush.
+				continue
+			}
+			delete(tm.decoder.pending, res.chunk)
+			if res.err != nil {
+				// Leave the chunk undecoded; the inline decode reports the error.
+				continue
+			}
+			res.chunk.attach(res.data)
+			tm.invalidateChunk(res.chunk)
+		default:
+			return
+		}
+	}
+}
+
+// ====================== asyncDecoder =====================
+
+type decodeRequest struct {
+	chunk       *Chunk
+	generation  uint64
+	raw         string
+	encoding    tiled.Encoding
+	compression tiled.Compression
+}
+
+type decodeResult struct {
+	chunk      *Chunk
+	generation uint64
+	data       []uint32
+	err        error
+}
+
+type asyncDecoder struct {
+	requests chan decodeRequest
+	results  chan decodeResult
+	pending  map[*Chunk]struct{}
+}
+
+func newAsyncDecoder(workers int) *asyncDecoder {
+	d := &asyncDecoder{
+		requests: make(chan decodeRequest, workers*asyncQueueSize),
+		results:  make(chan decodeResult, workers*asyncQueueSize),
+		pending:  make(map[*Chunk]struct{}),
+	}
+	for range workers {
+		go d.work()
+	}
+	return d
+}
+
+func (d *asyncDecoder) work() {
+	for req := range d.requests {
+		data, err := tiled.DecodeContent(req.raw, req.encoding, req.compression)
+		d.results <- decodeResult{
+			chunk:      req.chunk,
+			generation: req.generation,
+			data:       data,
+			err:        err,
+		}
+	}
+}
+
+// request queues a chunk for decoding unless it is already queued. If the queue is full, the
+// chunk is requested again on a later frame.
+func (d *asyncDecoder) request(chunk *Chunk, generation uint64) {
+	if _, ok := d.pending[chunk]; ok {
+		return
+	}
+
+	// Results must be drained before queueing more work, or workers could block.
+	if len(d.pending) >= cap(d.results) {
+		return
+	}
+
+	select {
+	case d.requests <- decodeRequest{
+		chunk:       chunk,
+		generation:  generation,
+		raw:         chunk.raw,
+		encoding:    chunk.encoding,
+		compression: chunk.compression,
+	}:
+		d.pending[chunk] = struct{}{}
+	default:
+	}
+}
+
+// reset forgets pending chunks after the map's chunks were recycled.
+// Their results are discarded by generation when they arrive.
+func (d *asyncDecoder) reset() {
+	clear(d.pending)
+}
+
+func (d *asyncDecoder) stop() {
+	close(d.requests)
+}
@@ -0,0 +1,202 @@
+package tilemap
+
+import (
+	"math"
+
+	"github.com/adm87/tiled"
+)
+
+const (
+	DefaultAudioClass = "audio" // object class turned into audio zones
+
+	AudioClipProperty    = "clip"    // name of the clip to play
+	AudioVolumeProperty  = "volume"  // volume inside the zone, defaults to 1
+	AudioFalloffProperty = "falloff" // distance outside the zone over which the volume fades out
+)
+
+// ====================== AudioZone =====================
+
+// AudioZone is an ambient audio area authored as an object in Tiled.
+type AudioZone struct {
+	Object  *tiled.Object
+	Clip    string
+	Volume  float32
+	Falloff float32
+
+	bounds [4]float32 // shape bounds including falloff
+}
+
+// Gain returns the volume of the zone heard at a world position.
+// It is Volume inside the shape and fades linearly to 0 over Falloff outside of it.
+func (z *AudioZone) Gain(x, y float32) float32 {
+	if x < z.bounds[0] || x > z.bounds[2] || y < z.bounds[1] || y > z.bounds[3] {
+		return 0
+	}
+
+	dist := objectDistance(z.Object, x, y)
+	if dist <= 0 {
+		return z.Volume
+	}
+
+	if z.Falloff <= 0 || dist >= z.Falloff {
+		return 0
+	}
+	return z.Volume * (1 - dist/z.Falloff)
+}
+
+// ====================== AudioZones =====================
+
+// AudioZones holds the audio zones of a map and answers listener queries.
+type AudioZones struct {
+	zones  []AudioZone
+	result []AudioZone
+}
+
+// NewAudioZones collects every visible object of the given class from the visible object
+// groups of a map. Clip, volume and falloff are read from the object's properties.
+func NewAudioZones(tmx *tiled.Tmx, class string) *AudioZones {
+	az := &AudioZones{}
+	if tmx == nil {
+		return az
+	}
+
+	for _, obj := range tiled.ObjectsByClass(tmx, class, 0) {
+		az.zones = append(az.zones, newAudioZone(obj))
+	}
+	return az
+}
+
+func newAudioZone(obj *tiled.Object) AudioZone {
+	z := AudioZone{
+		Object:  obj,
+		Clip:    obj.Properties.GetString(AudioClipProperty, ""),
+		Volume:  float32(obj.Properties.GetFloat(AudioVolumeProperty, 1)),
+		Falloff: float32(obj.Properties.GetFloat(AudioFalloffProperty, 0)),
+	}
+
+	b := objectBounds(obj)
+	z.bounds = [4]float32{b[0] - z.Falloff, b[1] - z.Falloff, b[2] + z.Falloff, b[3] + z.Falloff}
+	return z
+}
+
+// All returns every audio zone.
+func (az *AudioZones) All() []AudioZone {
+	return az.zones
+}
+
+// At returns the zones audible at a listener position.
+// The returned slice is reused between calls.
+func (az *AudioZones) At(x, y float32) []AudioZone {
+	az.result = az.result[:0]
+	for i := range az.zones {
+		if az.zones[i].Gain(x, y) > 0 {
+			az.result = append(az.result, az.zones[i])
+		}
+	}
+	return az.result
+}
+
+// ====================== Geometry =====================
+
+// objectBounds returns the world space bounding box of an object's shape, ignoring rotation.
+func objectBounds(obj *tiled.Object) [4]float32 {
+	points := obj.Polygon.Points
+	if len(points) == 0 {
+		points = obj.Polyline.Points
+	}
+
+	if len(points) == 0 {
+		return [4]float32{obj.X, obj.Y, obj.X + obj.Width, obj.Y + obj.Height}
+	}
+
+	b := [4]float32{math.MaxFloat32, math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for i := 0; i+1 < len(points); i += 2 {
+		b[0] = min(b[0], obj.X+points[i])
+		b[1] = min(b[1], obj.Y+points[i+1])
+		b[2] = max(b[2], obj.X+points[i])
+		b[3] = max(b[3], obj.Y+points[i+1])
+	}
+	return b
+}
+
+// objectDistance returns the distance from a world position to an object's shape,
+// or 0 when the position is inside it. Rotation is ignored.
+func objectDistance(obj *tiled.Object, x, y float32) float32 {
+	switch {
+	case obj.IsPoint():
+		return hypot(x-obj.X, y-obj.Y)
+
+	case obj.IsEllipse():
+		rx, ry := obj.Width/2, obj.Height/2
+		if rx <= 0 || ry <= 0 {
+			return hypot(x-obj.X, y-obj.Y)
+		}
+		dx, dy := (x-obj.X-rx)/rx, (y-obj.Y-ry)/ry
+		n := hypot(dx, dy)
+		if n <= 1 {
+			return 0
+		}
+		return (n - 1) * min(rx, ry)
+
+	case !obj.Polygon.IsEmpty():
+		if pointInPolygon(obj.Polygon.Points, x-obj.X, y-obj.Y) {
+			return 0
+		}
+		return polylineDistance(obj.Polygon.Points, x-obj.X, y-obj.Y, true)
+
+	case !obj.Polyline.IsEmpty():
+		return polylineDistance(obj.Polyline.Points, x-obj.X, y-obj.Y, false)
+	}
+
+	dx := max(obj.X-x, 0, x-(obj.X+obj.Width))
+	dy := max(obj.Y-y, 0, y-(obj.Y+obj.Height))
+	return hypot(dx, dy)
+}
+
+func pointInPolygon(points []float32, x, y float32) bool {
+	inside := false
+	n := len(points) / 2
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		xi, yi := points[i*2], points[i*2+1]
+		xj, yj := points[j*2], points[j*2+1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func polylineDistance(points []float32, x, y float32, closed bool) float32 {
+	n := len(points) / 2
+	if n == 0 {
+		return math.MaxFloat32
+	}
+	if n == 1 {
+		return hypot(x-points[0], y-points[1])
+	}
+
+	best := float32(math.MaxFloat32)
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	for i := 0; i < segments; i++ {
+		j := (i + 1) % n
+		best = min(best, segmentDistance(points[i*2], points[i*2+1], points[j*2], points[j*2+1], x, y))
+	}
+	return best
+}
+
+func segmentDistance(ax, ay, bx, by, x, y float32) float32 {
+	dx, dy := bx-ax, by-ay
+	lenSq := dx*dx + dy*dy
+	if lenSq == 0 {
+		return hypot(x-ax, y-ay)
+	}
+	t := max(0, min(1, ((x-ax)*dx+(y-ay)*dy)/lenSq))
+	return hypot(x-(ax+t*dx), y-(ay+t*dy))
+}
+
+func hypot(x, y float32) float32 {
+	return float32(math.Hypot(float64(x), float64(y)))
+}
@@ -0,0 +1,136 @@
+package tilemap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adm87/tiled"
+)
+
+const benchSize = 256
+
+// benchTmx builds a 256x256 map of 16px tiles with three layers: ground covering every cell,
+// sparse decoration, and a layer of flipped tiles on every other row.
+func benchTmx(b *testing.B) *tiled.Tmx {
+	b.Helper()
+
+	layer := func(id int, gid func(x, y int) uint32) string {
+		var sb strings.Builder
+		for y := range benchSize {
+			for x := range benchSize {
+				if x > 0 || y > 0 {
+					sb.WriteByte(',')
+				}
+				fmt.Fprint(&sb, gid(x, y))
+			}
+		}
+		return fmt.Sprintf(` <layer id="%[1]d" name="Layer%[1]d" width="%[2]d" height="%[2]d">
+  <data encoding="csv">%[3]s</data>
+ </layer>
+`, id, benchSize, sb.String())
+	}
+
+	src := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="%[1]d" height="%[1]d" tilewidth="16" tileheight="16" infinite="0">
+ <tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16" tilecount="16" columns="4">
+  <image source="tiles.png" width="64" height="64"/>
+ </tileset>
+`, benchSize) +
+		layer(1, func(x, y int) uint32 { return uint32(1 + (x+y)%4) }) +
+		layer(2, func(x, y int) uint32 {
+			if (x*7+y*13)%5 == 0 {
+				return uint32(5 + x%4)
+			}
+			return 0
+		}) +
+		layer(3, func(x, y int) uint32 {
+			if y%2 == 0 {
+				return uint32(9+x%8) | tiled.FlipHorizontalFlag
+			}
+			return 0
+		}) +
+		`</map>`
+
+	loader := tiled.NewLoaderFS(fstest.MapFS{"map.tmx": {Data: []byte(src)}})
+	tmx, err := loader.LoadTmx("map.tmx")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return tmx
+}
+
+// BenchmarkBufferFrame buffers the whole of a freshly decoded 256x256 map, resolving every
+// tile through getTileFromChunk, cold and after Warm.
+func BenchmarkBufferFrame(b *testing.B) {
+	tmx := benchTmx(b)
+	for _, warm := range []bool{false, true} {
+		b.Run(benchName(warm), func(b *testing.B) {
+			for b.Loop() {
+				b.StopTimer()
+				tm := NewMap()
+				if err := tm.SetTmx(tmx); err != nil {
+					b.Fatal(err)
+				}
+				if err := tm.DecodeAllChunks(); err != nil {
+					b.Fatal(err)
+				}
+				if warm {
+					if err := tm.Warm(Region{MaxX: benchSize, MaxY: benchSize}); err != nil {
+						b.Fatal(err)
+					}
+				}
+				tm.Frame().Set([4]float32{0, 0, benchSize * 16, benchSize * 16})
+				b.StartTimer()
+
+				if err := tm.BufferFrame(); err != nil {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				tm.Release()
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+// BenchmarkTileAt sweeps every tile of a decoded 256x256 map, cold and warmed.
+func BenchmarkTileAt(b *testing.B) {
+	tmx := benchTmx(b)
+	for _, warm := range []bool{false, true} {
+		b.Run(benchName(warm), func(b *testing.B) {
+			tm := NewMap()
+			if err := tm.SetTmx(tmx); err != nil {
+				b.Fatal(err)
+			}
+			defer tm.Release()
+			if err := tm.DecodeAllChunks(); err != nil {
+				b.Fatal(err)
+			}
+			if warm {
+				if err := tm.Warm(Region{MaxX: benchSize, MaxY: benchSize}); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			for b.Loop() {
+				for layer := range tmx.Layers {
+					for y := range int32(benchSize) {
+						for x := range int32(benchSize) {
+							tm.TileAt(layer, x, y)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func benchName(warm bool) string {
+	if warm {
+		return "warm"
+	}
+	return "cold"
+}
@@ -0,0 +1,99 @@
+package tilemap
+
+// ====================== Content Bounds =====================
+
+// LayerContentBounds returns the tight bounds (in tile coordinates) of the non-empty cells of
+// a layer, and false if the layer has no content.
+//
+// Chunk bounds are computed when chunks decode; chunks not decoded yet are decoded by this call.
+// Use it for cameras, minimaps and cropping, since the declared map size often includes large
+// empty margins.
+func (tm *Map) LayerContentBounds(index int) (Region, bool, error) {
+	if tm.Tmx == nil {
+		return Region{}, false, ErrNoTmxData
+	}
+
+	if index < 0 || index >= len(tm.layers) {
+		return Region{}, false, ErrLayerNotFound
+	}
+
+	var (
+		bounds Region
+		found  bool
+		err    error
+	)
+
+	tm.layers[index].ForEach(func(chunk *Chunk) {
+		if err != nil {
+			return
+		}
+		if err = chunk.decode(); err != nil {
+			return
+		}
+		if chunk.stale {
+			chunk.updateContent()
+		}
+		bounds, found = unionRegion(bounds, found, chunk.content)
+	})
+	if err != nil {
+		return Region{}, false, err
+	}
+	return bounds, found, nil
+}
+
+// ContentBounds returns the union of the content bounds of every layer.
+func (tm *Map) ContentBounds() (Region, bool, error) {
+	if tm.Tmx == nil {
+		return Region{}, false, ErrNoTmxData
+	}
+
+	var (
+		bounds Region
+		found  bool
+	)
+
+	for i := range tm.layers {
+		layer, ok, err := tm.LayerContentBounds(i)
+		if err != nil {
+			return Region{}, false, err
+		}
+		if ok {
+			bounds, found = unionRegion(bounds, found, layer)
+		}
+	}
+	return bounds, found, nil
+}
+
+// updateContent recomputes the bounds of the chunk's non-empty cells.
+// An empty chunk gets an empty region.
+func (c *Chunk) updateContent() {
+	c.stale = false
+	c.content = Region{}
+
+	found := false
+	for i := range c.len() {
+		if c.at(i) == 0 {
+			continue
+		}
+
+		x, y := c.x+i%c.w, c.y+i/c.w
+		c.content, found = unionRegion(c.content, found, Region{MinX: x, MinY: y, MaxX: x + 1, MaxY: y + 1})
+	}
+}
+
+// unionRegion extends a region by another, ignoring empty regions.
+// ok reports whether a holds any cells yet.
+func unionRegion(a Region, ok bool, b Region) (Region, bool) {
+	if b.IsEmpty() {
+		return a, ok
+	}
+	if !ok {
+		return b, true
+	}
+	return Region{
+		MinX: min(a.MinX, b.MinX),
+		MinY: min(a.MinY, b.MinY),
+		MaxX: max(a.MaxX, b.MaxX),
+		MaxY: max(a.MaxY, b.MaxY),
+	}, true
+}
@@ -0,0 +1,190 @@
+package tilemap
+
+import (
+	"fmt"
+	"time"
+)
+
+// ====================== BufferBudget =====================
+
+// BufferBudget limits how much work a single BufferFrame call may do.
+//
+// A zero field means no limit for that measure. When both fields are zero, BufferFrame
+// rebuilds the whole cache in one call.
+type BufferBudget struct {
+	MaxTiles int           // maximum tiles converted per call; a chunk decode counts as its tile count
+	MaxTime  time.Duration // maximum time spent per call, checked between rows of chunks
+}
+
+func (b BufferBudget) enabled() bool {
+	return b.MaxTiles > 0 || b.MaxTime > 0
+}
+
+// SetBufferBudget limits the work BufferFrame may do per call.
+//
+// With a budget set, a rebuild that exceeds it is carried over to the following calls while
+// the iterator keeps returning the previous complete frame, so a large camera jump spreads
+// over a few frames instead of spiking one. Chunks decoded by an interrupted rebuild stay
+// decoded, so restarting after the frame moves again is cheaper than the first attempt.
+func (tm *Map) SetBufferBudget(budget BufferBudget) {
+	tm.budget = budget
+	tm.staging.active = false
+}
+
+// Buffering reports whether a budgeted rebuild is still in progress.
+func (tm *Map) Buffering() bool {
+	return tm.staging.active
+}
+
+// ====================== Region cap =====================
+
+// SetMaxRegionTiles caps the number of cells a buffered frame may cover, counted across every
+// layer as the cache is sized. Frames are already clamped to the map's extent, so the cap
+// guards against large maps viewed while zoomed far out. BufferFrame returns an error wrapping
+// ErrRegionTooLarge, and keeps the previous frame, when the cap is exceeded. Zero disables it.
+func (tm *Map) SetMaxRegionTiles(n int) {
+	tm.maxRegionTiles = max(n, 0)
+}
+
+// MaxRegionTiles returns the cap set by SetMaxRegionTiles, or 0 if there is none.
+func (tm *Map) MaxRegionTiles() int {
+	return tm.maxRegionTiles
+}
+
+func (tm *Map) checkRegionCap(region Region) error {
+	if tm.maxRegionTiles <= 0 || region.IsEmpty() {
+		return nil
+	}
+
+	width := int64(region.MaxX - region.MinX)
+	height := int64(region.MaxY - region.MinY)
+	size := width * height * int64(len(tm.layers))
+	if size > int64(tm.maxRegionTiles) {
+		return fmt.Errorf("%w: %dx%d tiles over %d layers, cap is %d", ErrRegionTooLarge, width, height, len(tm.layers), tm.maxRegionTiles)
+	}
+	return nil
+}
+
+// ====================== Staging =====================
+
+// staging holds the state of a rebuild carried over between BufferFrame calls.
+type staging struct {
+	active    bool
+	region    Region
+	data      []Data
+	positions []int
+	layer     int      // layer being converted
+	chunks    []*Chunk // chunks of the current layer intersecting the region
+	chunk     int      // next chunk to convert
+	started   bool     // the current chunk is partly converted
+	row       int32    // next row of the current chunk to convert
+}
+
+// pending reports whether a rebuild of region is in progress, e.g. after the map changed.
+func (st *staging) pending(region Region) bool {
+	return st.active && st.region.Equals(&region)
+}
+
+// bufferBudgeted advances the rebuild for a region within the budget, and swaps the cache
+// once it completes.
+func (tm *Map) bufferBudgeted(region Region) error {
+	st := &tm.staging
+	if !st.active || tm.dirty || !st.region.Equals(&region) {
+		tm.restartStaging(region)
+	}
+
+	start := time.Now()
+	work := 0
+
+	for st.layer < len(tm.layers) {
+		if len(st.positions) == st.layer {
+			st.positions = append(st.positions, len(st.data))
+			st.chunks = st.chunks[:0]
+			if tm.layers[st.layer].visibility > 0 {
+				st.chunks = tm.layers[st.layer].query(st.chunks, region)
+			}
+		}
+
+		for st.chunk < len(st.chunks) {
+			chunk := st.chunks[st.chunk]
+			if !st.started {
+				if work > 0 && tm.overBudget(work, start) {
+					return nil
+				}
+				if !chunk.isDecoded {
+					work += int(chunk.w * chunk.h)
+				}
+				if !tm.startChunk(chunk) {
+					st.chunk++
+					continue
+				}
+				st.started = true
+				st.row = max(region.MinY, chunk.y)
+			}
+
+			// Chunks are converted a row at a time, so the single chunk holding a layer of a
+			// finite map is split over calls too. The chunk's tile list is only used when it
+			// is already built, since building it resolves the whole chunk at once.
+			end := min(region.MaxY, chunk.y+chunk.h)
+			for ; st.row < end; st.row++ {
+				if work > 0 && tm.overBudget(work, start) {
+					return nil
+				}
+
+				rows := region
+				rows.MinY, rows.MaxY = st.row, st.row+1
+				n := len(st.data)
+				st.data = tm.appendChunkRegion(st.data, st.layer, chunk, rows, chunk.emitted)
+				work += len(st.data) - n
+			}
+
+			st.started = false
+			st.chunk++
+		}
+
+		tm.sortLayer(st.data[st.positions[st.layer]:])
+		st.layer++
+		st.chunk = 0
+	}
+
+	st.positions = append(st.positions, len(st.data))
+
+	tm.cachedData, st.data = st.data, tm.cachedData
+	tm.cachedPositions, st.positions = st.positions, tm.cachedPositions
+	tm.cachedRegion = region
+	tm.staleLayers = tm.staleLayers[:0]
+	tm.cacheGen++
+	st.active = false
+	return nil
+}
+
+// restartStaging begins a new rebuild for a region, reusing the buffers of the previous one.
+// Changes made to the map after this point mark it dirty again and restart the rebuild.
+func (tm *Map) restartStaging(region Region) {
+	st := &tm.staging
+
+	size := int(max((region.MaxX-region.MinX)*(region.MaxY-region.MinY), 0)) * len(tm.layers)
+	if cap(st.data) < size {
+		dataArena.put(st.data)
+		st.data = dataArena.get(size)
+	}
+
+	st.active = true
+	st.region = region
+	st.data = st.data[:0]
+	st.positions = st.positions[:0]
+	st.chunks = st.chunks[:0]
+	st.layer = 0
+	st.chunk = 0
+	st.started = false
+
+	tm.computeOcclusion(region)
+	tm.dirty = false
+}
+
+func (tm *Map) overBudget(work int, start time.Time) bool {
+	if tm.budget.MaxTiles > 0 && work >= tm.budget.MaxTiles {
+		return true
+	}
+	return tm.budget.MaxTime > 0 && time.Since(start) >= tm.budget.MaxTime
+}
@@ -0,0 +1,52 @@
+package tilemap
+
+import (
+	"testing"
+
+	"github.com/adm87/tiled"
+)
+
+func TestCallbacksUnsubscribe(t *testing.T) {
+	var c callbacks[func()]
+	var a, b int
+	unsubscribeA := c.add(func() { a++ })
+	var unsubscribeB func()
+	unsubscribeB = c.add(func() {
+		b++
+		// Unregistering from a callback doesn't skip the callbacks after it.
+		unsubscribeB()
+	})
+	c.add(func() { a += 10 })
+
+	notify := func() {
+		for _, cb := range c.list {
+			cb.fn()
+		}
+	}
+	notify()
+	unsubscribeA()
+	unsubscribeA()
+	notify()
+
+	if a != 21 || b != 1 {
+		t.Fatalf("notifications = %d, %d, want 21, 1", a, b)
+	}
+}
+
+func TestOnTilesetSwapUnsubscribe(t *testing.T) {
+	tm := cullMap(t, 16, ``)
+	tsx := tm.Tmx.Tilesets[0].Tsx
+
+	calls := 0
+	unsubscribe := tm.OnTilesetSwap(func(int, *tiled.Tsx, *tiled.Tsx) { calls++ })
+	if err := tm.SwapTsx(0, tsx); err != nil {
+		t.Fatal(err)
+	}
+	unsubscribe()
+	if err := tm.SwapTsx(0, tsx); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
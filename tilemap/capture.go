@@ -0,0 +1,176 @@
+package tilemap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var ErrInvalidCapture = errors.New("invalid capture data")
+
+// captureMagic and captureVersion identify the capture file format.
+const (
+	captureMagic   = "TMCAP"
+	captureVersion = 1
+)
+
+// ====================== DrawCommand =====================
+
+// DrawCommand is a resolved draw of a single tile: which source rectangle of which tileset to
+// draw, and where, with everything a renderer needs already applied.
+type DrawCommand struct {
+	Layer     int32      // index of the layer the tile belongs to
+	TsIdx     int32      // tileset index
+	TileID    uint32     // tile ID local to the tileset
+	Src       [4]int32   // source rectangle in the tileset image as x, y, w, h
+	Transform [6]float32 // world transform, in the layout of tiled.FlipFlag.Apply
+	Alpha     float32    // visibility times opacity of the layer and its groups, 0..1
+}
+
+// ====================== Capture =====================
+
+// Capture is the recorded output of a buffered frame. It can be written to a compact binary
+// file and replayed without the map or its assets, e.g. for performance regression tests or
+// to attach to bug reports.
+type Capture struct {
+	Region   Region
+	Commands []DrawCommand
+}
+
+// CaptureFrame records the tiles of the buffered frame as draw commands, in draw order.
+// Tiles whose tileset has no Tsx attached are skipped, since their source can't be resolved.
+func (tm *Map) CaptureFrame() (*Capture, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
+	}
+
+	c := &Capture{
+		Region:   tm.cachedRegion,
+		Commands: make([]DrawCommand, 0, len(tm.cachedData)),
+	}
+
+	itr := tm.Itr()
+	for layer := int32(0); ; layer++ {
+		tiles := itr.Next()
+		if tiles == nil {
+			break
+		}
+
+		alpha := itr.Visibility() * itr.Opacity()
+		for i := range tiles {
+			tile := &tiles[i]
+			if tile.TsIdx < 0 || tile.TsIdx >= len(tm.Tmx.Tilesets) {
+				continue
+			}
+			tsx := tm.Tmx.Tilesets[tile.TsIdx].Tsx
+			if tsx == nil {
+				continue
+			}
+
+			cmd := DrawCommand{
+				Layer:  layer,
+				TsIdx:  int32(tile.TsIdx),
+				TileID: tile.TileID,
+				Alpha:  alpha,
+			}
+			cmd.Src[0], cmd.Src[1], cmd.Src[2], cmd.Src[3] = tsx.TileRect(tile.TileID)
+
+			m := tm.TileTransform(tile, tsx)
+			for j := range m {
+				cmd.Transform[j] = float32(m[j])
+			}
+			c.Commands = append(c.Commands, cmd)
+		}
+	}
+	return c, nil
+}
+
+// Replay calls fn for every recorded draw command, in draw order.
+func (c *Capture) Replay(fn func(cmd *DrawCommand)) {
+	for i := range c.Commands {
+		fn(&c.Commands[i])
+	}
+}
+
+// WriteTo writes the capture in its binary format.
+func (c *Capture) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+
+	header := struct {
+		Version uint16
+		Region  [4]int32
+		Count   uint32
+	}{
+		Version: captureVersion,
+		Region:  [4]int32{c.Region.MinX, c.Region.MinY, c.Region.MaxX, c.Region.MaxY},
+		Count:   uint32(len(c.Commands)),
+	}
+
+	if _, err := io.WriteString(cw, captureMagic); err != nil {
+		return cw.n, err
+	}
+	if err := binary.Write(cw, binary.LittleEndian, &header); err != nil {
+		return cw.n, err
+	}
+	if err := binary.Write(cw, binary.LittleEndian, c.Commands); err != nil {
+		return cw.n, err
+	}
+	return cw.n, bw.Flush()
+}
+
+// ReadCapture reads a capture written by Capture.WriteTo.
+func ReadCapture(r io.Reader) (*Capture, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(captureMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != captureMagic {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidCapture)
+	}
+
+	var header struct {
+		Version uint16
+		Region  [4]int32
+		Count   uint32
+	}
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCapture, err)
+	}
+	if header.Version != captureVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCapture, header.Version)
+	}
+	if header.Count > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: %d commands", ErrInvalidCapture, header.Count)
+	}
+
+	c := &Capture{
+		Region: Region{MinX: header.Region[0], MinY: header.Region[1], MaxX: header.Region[2], MaxY: header.Region[3]},
+	}
+
+	// Read in batches so a corrupt count can't allocate more than the data holds.
+	const batch = 4096
+	for remaining := int(header.Count); remaining > 0; {
+		n := min(remaining, batch)
+		cmds := make([]DrawCommand, n)
+		if err := binary.Read(br, binary.LittleEndian, cmds); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCapture, err)
+		}
+		c.Commands = append(c.Commands, cmds...)
+		remaining -= n
+	}
+	return c, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,29 @@
+package tilemap
+
+import "testing"
+
+func TestCaptureFrameAlpha(t *testing.T) {
+	// The cover layer is translucent, so nothing is culled and both layers are captured.
+	tm := cullMap(t, 16, `opacity="0.5"`)
+	tm.Frame().Set([4]float32{0, 0, 32, 32})
+	if err := tm.BufferFrame(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := tm.CaptureFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Commands) != 8 {
+		t.Fatalf("commands = %d, want 8", len(c.Commands))
+	}
+	for _, cmd := range c.Commands {
+		want := float32(1)
+		if cmd.Layer == 1 {
+			want = 0.5
+		}
+		if cmd.Alpha != want {
+			t.Errorf("layer %d alpha = %v, want %v", cmd.Layer, cmd.Alpha, want)
+		}
+	}
+}
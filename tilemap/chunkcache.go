@@ -0,0 +1,92 @@
+package tilemap
+
+import (
+	"slices"
+
+	"github.com/adm87/tiled"
+)
+
+// ====================== Chunk Tiles =====================
+
+// Each chunk keeps the tiles it emits, resolved and positioned, the first time it is
+// buffered. Moving the frame then only resolves the tiles of chunks entering it; the rest are
+// copied from their chunks row by row. The lists are dropped when a cell of the chunk
+// changes, when tile positions change (see invalidatePositions), and when the way tiles
+// resolve changes (see SetFallbackTile). The level of detail and occlusion culling skip
+// tiles cell by cell, so they buffer without the lists.
+
+// chunkTiles builds the tile list of a decoded chunk of a layer if it isn't up to date.
+func (tm *Map) chunkTiles(layer int, chunk *Chunk) {
+	if chunk.emitted {
+		return
+	}
+
+	offsetX, offsetY := tm.layerWorldOffset(layer)
+
+	chunk.emit = chunk.emit[:0]
+	chunk.emitCols = chunk.emitCols[:0]
+	chunk.emitRows = chunk.emitRows[:0]
+	decoded := chunk.decode() == nil
+	for y := chunk.y; y < chunk.y+chunk.h; y++ {
+		chunk.emitRows = append(chunk.emitRows, int32(len(chunk.emit)))
+		if !decoded {
+			continue
+		}
+		for x := chunk.x; x < chunk.x+chunk.w; x++ {
+			// Empty cells are most of a sparse layer; skip them before resolving anything.
+			if chunk.at(chunk.index(x, y))&tiled.GIDMask == 0 {
+				continue
+			}
+			if tile, ok := tm.getTileFromChunk(chunk, x, y); ok {
+				tile.X += offsetX
+				tile.Y += offsetY
+				tile.LayerIdx = int32(layer)
+				chunk.emit = append(chunk.emit, tile)
+				chunk.emitCols = append(chunk.emitCols, x)
+			}
+		}
+	}
+	chunk.emitRows = append(chunk.emitRows, int32(len(chunk.emit)))
+	chunk.emitted = true
+}
+
+// appendCachedTiles appends the tiles of a decoded chunk within a region to dst, from the
+// chunk's tile list.
+func (tm *Map) appendCachedTiles(dst []Data, layer int, chunk *Chunk, region Region) []Data {
+	tm.chunkTiles(layer, chunk)
+
+	sX, sY := max(region.MinX, chunk.x), max(region.MinY, chunk.y)
+	eX, eY := min(region.MaxX, chunk.x+chunk.w), min(region.MaxY, chunk.y+chunk.h)
+	full := sX == chunk.x && eX == chunk.x+chunk.w
+
+	for y := sY; y < eY; y++ {
+		start, end := chunk.emitRows[y-chunk.y], chunk.emitRows[y-chunk.y+1]
+		if !full {
+			cols := chunk.emitCols[start:end]
+			first, _ := slices.BinarySearch(cols, sX)
+			last, _ := slices.BinarySearch(cols, eX)
+			start, end = start+int32(first), start+int32(last)
+		}
+
+		row := chunk.emit[start:end]
+		if tm.filter.fn == nil {
+			dst = append(dst, row...)
+			continue
+		}
+		for i := range row {
+			if !tm.filtered(row[i]) {
+				dst = append(dst, row[i])
+			}
+		}
+	}
+	return dst
+}
+
+// flushChunkTiles drops the tiles resolved by every chunk, memoized and listed.
+func (tm *Map) flushChunkTiles() {
+	for _, layer := range tm.layers {
+		for _, chunk := range layer.chunks {
+			chunk.Flush()
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package tilemap
+
+import (
+	"slices"
+
+	"github.com/adm87/tiled"
+)
+
+// collisionKey identifies the collision shapes of a tile for a combination of flips.
+type collisionKey struct {
+	tsx    *tiled.Tsx
+	tileID uint32
+	flip   tiled.FlipFlag
+}
+
+// GetTileCollision returns the collision shapes authored for a tile in Tiled's collision
+// editor, flipped the same way as the tile. Shapes are relative to the top-left corner of
+// the tile's cell, in map pixels; GetTileCollisionWorld places them in world coordinates.
+//
+// It returns nil for tiles without collision shapes. The flipped shapes are computed once per
+// combination of flips and shared between calls, so they must not be modified. Rotated shapes
+// keep their position and size, with the rotation mirrored when the tile is.
+func (tm *Map) GetTileCollision(tsIdx int, tileID uint32, flip tiled.FlipFlag) ([]tiled.Object, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
+	}
+
+	if tsIdx < 0 || tsIdx >= len(tm.Tmx.Tilesets) {
+		return nil, ErrTilesetNotFound
+	}
+
+	tsx := tm.Tmx.Tilesets[tsIdx].Tsx
+	if tsx == nil {
+		return nil, ErrTilesetSource
+	}
+
+	tile := tsx.TileByID(tileID)
+	if tile == nil || !tile.HasCollision() {
+		return nil, nil
+	}
+
+	flip = flip.Canonical()
+	if flip == 0 {
+		return tile.ObjectGroup.Objects, nil
+	}
+
+	key := collisionKey{tsx: tsx, tileID: tileID, flip: flip}
+	if shapes, ok := tm.collisions[key]; ok {
+		return shapes, nil
+	}
+
+	_, _, w, h := tsx.TileRect(tileID)
+	m := flip.Apply(float64(w), float64(h))
+
+	shapes := make([]tiled.Object, len(tile.ObjectGroup.Objects))
+	for i := range shapes {
+		shapes[i] = flipShape(tile.ObjectGroup.Objects[i], m)
+	}
+
+	if tm.collisions == nil {
+		tm.collisions = make(map[collisionKey][]tiled.Object)
+	}
+	tm.collisions[key] = shapes
+	return shapes, nil
+}
+
+// flipShape applies a flip transform, in the layout of tiled.FlipFlag.Apply, to an object.
+func flipShape(obj tiled.Object, m [6]float64) tiled.Object {
+	apply := func(x, y float32) (float32, float32) {
+		return float32(m[0]*float64(x) + m[1]*float64(y) + m[4]),
+			float32(m[2]*float64(x) + m[3]*float64(y) + m[5])
+	}
+
+	// A mirroring transform reverses the direction of rotations.
+	if m[0]*m[3]-m[1]*m[2] < 0 {
+		obj.Rotation = -obj.Rotation
+	}
+
+	points := func(p tiled.Polygon) tiled.Polygon {
+		if p.IsEmpty() {
+			return p
+		}
+		pts := slices.Clone(p.Points)
+		for i := 0; i+1 < len(pts); i += 2 {
+			pts[i], pts[i+1] = apply(pts[i], pts[i+1])
+			pts[i], pts[i+1] = pts[i]-float32(m[4]), pts[i+1]-float32(m[5])
+		}
+		return tiled.Polygon{Points: pts}
+	}
+
+	if !obj.Polygon.IsEmpty() || !obj.Polyline.IsEmpty() || obj.IsPoint() {
+		// Vertices are relative to the object's position, so only the position is translated.
+		obj.X, obj.Y = apply(obj.X, obj.Y)
+		obj.Polygon = points(obj.Polygon)
+		obj.Polyline = points(obj.Polyline)
+		return obj
+	}
+
+	// Rectangles and ellipses are flipped through their corners.
+	x0, y0 := apply(obj.X, obj.Y)
+	x1, y1 := apply(obj.X+obj.Width, obj.Y+obj.Height)
+	obj.X, obj.Y = min(x0, x1), min(y0, y1)
+	obj.Width, obj.Height = max(x0, x1)-obj.X, max(y0, y1)-obj.Y
+	return obj
+}
@@ -0,0 +1,124 @@
+package tilemap
+
+import "github.com/adm87/tiled"
+
+const DefaultOpaqueClass = "opaque" // tileset tile class marking tiles that hide what is below them
+
+// ====================== Occlusion culling =====================
+
+// SetOcclusionCulling enables a pass that skips buffering tiles fully covered by an opaque
+// tile in a higher layer, reducing overdraw on maps with stacked ground layers. A nil func
+// disables it, which is the default.
+//
+// The pass only culls when it is certain the tile is hidden: the covering tile must fill its
+// cell exactly (tileset tile size equal to the map's, without a tile offset), and its layer
+// must be fully visible and opaque, with an opacity of 1 and no translucent tint, and share
+// the offset and parallax of the culled layer. With async decoding or a buffer budget, only
+// chunks that are already decoded occlude.
+func (tm *Map) SetOcclusionCulling(opaque OpaqueFunc) {
+	tm.opaque = opaque
+	tm.dirty = true
+}
+
+// OpaqueClass returns an OpaqueFunc treating the tiles of a tileset class as opaque,
+// e.g. DefaultOpaqueClass.
+func (tm *Map) OpaqueClass(class string) OpaqueFunc {
+	return func(tsIdx int, tileID uint32) bool {
+		tsx := tm.Tmx.Tilesets[tsIdx].Tsx
+		if tsx == nil {
+			return false
+		}
+		tile := tsx.TileByID(tileID)
+		return tile != nil && tile.Class == class
+	}
+}
+
+// computeOcclusion records, for every cell of the region, the highest layer with an opaque
+// tile covering it.
+func (tm *Map) computeOcclusion(region Region) {
+	if tm.cover == nil {
+		tm.cover = make(map[uint64]int)
+		tm.opaqueGIDs = make(map[uint32]bool)
+	}
+	clear(tm.cover)
+	clear(tm.opaqueGIDs)
+
+	if tm.opaque == nil || region.IsEmpty() {
+		return
+	}
+
+	decode := tm.decoder == nil && !tm.budget.enabled()
+
+	for i := len(tm.layers) - 1; i > 0; i-- {
+		if l := tm.layers[i]; l.visibility < 1 || l.opacity < 1 || l.tint.A < 0xff {
+			continue
+		}
+
+		for _, chunk := range tm.queryChunks(i, region) {
+			if !chunk.isDecoded && (!decode || chunk.decode() != nil) {
+				continue
+			}
+
+			sX := max(region.MinX, chunk.x)
+			sY := max(region.MinY, chunk.y)
+			eX := min(region.MaxX, chunk.x+chunk.w)
+			eY := min(region.MaxY, chunk.y+chunk.h)
+
+			for y := sY; y < eY; y++ {
+				for x := sX; x < eX; x++ {
+					key := NewTileKey(x, y)
+					if _, ok := tm.cover[key]; ok {
+						continue
+					}
+					if tm.isOpaque(chunk.at(chunk.index(x, y))) {
+						tm.cover[key] = i
+					}
+				}
+			}
+		}
+	}
+}
+
+// occluded reports whether the cell of a layer is hidden by an opaque tile above it.
+func (tm *Map) occluded(layer int, x, y int32) bool {
+	if len(tm.cover) == 0 {
+		return false
+	}
+
+	above, ok := tm.cover[NewTileKey(x, y)]
+	if !ok || above <= layer {
+		return false
+	}
+
+	a, b := tm.layers[above], tm.layers[layer]
+	return a.offsetX == b.offsetX && a.offsetY == b.offsetY &&
+		a.parallaxX == b.parallaxX && a.parallaxY == b.parallaxY
+}
+
+// isOpaque reports whether a GID fills its cell with an opaque tile, memoized per GID.
+func (tm *Map) isOpaque(gid uint32) bool {
+	if opaque, ok := tm.opaqueGIDs[gid]; ok {
+		return opaque
+	}
+
+	opaque := false
+	if tileID, _ := tiled.DecodeGID(gid); tileID != 0 {
+		_, tileID, tsIdx := tiled.TilesetByGID(tm.Tmx, tileID)
+		if tsIdx != -1 && tm.fillsCell(tsIdx, tileID) {
+			opaque = tm.opaque(tsIdx, tileID)
+		}
+	}
+
+	tm.opaqueGIDs[gid] = opaque
+	return opaque
+}
+
+// fillsCell reports whether a tile is drawn exactly over its map cell.
+func (tm *Map) fillsCell(tsIdx int, tileID uint32) bool {
+	tsx := tm.Tmx.Tilesets[tsIdx].Tsx
+	if tsx == nil || tsx.TileOffset != (tiled.Offset{}) {
+		return false
+	}
+	_, _, w, h := tsx.TileRect(tileID)
+	return w == tm.Tmx.TileWidth && h == tm.Tmx.TileHeight
+}
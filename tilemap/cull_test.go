@@ -0,0 +1,80 @@
+package tilemap
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adm87/tiled"
+)
+
+// cullMap builds a 2x2 map of 16px tiles with two layers: a ground layer of plain tiles and a
+// cover layer of tiles of the DefaultOpaqueClass. The tileset tile size and the attributes of
+// the cover layer vary per case.
+func cullMap(t *testing.T, tileSize int, coverAttrs string) *Map {
+	t.Helper()
+
+	tmx := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="2" height="2" tilewidth="16" tileheight="16" infinite="0">
+ <tileset firstgid="1" name="tiles" tilewidth="%[1]d" tileheight="%[1]d" tilecount="2" columns="2">
+  <image source="tiles.png" width="%[2]d" height="%[1]d"/>
+  <tile id="0" class="opaque"/>
+ </tileset>
+ <layer id="1" name="Ground" width="2" height="2">
+  <data encoding="csv">2,2,2,2</data>
+ </layer>
+ <layer id="2" name="Cover" width="2" height="2" %[3]s>
+  <data encoding="csv">1,1,1,1</data>
+ </layer>
+</map>`, tileSize, tileSize*2, coverAttrs)
+
+	loader := tiled.NewLoaderFS(fstest.MapFS{"map.tmx": {Data: []byte(tmx)}})
+	m, err := loader.LoadTmx("map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tm := NewMap()
+	if err := tm.SetTmx(m); err != nil {
+		t.Fatal(err)
+	}
+	tm.SetOcclusionCulling(tm.OpaqueClass(DefaultOpaqueClass))
+	return tm
+}
+
+func TestOcclusionCulling(t *testing.T) {
+	tests := []struct {
+		name       string
+		tileSize   int
+		coverAttrs string
+		ground     int // ground tiles expected to be buffered
+		cover      int // cover tiles expected to be buffered
+	}{
+		{"stacked opaque layer", 16, ``, 0, 4},
+		{"hidden layer", 16, `visible="0"`, 4, 0},
+		{"translucent layer", 16, `opacity="0.5"`, 4, 4},
+		{"translucent tint", 16, `tintcolor="#80ffffff"`, 4, 4},
+		{"opaque tint", 16, `tintcolor="#ff0000"`, 0, 4},
+		{"offset mismatch", 16, `offsetx="4"`, 4, 4},
+		{"parallax mismatch", 16, `parallaxx="0.5"`, 4, 4},
+		{"wrong tile size", 32, ``, 4, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := cullMap(t, tt.tileSize, tt.coverAttrs)
+			tm.Frame().Set([4]float32{0, 0, 32, 32})
+			if err := tm.BufferFrame(); err != nil {
+				t.Fatal(err)
+			}
+
+			itr := tm.Itr()
+			if got := len(itr.Layer(0)); got != tt.ground {
+				t.Errorf("ground tiles = %d, want %d", got, tt.ground)
+			}
+			if got := len(itr.Layer(1)); got != tt.cover {
+				t.Errorf("cover tiles = %d, want %d", got, tt.cover)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package tilemap
+
+// ====================== Decode Control =====================
+
+// DecodedChunkCount returns how many chunks are currently decoded, across all layers.
+func (tm *Map) DecodedChunkCount() int {
+	n := 0
+	for _, layer := range tm.layers {
+		for _, chunk := range layer.chunks {
+			if chunk.isDecoded {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// SetEagerDecode makes SetTmx decode every chunk of the map up front, as DecodeAllChunks does,
+// instead of on first use. Chunks decode lazily by default, which keeps loading huge infinite
+// maps fast; eager decoding moves the cost to loading, e.g. behind a loading screen, so no
+// frame decodes chunks. It applies from the next SetTmx.
+func (tm *Map) SetEagerDecode(enabled bool) {
+	tm.eagerDecode = enabled
+}
+
+// DecodeAllChunks decodes every chunk of every layer up front, instead of on first use.
+func (tm *Map) DecodeAllChunks() error {
+	if tm.Tmx == nil {
+		return ErrNoTmxData
+	}
+
+	for _, layer := range tm.layers {
+		for _, chunk := range layer.chunks {
+			if err := chunk.decode(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// EvictChunksOutside releases the decoded data of chunks that don't intersect a region (in
+// tile coordinates), e.g. after teleporting far away. Evicted chunks decode again when needed.
+// Chunks with edits are kept, so no changes are lost. It returns the number of evicted chunks.
+func (tm *Map) EvictChunksOutside(region Region) int {
+	n := 0
+	for _, layer := range tm.layers {
+		for _, chunk := range layer.chunks {
+			if !chunk.isDecoded || chunk.modified || chunk.intersects(region) {
+				continue
+			}
+			chunk.evict()
+			n++
+		}
+	}
+	return n
+}
+
+// intersects reports whether the chunk overlaps a region.
+func (c *Chunk) intersects(region Region) bool {
+	return c.x < region.MaxX && c.x+c.w > region.MinX && c.y < region.MaxY && c.y+c.h > region.MinY
+}
+
+// evict drops the decoded data, returning the chunk to its undecoded state.
+func (c *Chunk) evict() {
+	c.isDecoded = false
+	c.data = nil
+	c.packed = nil
+	c.palette = nil
+	c.memo = nil
+	c.memoState = nil
+	c.content = Region{}
+	c.stale = false
+	c.emitted = false
+}
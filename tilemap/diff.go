@@ -0,0 +1,57 @@
+package tilemap
+
+// ====================== Frame Diff =====================
+
+// DiffFrames returns the tiles of the visible layers that enter and leave view when the
+// buffered region moves from prev to next, in tile coordinates, e.g. the regions of two
+// successive frames (see Map.Region). Systems keeping state per visible tile, such as
+// particles on water tiles or audio emitters, can update only the tiles that changed instead
+// of scanning every visible tile.
+//
+// Tiles are listed layer by layer, row by row, and pass through the map's filter; occlusion
+// culling and the level of detail are not applied. Only the cells of the two regions are
+// compared, so tiles edited inside both regions are in neither list. The returned slices are
+// reused between calls.
+func (tm *Map) DiffFrames(prev, next Region) (entered, exited []Data) {
+	tm.diffEntered = tm.appendRegionTiles(tm.diffEntered[:0], next, prev)
+	tm.diffExited = tm.appendRegionTiles(tm.diffExited[:0], prev, next)
+	return tm.diffEntered, tm.diffExited
+}
+
+// appendRegionTiles appends the tiles of the visible layers within region but outside skip.
+func (tm *Map) appendRegionTiles(dst []Data, region, skip Region) []Data {
+	if tm.Tmx == nil || region.IsEmpty() {
+		return dst
+	}
+
+	for layer := range tm.layers {
+		if tm.layers[layer].visibility <= 0 {
+			continue
+		}
+		offsetX, offsetY := tm.layerWorldOffset(layer)
+
+		for _, chunk := range tm.queryChunks(layer, region) {
+			sX, sY := max(region.MinX, chunk.x), max(region.MinY, chunk.y)
+			eX, eY := min(region.MaxX, chunk.x+chunk.w), min(region.MaxY, chunk.y+chunk.h)
+
+			for y := sY; y < eY; y++ {
+				for x := sX; x < eX; x++ {
+					if x >= skip.MinX && x < skip.MaxX && y >= skip.MinY && y < skip.MaxY {
+						continue
+					}
+					tile, ok := tm.getTileFromChunk(chunk, x, y)
+					if !ok {
+						continue
+					}
+					tile.X += offsetX
+					tile.Y += offsetY
+					tile.LayerIdx = int32(layer)
+					if !tm.filtered(tile) {
+						dst = append(dst, tile)
+					}
+				}
+			}
+		}
+	}
+	return dst
+}
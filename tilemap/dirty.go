@@ -0,0 +1,76 @@
+package tilemap
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ====================== Dirty Rects =====================
+
+// SetBakeChunkSize sets the size, in tiles, of the square chunks renderers bake layers into,
+// which ConsumeDirtyRects aligns its regions to. A size of 0 uses DefaultChunkSize. Changing
+// the size discards the pending dirty regions, so baked layers must be rebuilt.
+func (tm *Map) SetBakeChunkSize(size int32) {
+	size = max(size, 0)
+	if size == tm.bakeSize {
+		return
+	}
+	tm.bakeSize = size
+	for _, l := range tm.layers {
+		clear(l.dirty)
+	}
+}
+
+// BakeChunkSize returns the size, in tiles, of the chunks dirty regions are aligned to.
+func (tm *Map) BakeChunkSize() int32 {
+	if tm.bakeSize == 0 {
+		return DefaultChunkSize
+	}
+	return tm.bakeSize
+}
+
+// ConsumeDirtyRects returns the regions, in tile coordinates, of the bake chunks of a layer
+// whose tiles were mutated since the last call, and forgets them. Regions are aligned to the
+// bake chunk grid and ordered row-major, so a renderer baking a texture per chunk
+// re-rasterizes exactly the chunks returned instead of the whole layer.
+//
+// Rebuilding the layers, e.g. with SetTmx, discards the pending regions; baked layers are
+// rebuilt from scratch when Generation changes.
+func (tm *Map) ConsumeDirtyRects(layer int) ([]Region, error) {
+	if layer < 0 || layer >= len(tm.layers) {
+		return nil, ErrLayerNotFound
+	}
+
+	l := tm.layers[layer]
+	if len(l.dirty) == 0 {
+		return nil, nil
+	}
+
+	rects := make([]Region, 0, len(l.dirty))
+	for _, r := range l.dirty {
+		rects = append(rects, r)
+	}
+	clear(l.dirty)
+
+	slices.SortFunc(rects, func(a, b Region) int {
+		if a.MinY != b.MinY {
+			return cmp.Compare(a.MinY, b.MinY)
+		}
+		return cmp.Compare(a.MinX, b.MinX)
+	})
+	return rects, nil
+}
+
+// markDirty records the bake chunk containing a mutated tile.
+func (tm *Map) markDirty(layer int, x, y int32) {
+	l := tm.layers[layer]
+	size := tm.BakeChunkSize()
+	key := NewChunkKey(x, y, size)
+	if _, ok := l.dirty[key]; ok {
+		return
+	}
+	if l.dirty == nil {
+		l.dirty = make(map[uint64]Region)
+	}
+	l.dirty[key] = DecodeChunkKey(key, size)
+}
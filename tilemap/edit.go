@@ -0,0 +1,81 @@
+package tilemap
+
+import (
+	"fmt"
+
+	"github.com/adm87/tiled"
+)
+
+// ====================== Editing =====================
+
+// SetTileAt stores a GID, including its flip flags, at a tile coordinate of a layer, e.g. for
+// destructible terrain or level editors. The buffered region is rebuilt on the next
+// BufferFrame if it holds the tile, and tile change callbacks are called. It returns
+// ErrTileNotFound for coordinates outside the layer's chunks. Use FillRegion to edit many
+// tiles at once, and SyncTmx to write the edits back to the Tmx data.
+func (tm *Map) SetTileAt(layer int, x, y int32, gid uint32) error {
+	_, err := tm.setGID(layer, x, y, gid)
+	return err
+}
+
+// ClearTileAt empties the tile at a tile coordinate of a layer.
+func (tm *Map) ClearTileAt(layer int, x, y int32) error {
+	return tm.SetTileAt(layer, x, y, 0)
+}
+
+// ModifiedLayers returns the indices of the layers edited since their data was last written
+// back to the Tmx data by SyncTmx, in ascending order.
+func (tm *Map) ModifiedLayers() []int {
+	var layers []int
+	for i, l := range tm.layers {
+		for _, chunk := range l.chunks {
+			if chunk.unsynced {
+				layers = append(layers, i)
+				break
+			}
+		}
+	}
+	return layers
+}
+
+// SyncTmx re-encodes the edited chunks of every layer into the layer data of the Tmx, in the
+// encoding and compression they were loaded with, so the map can be saved with its edits.
+// Only the edited chunks are encoded. DiffFromOriginal still compares against the content the
+// map was set with.
+func (tm *Map) SyncTmx() error {
+	if tm.Tmx == nil {
+		return ErrNoTmxData
+	}
+
+	var gids []uint32
+	for i, l := range tm.layers {
+		data := &tm.Tmx.Layers[i].Data
+		for _, chunk := range l.chunks {
+			if !chunk.unsynced {
+				continue
+			}
+
+			gids = gids[:0]
+			for j := range chunk.len() {
+				gids = append(gids, chunk.at(j))
+			}
+			content, err := tiled.EncodeContent(gids, chunk.encoding, chunk.compression)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", tm.Tmx.Layers[i].Name, err)
+			}
+
+			if !tm.Tmx.IsInfinite() {
+				data.Content = content
+			} else {
+				for j := range data.Chunks {
+					if data.Chunks[j].X == chunk.x && data.Chunks[j].Y == chunk.y {
+						data.Chunks[j].Content = content
+						break
+					}
+				}
+			}
+			chunk.unsynced = false
+		}
+	}
+	return nil
+}
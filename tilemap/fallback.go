@@ -0,0 +1,93 @@
+package tilemap
+
+import (
+	"image/color"
+
+	"github.com/adm87/tiled"
+)
+
+const PlaceholderTileset = -1 // TsIdx of placeholder tiles
+
+// PlaceholderColor is the color renderers fill placeholder tiles with.
+var PlaceholderColor = color.RGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}
+
+// ====================== Fallback =====================
+
+// SetFallbackTile controls what happens to tiles that can't be drawn: tiles whose GID
+// belongs to no tileset, whose tileset has no Tsx attached, or whose tile ID lies outside
+// their tileset. By default they are buffered as they resolve, or dropped when they don't
+// resolve at all, so content problems are easy to miss. When enabled, a placeholder is
+// buffered in their place: TsIdx is PlaceholderTileset, TileID the GID without flip flags,
+// and renderers fill the map's tile size at the tile's position with PlaceholderColor.
+func (tm *Map) SetFallbackTile(enabled bool) {
+	tm.fallback = enabled
+	clear(tm.drawable)
+	tm.flushChunkTiles()
+	tm.dirty = true
+}
+
+// FallbackTile reports whether placeholders are buffered for tiles that can't be drawn.
+func (tm *Map) FallbackTile() bool {
+	return tm.fallback
+}
+
+// IsPlaceholder reports whether the tile stands in for a tile that can't be drawn.
+func (d *Data) IsPlaceholder() bool {
+	return d.TsIdx == PlaceholderTileset
+}
+
+// resolveTile turns a GID into tile data, substituting a placeholder for tiles that can't
+// be drawn when the fallback tile is enabled.
+func (tm *Map) resolveTile(gid uint32, x, y float32) (Data, bool) {
+	tile, ok := GetTileData(gid, tm.Tmx, x, y)
+	if !tm.fallback || (ok && tm.isDrawable(gid, &tile)) {
+		return tile, ok
+	}
+
+	tileID, flipFlags := tiled.DecodeGID(gid)
+	if tileID == 0 {
+		return tile, false
+	}
+	return Data{
+		TsIdx:    PlaceholderTileset,
+		TileID:   tileID,
+		FlipFlag: flipFlags,
+		X:        x,
+		Y:        y,
+	}, true
+}
+
+// isDrawable reports whether a resolved tile has the tileset data needed to draw it.
+// Results are memoized per GID, since the checks scan the tileset's tiles.
+func (tm *Map) isDrawable(gid uint32, tile *Data) bool {
+	tileID, _ := tiled.DecodeGID(gid)
+	if drawable, ok := tm.drawable[tileID]; ok {
+		return drawable
+	}
+
+	drawable := false
+	if tsx := tm.Tmx.Tilesets[tile.TsIdx].Tsx; tsx != nil {
+		if tsx.Columns > 0 {
+			drawable = tsx.TileCount <= 0 || int64(tile.TileID) < int64(tsx.TileCount)
+		} else {
+			// Image collections only have the tiles they list, each with its own image.
+			t := tsx.TileByID(tile.TileID)
+			drawable = t != nil && t.Image != nil && t.Image.Source != ""
+		}
+	}
+
+	if tm.drawable == nil {
+		tm.drawable = make(map[uint32]bool)
+	}
+	tm.drawable[tileID] = drawable
+	return drawable
+}
+
+// invalidateFallback forgets which tiles can be drawn after a tileset changed.
+func (tm *Map) invalidateFallback() {
+	clear(tm.drawable)
+	if tm.fallback {
+		tm.flushChunkTiles()
+		tm.dirty = true
+	}
+}
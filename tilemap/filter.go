@@ -0,0 +1,48 @@
+package tilemap
+
+// FilterFunc reports whether a tile is buffered. Tiles carry their layer in LayerIdx.
+type FilterFunc func(tile Data) bool
+
+// ====================== Filter =====================
+
+// SetFilter sets a predicate applied to every tile as it is buffered, e.g. hiding tiles of a
+// "secret" class until they are discovered. Filtering while buffering costs once per buffered
+// region instead of once per drawn frame. Pass a nil func to buffer every tile.
+//
+// The buffered region is rebuilt when the filter is set, and on BufferFrame whenever
+// generation returns a different value than at the last buffer, so a filter reading game
+// state can bump a counter when that state changes. generation may be nil for filters that
+// never change.
+func (tm *Map) SetFilter(fn FilterFunc, generation func() uint64) {
+	tm.filter.fn = fn
+	tm.filter.generation = generation
+	tm.filter.seen = 0
+	if generation != nil {
+		tm.filter.seen = generation()
+	}
+	tm.dirty = true
+}
+
+// filter is the tile predicate of a map.
+type filter struct {
+	fn         FilterFunc
+	generation func() uint64
+	seen       uint64 // generation the buffered region was filtered with
+}
+
+// checkFilter marks the buffered region dirty when the filter's generation moved on.
+func (tm *Map) checkFilter() {
+	f := &tm.filter
+	if f.fn == nil || f.generation == nil {
+		return
+	}
+	if gen := f.generation(); gen != f.seen {
+		f.seen = gen
+		tm.dirty = true
+	}
+}
+
+// filtered reports whether the filter hides a tile.
+func (tm *Map) filtered(tile Data) bool {
+	return tm.filter.fn != nil && !tm.filter.fn(tile)
+}
@@ -0,0 +1,195 @@
+package tilemap
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidFogData = errors.New("invalid fog data")
+
+const (
+	fogChunkSize  = DefaultChunkSize
+	fogChunkWords = int(fogChunkSize*fogChunkSize) / 64
+)
+
+var fogMagic = [4]byte{'F', 'O', 'G', '1'}
+
+// ====================== Fog =====================
+
+// Fog is a discovery mask over the tiles of a map, e.g. for minimaps and fog of war. World
+// positions are converted the same way the map converts them, following its orientation,
+// origin, Y direction and pixels per unit.
+//
+// Cells are stored as bits in chunks of DefaultChunkSize tiles, so it works for infinite
+// maps as well. For finite maps, cells outside the map are ignored.
+type Fog struct {
+	tm      *Map
+	bounded bool
+	bounds  Region
+
+	chunks map[uint64]*[fogChunkWords]uint64
+}
+
+// NewFog creates an empty discovery mask matching the dimensions of the map's Tmx. Set the
+// map's coordinate system before revealing world positions; changing it later doesn't move
+// revealed cells.
+func NewFog(tm *Map) (*Fog, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
+	}
+
+	f := &Fog{
+		tm:     tm,
+		chunks: make(map[uint64]*[fogChunkWords]uint64),
+	}
+
+	if !tm.Tmx.IsInfinite() {
+		f.bounded = true
+		f.bounds = Region{MinX: 0, MinY: 0, MaxX: tm.Tmx.Width, MaxY: tm.Tmx.Height}
+	}
+	return f, nil
+}
+
+// IsRevealed reports whether a tile coordinate has been revealed.
+func (f *Fog) IsRevealed(x, y int32) bool {
+	words, ok := f.chunks[fogChunkKey(x, y)]
+	if !ok {
+		return false
+	}
+	bit := fogBit(x, y)
+	return words[bit/64]&(1<<(bit%64)) != 0
+}
+
+// Reveal reveals a single tile coordinate.
+func (f *Fog) Reveal(x, y int32) {
+	if f.bounded && (x < f.bounds.MinX || x >= f.bounds.MaxX || y < f.bounds.MinY || y >= f.bounds.MaxY) {
+		return
+	}
+
+	key := fogChunkKey(x, y)
+	words, ok := f.chunks[key]
+	if !ok {
+		words = new([fogChunkWords]uint64)
+		f.chunks[key] = words
+	}
+	bit := fogBit(x, y)
+	words[bit/64] |= 1 << (bit % 64)
+}
+
+// RevealRegion reveals every tile within a region (in tile coordinates).
+func (f *Fog) RevealRegion(region Region) {
+	region = f.clamp(region)
+	for y := region.MinY; y < region.MaxY; y++ {
+		for x := region.MinX; x < region.MaxX; x++ {
+			f.Reveal(x, y)
+		}
+	}
+}
+
+// RevealCircle reveals every tile whose center lies within radius of a world position.
+func (f *Fog) RevealCircle(x, y, radius float32) {
+	tm := f.tm
+	if tm.Tmx == nil {
+		return
+	}
+
+	// Cells more than r rings away from the cell containing the position are farther than
+	// ringDistance(r), so only the rings closer than the radius need testing.
+	cx, cy := tm.worldToTile(x, y)
+	r := int32(0)
+	for tm.ringDistance(r+1) <= radius {
+		r++
+	}
+	region := f.clamp(Region{MinX: cx - r, MinY: cy - r, MaxX: cx + r + 1, MaxY: cy + r + 1})
+
+	for ty := region.MinY; ty < region.MaxY; ty++ {
+		for tx := region.MinX; tx < region.MaxX; tx++ {
+			wx, wy := tm.tileCenter(tx, ty)
+			if hypot(wx-x, wy-y) <= radius {
+				f.Reveal(tx, ty)
+			}
+		}
+	}
+}
+
+// Reset hides every tile again.
+func (f *Fog) Reset() {
+	clear(f.chunks)
+}
+
+// MarshalBinary encodes the revealed cells for save games.
+func (f *Fog) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 8+len(f.chunks)*(8+fogChunkWords*8))
+	buf = append(buf, fogMagic[:]...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(f.chunks)))
+
+	for key, words := range f.chunks {
+		buf = binary.LittleEndian.AppendUint64(buf, key)
+		for _, w := range words {
+			buf = binary.LittleEndian.AppendUint64(buf, w)
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary restores revealed cells encoded by MarshalBinary, replacing the current mask.
+func (f *Fog) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 || [4]byte(data[:4]) != fogMagic {
+		return ErrInvalidFogData
+	}
+
+	count := int(binary.LittleEndian.Uint32(data[4:8]))
+	data = data[8:]
+	if len(data) != count*(8+fogChunkWords*8) {
+		return ErrInvalidFogData
+	}
+
+	if f.chunks == nil {
+		f.chunks = make(map[uint64]*[fogChunkWords]uint64, count)
+	}
+	clear(f.chunks)
+
+	for range count {
+		key := binary.LittleEndian.Uint64(data)
+		data = data[8:]
+
+		words := new([fogChunkWords]uint64)
+		for i := range words {
+			words[i] = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		}
+		f.chunks[key] = words
+	}
+	return nil
+}
+
+func (f *Fog) clamp(region Region) Region {
+	if !f.bounded {
+		return region
+	}
+	return Region{
+		MinX: max(region.MinX, f.bounds.MinX),
+		MinY: max(region.MinY, f.bounds.MinY),
+		MaxX: min(region.MaxX, f.bounds.MaxX),
+		MaxY: min(region.MaxY, f.bounds.MaxY),
+	}
+}
+
+func fogChunkKey(x, y int32) uint64 {
+	return NewChunkKey(x, y, fogChunkSize)
+}
+
+func fogBit(x, y int32) int32 {
+	lx := x - floorDivInt(x, fogChunkSize)*fogChunkSize
+	ly := y - floorDivInt(y, fogChunkSize)*fogChunkSize
+	return ly*fogChunkSize + lx
+}
+
+// floorDivInt divides rounding toward negative infinity.
+func floorDivInt(a, b int32) int32 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,91 @@
+package tilemap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adm87/tiled"
+)
+
+// fogMap builds an empty 20x20 map of 32x16 tiles with the given orientation attributes.
+func fogMap(t *testing.T, orientation string) *Map {
+	t.Helper()
+
+	tmx := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" %s renderorder="right-down" width="20" height="20" tilewidth="32" tileheight="16" infinite="0">
+ <layer id="1" name="Ground" width="20" height="20">
+  <data encoding="csv">%s0</data>
+ </layer>
+</map>`, orientation, strings.Repeat("0,", 20*20-1))
+
+	loader := tiled.NewLoaderFS(fstest.MapFS{"map.tmx": {Data: []byte(tmx)}})
+	m, err := loader.LoadTmx("map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tm := NewMap()
+	if err := tm.SetTmx(m); err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+func TestFogRevealCircle(t *testing.T) {
+	orientations := map[string]string{
+		"orthogonal": `orientation="orthogonal"`,
+		"isometric":  `orientation="isometric"`,
+		"staggered":  `orientation="staggered" staggeraxis="y" staggerindex="odd"`,
+	}
+	systems := []struct {
+		name  string
+		setup func(tm *Map)
+	}{
+		{"default", func(tm *Map) {}},
+		{"origin y-up ppu", func(tm *Map) {
+			tm.SetOrigin(100, 50)
+			tm.SetYUp(true)
+			if err := tm.SetPixelsPerUnit(16); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	for name, orientation := range orientations {
+		for _, sys := range systems {
+			t.Run(name+"/"+sys.name, func(t *testing.T) {
+				tm := fogMap(t, orientation)
+				sys.setup(tm)
+
+				// Reveal around the center of tile (7, 9), with a radius covering a few cells.
+				x, y := tm.TileToWorld(7.5, 9.5)
+				radius := 70 / tm.PixelsPerUnit()
+
+				fog, err := NewFog(tm)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fog.RevealCircle(x, y, radius)
+
+				revealed := 0
+				for ty := int32(0); ty < 20; ty++ {
+					for tx := int32(0); tx < 20; tx++ {
+						cx, cy := tm.TileToWorld(float32(tx)+0.5, float32(ty)+0.5)
+						want := hypot(cx-x, cy-y) <= radius
+						if got := fog.IsRevealed(tx, ty); got != want {
+							t.Errorf("tile (%d, %d) revealed = %v, want %v", tx, ty, got, want)
+						}
+						if want {
+							revealed++
+						}
+					}
+				}
+				if !fog.IsRevealed(7, 9) || revealed < 5 {
+					t.Errorf("revealed %d tiles around (7, 9), want the tile and its neighbors", revealed)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package tilemap
+
+import (
+	"math"
+
+	"github.com/adm87/tiled"
+)
+
+// CostRules describes how the cells of a map are weighted when exported as a graph.
+//
+// The cost of a cell is the highest cost of the tiles stacked on it across the checked
+// layers, or EmptyCost when they are all empty. A cost of +Inf makes a cell impassable.
+type CostRules struct {
+	Layers    []int                                  // layers checked, nil checks every layer
+	Cost      func(tsIdx int, tileID uint32) float32 // nil gives every tile a cost of 1
+	EmptyCost float32                                // cost of empty cells, 0 defaults to 1
+	Diagonal  bool                                   // connect cells diagonally as well
+}
+
+// ====================== Graph =====================
+
+// Edge is a weighted, directed connection between two cells of a Graph.
+// Node IDs are int64 so edges can be fed to gonum's graph packages directly.
+type Edge struct {
+	From, To int64
+	Weight   float64
+}
+
+// Graph is a weighted grid graph of a region of a map, for AI planners and flow fields.
+type Graph struct {
+	Region   Region
+	Costs    []float32 // per cell, row-major, +Inf for impassable cells
+	Diagonal bool
+}
+
+// Graph exports a region (in tile coordinates) of the map as a weighted grid graph.
+func (tm *Map) Graph(region Region, rules CostRules) (*Graph, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
+	}
+
+	for _, layer := range rules.Layers {
+		if layer < 0 || layer >= len(tm.layers) {
+			return nil, ErrLayerNotFound
+		}
+	}
+
+	layers := rules.Layers
+	if layers == nil {
+		layers = make([]int, len(tm.layers))
+		for i := range layers {
+			layers[i] = i
+		}
+	}
+
+	emptyCost := rules.EmptyCost
+	if emptyCost == 0 {
+		emptyCost = 1
+	}
+
+	width := max(region.MaxX-region.MinX, 0)
+	height := max(region.MaxY-region.MinY, 0)
+
+	g := &Graph{
+		Region:   region,
+		Costs:    make([]float32, width*height),
+		Diagonal: rules.Diagonal,
+	}
+
+	for y := region.MinY; y < region.MaxY; y++ {
+		for x := region.MinX; x < region.MaxX; x++ {
+			cost, inside := float32(0), false
+			for _, layer := range layers {
+				gid, err := tm.gidAt(layer, x, y)
+				if err != nil {
+					continue
+				}
+				inside = true
+
+				tileID, _ := tiled.DecodeGID(gid)
+				if tileID == 0 {
+					continue
+				}
+
+				c := float32(1)
+				if rules.Cost != nil {
+					if _, id, tsIdx := tiled.TilesetByGID(tm.Tmx, tileID); tsIdx != -1 {
+						c = rules.Cost(tsIdx, id)
+					}
+				}
+				cost = max(cost, c)
+			}
+
+			switch {
+			case !inside:
+				cost = float32(math.Inf(1))
+			case cost == 0:
+				cost = emptyCost
+			}
+			g.Costs[(y-region.MinY)*width+(x-region.MinX)] = cost
+		}
+	}
+	return g, nil
+}
+
+func (g *Graph) Width() int32 {
+	return g.Region.MaxX - g.Region.MinX
+}
+
+func (g *Graph) Height() int32 {
+	return g.Region.MaxY - g.Region.MinY
+}
+
+// Node returns the ID of the cell at a tile coordinate, or -1 if it lies outside the graph.
+func (g *Graph) Node(x, y int32) int64 {
+	if x < g.Region.MinX || x >= g.Region.MaxX || y < g.Region.MinY || y >= g.Region.MaxY {
+		return -1
+	}
+	return int64(y-g.Region.MinY)*int64(g.Width()) + int64(x-g.Region.MinX)
+}
+
+// Coord returns the tile coordinate of a node.
+func (g *Graph) Coord(id int64) (x, y int32) {
+	w := int64(g.Width())
+	return g.Region.MinX + int32(id%w), g.Region.MinY + int32(id/w)
+}
+
+// Cost returns the cost of entering a node, +Inf if it is impassable or out of range.
+func (g *Graph) Cost(id int64) float32 {
+	if id < 0 || id >= int64(len(g.Costs)) {
+		return float32(math.Inf(1))
+	}
+	return g.Costs[id]
+}
+
+// Neighbors appends the outgoing edges of a node to dst and returns the extended slice.
+// The weight of an edge is the mean cost of both cells scaled by the step distance.
+func (g *Graph) Neighbors(id int64, dst []Edge) []Edge {
+	from := g.Cost(id)
+	if math.IsInf(float64(from), 1) {
+		return dst
+	}
+
+	x, y := g.Coord(id)
+	for _, d := range neighborOffsets(g.Diagonal) {
+		to := g.Node(x+d[0], y+d[1])
+		if to == -1 {
+			continue
+		}
+
+		cost := g.Costs[to]
+		if math.IsInf(float64(cost), 1) {
+			continue
+		}
+
+		// Disallow cutting corners past impassable cells.
+		if d[0] != 0 && d[1] != 0 {
+			if math.IsInf(float64(g.Cost(g.Node(x+d[0], y))), 1) || math.IsInf(float64(g.Cost(g.Node(x, y+d[1]))), 1) {
+				continue
+			}
+		}
+
+		dist := 1.0
+		if d[0] != 0 && d[1] != 0 {
+			dist = math.Sqrt2
+		}
+		dst = append(dst, Edge{
+			From:   id,
+			To:     to,
+			Weight: float64(from+cost) / 2 * dist,
+		})
+	}
+	return dst
+}
+
+// Edges returns the full edge list of the graph.
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+	for id := range int64(len(g.Costs)) {
+		edges = g.Neighbors(id, edges)
+	}
+	return edges
+}
+
+var (
+	cardinalOffsets = [][2]int32{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	diagonalOffsets = [][2]int32{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {-1, 1}, {1, -1}, {-1, -1}}
+)
+
+func neighborOffsets(diagonal bool) [][2]int32 {
+	if diagonal {
+		return diagonalOffsets
+	}
+	return cardinalOffsets
+}
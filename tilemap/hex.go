@@ -0,0 +1,199 @@
+package tilemap
+
+import (
+	"math"
+
+	"github.com/adm87/tiled"
+)
+
+// ====================== Hexagonal =====================
+
+// hexLayout holds the measures of a staggered or hexagonal map, computed the way Tiled's
+// hexagonal renderer does. Staggered maps are laid out as hexagonal maps whose hexagons have
+// no flat side, which makes them diamonds.
+type hexLayout struct {
+	staggerX    bool
+	staggerEven bool
+
+	tileW, tileH       float32
+	sideX, sideY       float32 // length of the flat side along the stagger axis
+	sideOffX, sideOffY float32 // extent of the slanted sides
+	colW, rowH         float32 // distance between columns and rows of the staggered axis
+}
+
+func (tm *Map) hexLayout() hexLayout {
+	tmx := tm.Tmx
+	l := hexLayout{
+		staggerX:    tmx.StaggerAxis == tiled.StaggerAxisX,
+		staggerEven: tmx.StaggerIndex == tiled.StaggerIndexEven,
+	}
+
+	// Tiled rounds the tile size down to even numbers so the hexagons share their corners.
+	tileW, tileH := tmx.TileWidth&^1, tmx.TileHeight&^1
+	var sideX, sideY int32
+	if tmx.Orientation == tiled.OrientationHexagonal {
+		if l.staggerX {
+			sideX = tmx.HexSideLength
+		} else {
+			sideY = tmx.HexSideLength
+		}
+	}
+	sideOffX, sideOffY := (tileW-sideX)/2, (tileH-sideY)/2
+
+	l.tileW, l.tileH = float32(tileW), float32(tileH)
+	l.sideX, l.sideY = float32(sideX), float32(sideY)
+	l.sideOffX, l.sideOffY = float32(sideOffX), float32(sideOffY)
+	l.colW, l.rowH = float32(sideOffX+sideX), float32(sideOffY+sideY)
+	return l
+}
+
+// doStagger reports whether a row or column of the stagger axis is shifted.
+func (l *hexLayout) doStagger(i int32) bool {
+	return (i&1 != 0) != l.staggerEven
+}
+
+// cellToPixel returns the top-left corner of the bounding box of a cell.
+func (l *hexLayout) cellToPixel(x, y int32) (float32, float32) {
+	if l.staggerX {
+		py := float32(y) * (l.tileH + l.sideY)
+		if l.doStagger(x) {
+			py += l.rowH
+		}
+		return float32(x) * l.colW, py
+	}
+
+	px := float32(x) * (l.tileW + l.sideX)
+	if l.doStagger(y) {
+		px += l.colW
+	}
+	return px, float32(y) * l.rowH
+}
+
+// pixelToCell returns the cell whose hexagon, or diamond, contains a pixel position. The
+// position is matched to the nearest of the centers of the cells around it.
+func (l *hexLayout) pixelToCell(x, y float32) (int32, int32) {
+	if l.staggerX {
+		if l.staggerEven {
+			x -= l.tileW
+		} else {
+			x -= l.sideOffX
+		}
+	} else {
+		if l.staggerEven {
+			y -= l.tileH
+		} else {
+			y -= l.sideOffY
+		}
+	}
+
+	// Start with the grid-aligned block of two by two cells containing the position.
+	refX := int32(math.Floor(float64(x / (l.colW * 2))))
+	refY := int32(math.Floor(float64(y / (l.rowH * 2))))
+	relX := x - float32(refX)*l.colW*2
+	relY := y - float32(refY)*l.rowH*2
+
+	var centers [4][2]float32
+	var offsets [4][2]int32
+	if l.staggerX {
+		refX *= 2
+		if l.staggerEven {
+			refX++
+		}
+		left := l.sideX / 2
+		centerX, centerY := left+l.colW, l.tileH/2
+		centers = [4][2]float32{{left, centerY}, {centerX, centerY - l.rowH}, {centerX, centerY + l.rowH}, {centerX + l.colW, centerY}}
+		offsets = [4][2]int32{{0, 0}, {1, -1}, {1, 0}, {2, 0}}
+	} else {
+		refY *= 2
+		if l.staggerEven {
+			refY++
+		}
+		top := l.sideY / 2
+		centerX, centerY := l.tileW/2, top+l.rowH
+		centers = [4][2]float32{{centerX, top}, {centerX - l.colW, centerY}, {centerX + l.colW, centerY}, {centerX, centerY + l.rowH}}
+		offsets = [4][2]int32{{0, 0}, {-1, 1}, {1, 1}, {0, 2}}
+	}
+
+	// Diamonds are split along their edges, which the distance scaled to the tile size
+	// follows exactly. Hexagons use the plain distance, as Tiled does.
+	diamond := l.sideX == 0 && l.sideY == 0
+	nearest, best := 0, float32(math.Inf(1))
+	for i, c := range centers {
+		dx, dy := c[0]-relX, c[1]-relY
+		var dist float32
+		if diamond {
+			dist = abs32(dx)/l.tileW + abs32(dy)/l.tileH
+		} else {
+			dist = dx*dx + dy*dy
+		}
+		if dist < best {
+			nearest, best = i, dist
+		}
+	}
+	return refX + offsets[nearest][0], refY + offsets[nearest][1]
+}
+
+// hexToPixel returns the map pixel position of a fractional tile coordinate of a staggered or
+// hexagonal map: the position within the bounding box of the cell, at the fraction given by
+// the coordinate.
+func (tm *Map) hexToPixel(x, y float32) (float32, float32) {
+	l := tm.hexLayout()
+	cx, cy := math.Floor(float64(x)), math.Floor(float64(y))
+	px, py := l.cellToPixel(int32(cx), int32(cy))
+	return px + (x-float32(cx))*l.tileW, py + (y-float32(cy))*l.tileH
+}
+
+// pixelToHex returns the fractional tile coordinate of a map pixel position of a staggered or
+// hexagonal map. The whole part is the cell containing the position; the fraction is its
+// place within the cell's bounding box, kept below 1 so it never names a neighbouring cell.
+func (tm *Map) pixelToHex(x, y float32) (float32, float32) {
+	l := tm.hexLayout()
+	cx, cy := l.pixelToCell(x, y)
+	px, py := l.cellToPixel(cx, cy)
+
+	below1 := math.Nextafter32(1, 0)
+	fx := min(max((x-px)/l.tileW, 0), below1)
+	fy := min(max((y-py)/l.tileH, 0), below1)
+	return float32(cx) + fx, float32(cy) + fy
+}
+
+// hexRectToPixel returns the bounding box, in map pixels, of the cells of a range of tile
+// coordinates. The extremes are reached by the first and last two rows and columns, which
+// cover both the shifted and the unshifted ones.
+func (tm *Map) hexRectToPixel(minX, minY, maxX, maxY int32) [4]float32 {
+	l := tm.hexLayout()
+	if maxX <= minX || maxY <= minY {
+		px, py := l.cellToPixel(minX, minY)
+		return [4]float32{px, py, px, py}
+	}
+
+	rect := [4]float32{float32(math.Inf(1)), float32(math.Inf(1)), float32(math.Inf(-1)), float32(math.Inf(-1))}
+	for _, x := range [4]int32{minX, min(minX+1, maxX-1), max(maxX-2, minX), maxX - 1} {
+		for _, y := range [4]int32{minY, min(minY+1, maxY-1), max(maxY-2, minY), maxY - 1} {
+			px, py := l.cellToPixel(x, y)
+			rect[0], rect[1] = min(rect[0], px), min(rect[1], py)
+			rect[2], rect[3] = max(rect[2], px+l.tileW), max(rect[3], py+l.tileH)
+		}
+	}
+	return rect
+}
+
+// hexFrameTiles returns the range of tile coordinates whose cells may intersect a pixel
+// rectangle of a staggered or hexagonal map. The range is conservative: cells overlap the
+// bounding boxes of their neighbours, so it includes the cells around the rectangle.
+func (tm *Map) hexFrameTiles(minX, minY, maxX, maxY float32) (float32, float32, float32, float32) {
+	l := tm.hexLayout()
+	if l.staggerX {
+		stepY := l.tileH + l.sideY
+		return (minX - l.tileW) / l.colW, (minY - l.tileH - l.rowH) / stepY, maxX / l.colW, maxY / stepY
+	}
+	stepX := l.tileW + l.sideX
+	return (minX - l.tileW - l.colW) / stepX, (minY - l.tileH) / l.rowH, maxX / stepX, maxY / l.rowH
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,61 @@
+package tilemap
+
+import (
+	"math"
+
+	"github.com/adm87/tiled"
+)
+
+// maxImageRepeats bounds how many times a repeating image layer is tiled along an axis.
+const maxImageRepeats = 4096
+
+// ImageLayerRects appends to dst the world rectangles, as minX, minY, maxX, maxY, where the
+// image of an image layer is drawn for the frame interpolated by alpha (see Frame.Interpolate).
+//
+// The layer's offset and parallax are applied the same way as for tile layers, and repeating
+// layers are tiled to cover the frame. Use Tmx.OrderedLayers to draw image layers between
+// the tile layers returned by the iterator, which follow the order of Tmx.Layers.
+func (tm *Map) ImageLayerRects(dst [][4]float32, layer *tiled.ImageLayer, alpha float32) [][4]float32 {
+	if layer == nil || layer.Image.Width <= 0 || layer.Image.Height <= 0 {
+		return dst
+	}
+
+	minX, minY, maxX, maxY := tm.frame.Interpolate(alpha)
+
+	rect := tm.RectToWorld([4]float32{
+		layer.OffsetX, layer.OffsetY,
+		layer.OffsetX + float32(layer.Image.Width), layer.OffsetY + float32(layer.Image.Height),
+	})
+	shiftX := minX * (1 - layer.ParallaxX)
+	shiftY := minY * (1 - layer.ParallaxY)
+	rect = [4]float32{rect[0] + shiftX, rect[1] + shiftY, rect[2] + shiftX, rect[3] + shiftY}
+
+	w, h := rect[2]-rect[0], rect[3]-rect[1]
+	startX, countX := repeatSpan(rect[0], w, minX, maxX, layer.RepeatX)
+	startY, countY := repeatSpan(rect[1], h, minY, maxY, layer.RepeatY)
+
+	for j := 0; j < countY; j++ {
+		for i := 0; i < countX; i++ {
+			x := startX + float32(i)*w
+			y := startY + float32(j)*h
+			dst = append(dst, [4]float32{x, y, x + w, y + h})
+		}
+	}
+	return dst
+}
+
+// repeatSpan returns the first position and the number of copies of an image of a given size
+// placed at pos needed to cover lo..hi. Without repeat, the image is placed once.
+func repeatSpan(pos, size, lo, hi float32, repeat bool) (float32, int) {
+	if !repeat || !(size > 0) || !(hi > lo) {
+		return pos, 1
+	}
+
+	first := math.Floor(float64(lo-pos) / float64(size))
+	last := math.Ceil(float64(hi-pos) / float64(size))
+	count := min(last-first, maxImageRepeats)
+	if !(count > 0) {
+		return pos, 1
+	}
+	return pos + float32(first)*size, int(count)
+}
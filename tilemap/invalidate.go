@@ -0,0 +1,68 @@
+package tilemap
+
+import "slices"
+
+// ====================== Invalidation =====================
+
+// Edits and chunks finishing their async decode only change the tiles of one layer, so
+// instead of rebuilding the whole buffered region, the layers they touch are marked stale
+// and rebuilt on their own by the next BufferFrame, their tiles spliced into the cache.
+// Occlusion culling links the tiles of every layer, and the buffer budget rebuilds into a
+// staging cache, so both fall back to rebuilding the whole region.
+
+// invalidateTile marks the layer of a tile stale if the tile is part of the buffered region.
+func (tm *Map) invalidateTile(layer int, x, y int32) {
+	r := &tm.cachedRegion
+	if x >= r.MinX && x < r.MaxX && y >= r.MinY && y < r.MaxY {
+		tm.invalidateLayer(layer)
+	}
+}
+
+// invalidateChunk marks the layer of a chunk stale if the chunk intersects the buffered region.
+func (tm *Map) invalidateChunk(chunk *Chunk) {
+	if !chunk.intersects(tm.cachedRegion) {
+		return
+	}
+	if layer := slices.Index(tm.layers, chunk.layer); layer >= 0 {
+		tm.invalidateLayer(layer)
+	}
+}
+
+// invalidateLayer marks a layer to be rebuilt within the buffered region.
+func (tm *Map) invalidateLayer(layer int) {
+	if tm.opaque != nil || tm.budget.enabled() || len(tm.cachedPositions) != len(tm.layers)+1 {
+		tm.dirty = true
+		return
+	}
+	if !slices.Contains(tm.staleLayers, layer) {
+		tm.staleLayers = append(tm.staleLayers, layer)
+	}
+}
+
+// rebuildStaleLayers rebuilds the tiles of the stale layers within the buffered region and
+// splices them into the cache in place of the old ones.
+func (tm *Map) rebuildStaleLayers() {
+	if len(tm.staleLayers) == 0 {
+		return
+	}
+	tm.cacheGen++
+
+	region := tm.cachedRegion
+	for _, layer := range tm.staleLayers {
+		tiles := tm.layerScratch[:0]
+		if tm.layers[layer].visibility > 0 {
+			for _, chunk := range tm.queryChunks(layer, region) {
+				tiles = tm.appendChunkTiles(tiles, layer, chunk, region)
+			}
+			tm.sortLayer(tiles)
+		}
+
+		start, end := tm.cachedPositions[layer], tm.cachedPositions[layer+1]
+		tm.cachedData = slices.Replace(tm.cachedData, start, end, tiles...)
+		for i := layer + 1; i < len(tm.cachedPositions); i++ {
+			tm.cachedPositions[i] += len(tiles) - (end - start)
+		}
+		tm.layerScratch = tiles
+	}
+	tm.staleLayers = tm.staleLayers[:0]
+}
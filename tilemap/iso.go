@@ -0,0 +1,80 @@
+package tilemap
+
+import (
+	"slices"
+)
+
+// ====================== Isometric =====================
+
+// IsoToWorld projects a (fractional) tile coordinate of an isometric map to world space, laid
+// out the way Tiled draws isometric maps: tile x runs down-right, tile y runs down-left, and
+// the left corner of the map is at x 0. It returns the top corner of the tile's diamond.
+//
+// Buffered tiles of isometric maps are already positioned this way: their X and Y are the
+// top-left corner of the bounding box of their diamond. TileToWorld does the same for any
+// orientation.
+func (tm *Map) IsoToWorld(tileX, tileY float32) (x, y float32) {
+	return tm.ToWorld(tm.isoToPixel(tileX, tileY))
+}
+
+// IsoFromWorld converts a world position to the fractional tile coordinate of an isometric
+// map. It is the inverse of IsoToWorld.
+func (tm *Map) IsoFromWorld(x, y float32) (tileX, tileY float32) {
+	return tm.pixelToIso(tm.FromWorld(x, y))
+}
+
+// IsoFrame returns the frame to buffer so that every tile of an isometric map visible in a
+// world view rectangle (minX, minY, maxX, maxY) is buffered. The frame of an isometric map
+// selects the tiles whose diamonds intersect it, but tiles taller than the grid are drawn
+// above their diamond and reach into the view from below it, so margin extends the view
+// downwards on screen by that many world units: towards +Y by default, or towards -Y with
+// SetYUp.
+func (tm *Map) IsoFrame(view [4]float32, margin float32) [4]float32 {
+	if tm.yUp {
+		return [4]float32{view[0], view[1] - margin, view[2], view[3]}
+	}
+	return [4]float32{view[0], view[1], view[2], view[3] + margin}
+}
+
+// isoToPixel returns the map pixel position of the top corner of a (fractional) tile
+// coordinate's diamond.
+func (tm *Map) isoToPixel(tileX, tileY float32) (x, y float32) {
+	halfW := float32(tm.Tmx.TileWidth) / 2
+	halfH := float32(tm.Tmx.TileHeight) / 2
+	originX := float32(tm.Tmx.Height) * halfW
+
+	return originX + (tileX-tileY)*halfW, (tileX + tileY) * halfH
+}
+
+// pixelToIso is the inverse of isoToPixel.
+func (tm *Map) pixelToIso(x, y float32) (tileX, tileY float32) {
+	halfW := float32(tm.Tmx.TileWidth) / 2
+	halfH := float32(tm.Tmx.TileHeight) / 2
+	originX := float32(tm.Tmx.Height) * halfW
+
+	u := (x - originX) / halfW // tileX - tileY
+	v := y / halfH             // tileX + tileY
+	return (u + v) / 2, (v - u) / 2
+}
+
+// TileCoord returns the tile coordinate of a buffered tile.
+func (tm *Map) TileCoord(tile *Data) (x, y int32) {
+	// Sample the center of the cell, so the result doesn't depend on the axis direction.
+	halfW := float32(tm.Tmx.TileWidth) / tm.ppu() / 2
+	halfH := float32(tm.Tmx.TileHeight) / tm.ppu() / 2
+	return tm.worldToTile(tile.X+halfW, tile.Y+halfH)
+}
+
+// SortIsometric sorts the buffered tiles of a layer into isometric draw order, back to front,
+// so tiles overlapping their neighbours are drawn over the ones behind them. The iterator
+// returns tiles chunk by chunk, which only suits orthogonal maps.
+func (tm *Map) SortIsometric(tiles []Data) {
+	slices.SortFunc(tiles, func(a, b Data) int {
+		ax, ay := tm.TileCoord(&a)
+		bx, by := tm.TileCoord(&b)
+		if d := (ax + ay) - (bx + by); d != 0 {
+			return int(d)
+		}
+		return int(ax - bx)
+	})
+}
@@ -0,0 +1,62 @@
+package tilemap
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adm87/tiled"
+)
+
+func TestIsoFrameMargin(t *testing.T) {
+	// A 4x4 isometric map of 32x16 cells filled with 32x48 tiles, which are drawn up to 32
+	// pixels above their diamond.
+	src := `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="isometric" renderorder="right-down" width="4" height="4" tilewidth="32" tileheight="16" infinite="0">
+ <tileset firstgid="1" name="tall" tilewidth="32" tileheight="48" tilecount="1" columns="1">
+  <image source="tall.png" width="32" height="48"/>
+ </tileset>
+ <layer id="1" name="Ground" width="4" height="4">
+  <data encoding="csv">` + strings.Repeat("1,", 15) + `1</data>
+ </layer>
+</map>`
+
+	loader := tiled.NewLoaderFS(fstest.MapFS{"map.tmx": {Data: []byte(src)}})
+	tmx, err := loader.LoadTmx("map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, yUp := range []bool{false, true} {
+		tm := NewMap()
+		if err := tm.SetTmx(tmx); err != nil {
+			t.Fatal(err)
+		}
+		tm.SetYUp(yUp)
+
+		// The diamond of tile (3, 3) spans pixels 48..64 vertically, its image 16..64. The view
+		// only covers the image.
+		view := tm.RectToWorld([4]float32{56, 20, 72, 30})
+
+		for _, tt := range []struct {
+			margin float32
+			want   bool
+		}{{0, false}, {32, true}} {
+			tm.Frame().Set(tm.IsoFrame(view, tt.margin))
+			if err := tm.BufferFrame(); err != nil {
+				t.Fatal(err)
+			}
+
+			itr := tm.Itr()
+			found := false
+			for _, tile := range itr.Layer(0) {
+				if x, y := tm.TileCoord(&tile); x == 3 && y == 3 {
+					found = true
+				}
+			}
+			if found != tt.want {
+				t.Errorf("y up %v, margin %v: tile (3, 3) buffered = %v, want %v", yUp, tt.margin, found, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package tilemap
+
+import "iter"
+
+// ====================== Iterators =====================
+
+// All returns an iterator over the layers left in the iterator, yielding the index of each
+// layer in Tmx.Layers and its tiles. It advances the iterator, so the accessors, e.g.
+// Opacity, describe the yielded layer within the loop.
+//
+//	itr := tm.Itr()
+//	for layer, tiles := range itr.All() {
+//		alpha := itr.Visibility() * itr.Opacity()
+func (it *Iterator) All() iter.Seq2[int, []Data] {
+	return func(yield func(int, []Data) bool) {
+		for tiles := it.Next(); tiles != nil; tiles = it.Next() {
+			if !yield(it.index-1, tiles) {
+				return
+			}
+		}
+	}
+}
+
+// Tiles returns an iterator over the buffered tiles of every layer, layer by layer, yielding
+// the index of each tile's layer in Tmx.Layers and the tile. Like the iterator returned by
+// Itr, it borrows the buffered tiles, which the next BufferFrame may rewrite.
+//
+//	for layer, tile := range tm.Tiles() {
+func (tm *Map) Tiles() iter.Seq2[int, Data] {
+	return func(yield func(int, Data) bool) {
+		itr := tm.Itr()
+		for layer, tiles := range itr.All() {
+			for i := range tiles {
+				if !yield(layer, tiles[i]) {
+					return
+				}
+			}
+		}
+	}
+}
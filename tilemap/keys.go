@@ -0,0 +1,36 @@
+package tilemap
+
+import "github.com/adm87/tiled/internal/hash"
+
+// ====================== Keys =====================
+
+// NewTileKey returns the map key of a tile coordinate. The map keys its own per-tile state
+// this way, so systems keeping their own per-tile storage (user data, lighting) can share
+// keys with it. Every int32 coordinate has its own key, negative ones included.
+func NewTileKey(x, y int32) uint64 {
+	return hash.EncodeGridKey(x, y)
+}
+
+// DecodeTileKey returns the tile coordinate a key was made from.
+func DecodeTileKey(key uint64) (x, y int32) {
+	return hash.DecodeGridKey(key)
+}
+
+// ChunkCoord returns the coordinate of the chunk containing a tile, for chunks of size tiles
+// per side aligned to the origin. Tiles left of or above the origin fall into negative chunks.
+func ChunkCoord(x, y, size int32) (cx, cy int32) {
+	return floorDivInt(x, size), floorDivInt(y, size)
+}
+
+// NewChunkKey returns the map key of the chunk containing a tile, for chunks of size tiles
+// per side. Fog of war and baked dirty regions are keyed this way.
+func NewChunkKey(x, y, size int32) uint64 {
+	return NewTileKey(ChunkCoord(x, y, size))
+}
+
+// DecodeChunkKey returns the region of tiles covered by the chunk a key was made from, for
+// chunks of size tiles per side.
+func DecodeChunkKey(key uint64, size int32) Region {
+	cx, cy := DecodeTileKey(key)
+	return Region{MinX: cx * size, MinY: cy * size, MaxX: (cx + 1) * size, MaxY: (cy + 1) * size}
+}
@@ -0,0 +1,67 @@
+package tilemap
+
+// TileSource locates a tile in the Tmx data it was read from.
+type TileSource struct {
+	Layer   int   // index of the layer in Tmx.Layers
+	LayerID int32 // ID of the layer in Tiled
+	X, Y    int32 // tile coordinate
+
+	// Chunk is the index of the chunk holding the tile in the layer's Data.Chunks, or -1 for
+	// finite maps, which store the layer in Data.Content. ChunkX and ChunkY are its origin
+	// in tile coordinates, 0 for finite maps.
+	Chunk          int
+	ChunkX, ChunkY int32
+
+	// Cell is the index of the tile in the decoded content of its chunk, or of the layer
+	// for finite maps, row by row.
+	Cell int
+}
+
+// ====================== Locate =====================
+
+// Locate returns where a buffered tile, or one returned by TileAt, is stored in the Tmx data,
+// so editors and debuggers can jump from a drawn tile back to the authoring data. The tile's
+// coordinate is recovered from its position, so the tile must come from the map's current
+// frame and coordinate system. It returns ErrLayerNotFound for tiles of another map and
+// ErrTileNotFound when no chunk holds the tile's coordinate.
+func (tm *Map) Locate(tile *Data) (TileSource, error) {
+	if tm.Tmx == nil {
+		return TileSource{}, ErrNoTmxData
+	}
+
+	layer := int(tile.LayerIdx)
+	if layer < 0 || layer >= len(tm.layers) {
+		return TileSource{}, ErrLayerNotFound
+	}
+
+	// Positions include the layer offset, which the cell doesn't.
+	cell := *tile
+	offsetX, offsetY := tm.layerWorldOffset(layer)
+	cell.X -= offsetX
+	cell.Y -= offsetY
+	x, y := tm.TileCoord(&cell)
+
+	chunk, err := tm.chunkAt(layer, x, y)
+	if err != nil {
+		return TileSource{}, err
+	}
+
+	src := TileSource{
+		Layer:   layer,
+		LayerID: tm.Tmx.Layers[layer].ID,
+		X:       x,
+		Y:       y,
+		Chunk:   -1,
+		Cell:    int(chunk.index(x, y)),
+	}
+	if tm.Tmx.IsInfinite() {
+		src.ChunkX, src.ChunkY = chunk.x, chunk.y
+		for i, c := range tm.Tmx.Layers[layer].Data.Chunks {
+			if c.X == chunk.x && c.Y == chunk.y {
+				src.Chunk = i
+				break
+			}
+		}
+	}
+	return src, nil
+}
@@ -0,0 +1,86 @@
+package tilemap
+
+import "math"
+
+// ====================== LOD =====================
+
+// LOD thins out the buffered tiles when the map is viewed zoomed far out, so huge maps don't
+// emit millions of tiles that each cover a fraction of a pixel.
+//
+// Below MinZoom, only every Nth tile along each axis is buffered, with N the LOD step: the
+// smallest whole number for which the zoom times N reaches MinZoom, capped at MaxStep.
+// Selected tiles sit on a fixed grid of tile coordinates, so they don't flicker while panning.
+// Renderers scale each tile by the step to cover the cells that were skipped.
+type LOD struct {
+	MinZoom float32 // zoom below which tiles are skipped; 0 disables LOD
+	MaxStep int32   // upper bound for the step; 0 means no bound
+}
+
+// SetLOD sets the level of detail applied by BufferFrame, based on the frame's zoom.
+func (tm *Map) SetLOD(lod LOD) {
+	tm.lod = lod
+}
+
+// LOD returns the level of detail settings set by SetLOD.
+func (tm *Map) LOD() LOD {
+	return tm.lod
+}
+
+// LODStep returns the step the buffered frame was built with: 1 when every tile is
+// buffered, N when only every Nth tile along each axis is.
+func (tm *Map) LODStep() int32 {
+	return max(tm.lodStep, 1)
+}
+
+// step returns the LOD step for a zoom level.
+func (lod LOD) step(zoom float32) int32 {
+	if lod.MinZoom <= 0 || !(zoom > 0) || zoom >= lod.MinZoom {
+		return 1
+	}
+
+	step := math.Ceil(float64(lod.MinZoom) / float64(zoom))
+	if lod.MaxStep > 0 {
+		step = min(step, float64(lod.MaxStep))
+	}
+	return int32(min(step, maxLODStep))
+}
+
+// maxLODStep keeps tile coordinates stepped by the LOD from overflowing.
+const maxLODStep = 1 << 16
+
+// updateLOD picks the LOD step for the frame's zoom, invalidating the cache if it changed.
+func (tm *Map) updateLOD() {
+	step := tm.lod.step(tm.frame.Zoom())
+	if step != tm.LODStep() {
+		tm.lodStep = step
+		tm.dirty = true
+	}
+}
+
+// lodStart returns the first coordinate at or after v on the LOD grid.
+func lodStart(v, step int32) int32 {
+	if step <= 1 {
+		return v
+	}
+	return -floorDivInt(-v, step) * step
+}
+
+// ====================== Zoom =====================
+
+// SetZoom sets the zoom the frame is viewed at: the number of screen pixels per world unit.
+// It only drives the level of detail; the frame bounds are unaffected. A zero or negative
+// zoom resets it to 1.
+func (f *Frame) SetZoom(zoom float32) {
+	if !(zoom > 0) {
+		zoom = 0
+	}
+	f.zoom = zoom
+}
+
+// Zoom returns the zoom set by SetZoom, 1 by default.
+func (f *Frame) Zoom() float32 {
+	if f.zoom == 0 {
+		return 1
+	}
+	return f.zoom
+}
@@ -2,11 +2,13 @@ package tilemap
 
 import (
 	"errors"
+	"image/color"
 	"math"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/adm87/tiled"
-	"github.com/adm87/utilities/hash"
 )
 
 var (
@@ -15,10 +17,13 @@ var (
 	ErrTilesetNotFound = errors.New("tileset not found")
 	ErrTileNotFound    = errors.New("tile not found")
 	ErrTilesetSource   = errors.New("tileset source is empty")
+	ErrLayerNotFound   = errors.New("layer not found")
+	ErrRegionTooLarge  = errors.New("buffered region exceeds the tile cap")
 )
 
 const (
-	DefaultChunkSize int32 = 16 // in tiles
+	DefaultChunkSize        int32 = 16         // in tiles
+	DefaultMaterialProperty       = "material" // layer property exposed as Iterator.Material
 )
 
 // ====================== Region =====================
@@ -29,6 +34,11 @@ type Region struct {
 	MaxX, MaxY int32
 }
 
+// IsEmpty reports whether the region covers no tiles.
+func (r *Region) IsEmpty() bool {
+	return r.MinX >= r.MaxX || r.MinY >= r.MaxY
+}
+
 func (r *Region) Equals(other *Region) bool {
 	return r.MinX == other.MinX &&
 		r.MinY == other.MinY &&
@@ -38,11 +48,20 @@ func (r *Region) Equals(other *Region) bool {
 
 // ====================== Data =====================
 
+// Data is the tile record emitted by the map. It is the only tile record of the module;
+// the integer TileData of earlier releases maps onto it through Pixel.
 type Data struct {
 	X, Y     float32        // World position
 	TileID   uint32         // Tile ID
 	TsIdx    int            // Tileset index
 	FlipFlag tiled.FlipFlag // Flip flags
+	LayerIdx int32          // Index of the layer in Tmx.Layers
+}
+
+// Pixel returns the world position rounded to whole pixels, for renderers that draw on an
+// integer grid. With the default coordinate system, tile positions are already whole.
+func (d *Data) Pixel() (x, y int32) {
+	return int32(math.Round(float64(d.X))), int32(math.Round(float64(d.Y)))
 }
 
 // ====================== Chunk =====================
@@ -50,8 +69,7 @@ type Data struct {
 var chunkPool = sync.Pool{
 	New: func() any {
 		return &Chunk{
-			data:  make([]uint32, 0),
-			tiles: make(map[uint64]Data),
+			data: make([]uint32, 0),
 		}
 	},
 }
@@ -64,44 +82,167 @@ type Chunk struct {
 	compression tiled.Compression
 	raw         string
 	data        []uint32
-	tiles       map[uint64]Data
+	packed      []uint16
+	palette     *palette // layer palette when packed, nil otherwise
+	layer       *Layer
+	memo        []Data  // tiles memoized by cell index, allocated by memoizeChunk
+	memoState   []uint8 // memoUnknown, memoEmpty or memoTile per cell of memo
+	content     Region  // bounds of non-empty cells, in tile coordinates
+	stale       bool    // content bounds need to be recomputed
+	modified    bool    // cells were edited since decoding
+	unsynced    bool    // cells were edited since the last SyncTmx
+	summary     ChunkColor
+	summarized  bool // summary is up to date
+
+	emit     []Data  // resolved tiles of the chunk, row by row, see chunkTiles
+	emitCols []int32 // column of each tile of emit
+	emitRows []int32 // index in emit of the first tile of each row, then the end of the last
+	emitted  bool    // emit is up to date
 }
 
 func (c *Chunk) Flush() {
-	clear(c.tiles)
+	clear(c.memoState)
+	c.emitted = false
+}
+
+// decode decodes the raw chunk content the first time it is needed.
+func (c *Chunk) decode() error {
+	if c.isDecoded {
+		return nil
+	}
+
+	data, err := tiled.DecodeContent(c.raw, c.encoding, c.compression)
+	if err != nil {
+		return err
+	}
+
+	c.attach(data)
+	return nil
+}
+
+// contains reports whether the tile coordinate lies within the chunk.
+func (c *Chunk) contains(x, y int32) bool {
+	return x >= c.x && x < c.x+c.w && y >= c.y && y < c.y+c.h
+}
+
+// index returns the position of a tile coordinate in the chunk's data.
+func (c *Chunk) index(x, y int32) int32 {
+	return (y-c.y)*c.w + (x - c.x)
+}
+
+// reset prepares the chunk for reuse from the pool.
+func (c *Chunk) reset() {
+	c.memo = c.memo[:0]
+	c.memoState = c.memoState[:0]
+	c.isDecoded = false
+	c.raw = ""
+	c.data = c.data[:0]
+	c.packed = c.packed[:0]
+	c.palette = nil
+	c.layer = nil
+	c.content = Region{}
+	c.stale = false
+	c.modified = false
+	c.unsynced = false
+	c.summarized = false
+	c.emit = c.emit[:0]
+	c.emitCols = c.emitCols[:0]
+	c.emitRows = c.emitRows[:0]
+	c.emitted = false
 }
 
 // ====================== Layer =====================
 
 var layerPool = sync.Pool{
 	New: func() any {
-		return &Layer{
-			Grid: hash.NewGrid[*Chunk](0, 0),
-		}
+		return &Layer{}
 	},
 }
 
+// Layer holds the chunks of a tile layer, indexed by their position in tile coordinates.
 type Layer struct {
-	*hash.Grid[*Chunk]
+	visibility float32 // current visibility, 0..1
+	target     float32 // visibility being transitioned to
+	rate       float32 // visibility change per second
+
+	parallaxX, parallaxY float32    // scroll factor relative to the frame, 1 = none
+	offsetX, offsetY     float32    // static offset in map pixels, applied to tile positions
+	opacity              float32    // layer opacity, 0..1
+	tint                 color.RGBA // tint color, white for none
+
+	class    string // layer class
+	material string // value of the map's material property
+
+	palette *palette // shared by packed chunks, nil when packing is disabled
+
+	chunks         []*Chunk          // every chunk, row-major
+	index          map[uint64]*Chunk // chunks by grid cell
+	chunkW, chunkH int32             // grid cell size in tiles, 0 if chunks are irregular
+	scratch        []*Chunk          // reused query results
+	dirty          map[uint64]Region // bake chunks mutated since the last ConsumeDirtyRects
+}
+
+// Visibility returns the layer's current visibility transition value in the range 0..1.
+func (l *Layer) Visibility() float32 {
+	return l.visibility
+}
+
+func (l *Layer) setVisible(visible bool) {
+	l.target = 0
+	if visible {
+		l.target = 1
+	}
+	l.visibility = l.target
+	l.rate = 0
+}
+
+// step advances the visibility transition by dt seconds.
+// It returns true when the layer starts or stops emitting tiles.
+func (l *Layer) step(dt float32) bool {
+	if l.visibility == l.target {
+		return false
+	}
+
+	wasEmitting := l.visibility > 0
+	if l.visibility < l.target {
+		l.visibility = min(l.target, l.visibility+l.rate*dt)
+	} else {
+		l.visibility = max(l.target, l.visibility-l.rate*dt)
+	}
+	return wasEmitting != (l.visibility > 0)
 }
 
 func (l *Layer) Flush() {
-	if l.Grid != nil {
-		l.Grid.ForEach(func(chunk *Chunk) {
-			chunk.Flush()
-			chunkPool.Put(chunk)
-		})
-		l.Grid.Clear()
+	for _, chunk := range l.chunks {
+		chunk.reset()
+		chunkPool.Put(chunk)
 	}
+	l.resetChunks()
 }
 
 // ====================== Iterator =====================
 
 // Iterator provides a way to iterate over tiles in the visible frame of a tilemap.
+//
+// The iterator borrows the map's buffered tiles instead of copying them, so taking and
+// walking one doesn't allocate. The tiles are rewritten by the next BufferFrame that
+// rebuilds them; Valid reports whether that happened.
 type Iterator struct {
-	tiles  []Data
-	layers []int
-	index  int
+	tiles    []Data
+	layers   []int
+	index    int
+	meta     []*Layer   // layer state the accessors read
+	tmx      *tiled.Tmx // map the layers belong to
+	cacheGen *uint64    // generation of the map's buffered tiles, nil for snapshots
+	gen      uint64     // generation the iterator was taken at
+}
+
+// Valid reports whether the tiles the iterator borrows are still the map's buffered tiles.
+// It turns false once BufferFrame, an edit followed by BufferFrame, SetTmx or Release rewrites
+// them; tiles returned by Next before then must not be used anymore. Snapshot iterators are
+// always valid.
+func (it *Iterator) Valid() bool {
+	return it.cacheGen == nil || *it.cacheGen == it.gen
 }
 
 func (it *Iterator) Next() []Data {
@@ -113,14 +254,117 @@ func (it *Iterator) Next() []Data {
 	end := it.layers[it.index+1]
 	it.index++
 
+	// nil marks the end of the layers, so an empty layer must not return it.
+	if it.tiles == nil {
+		return noTiles
+	}
 	return it.tiles[start:end]
 }
 
+// noTiles is returned for layers without tiles when nothing has been buffered.
+var noTiles = []Data{}
+
+// Len returns the number of layers the iterator holds.
+func (it *Iterator) Len() int {
+	return max(len(it.layers)-1, 0)
+}
+
+// Layer returns the tiles of layer i without moving the iterator, or nil when i is out of
+// range. Renderers drawing layers in passes can take the layers each pass needs directly.
+func (it *Iterator) Layer(i int) []Data {
+	if i < 0 || i >= it.Len() {
+		return nil
+	}
+	if it.tiles == nil {
+		return noTiles
+	}
+	return it.tiles[it.layers[i]:it.layers[i+1]]
+}
+
+// SeekLayer moves the iterator so the next call to Next returns layer i. Seeking past the
+// last layer ends the iteration; negative indices restart it.
+func (it *Iterator) SeekLayer(i int) {
+	it.index = min(max(i, 0), it.Len())
+}
+
+// Visibility returns the visibility transition value (0..1) of the layer last returned by Next.
+// Adapters can use it as an alpha multiplier to fade layers in and out.
+func (it *Iterator) Visibility() float32 {
+	if it.index == 0 || it.index > len(it.meta) {
+		return 1
+	}
+	return it.meta[it.index-1].visibility
+}
+
+// Class returns the class of the layer last returned by Next.
+func (it *Iterator) Class() string {
+	if it.index == 0 || it.index > len(it.meta) {
+		return ""
+	}
+	return it.meta[it.index-1].class
+}
+
+// Material returns the value of the map's material property (see Map.SetMaterialProperty)
+// for the layer last returned by Next. Renderers can use it to select a shader per layer.
+func (it *Iterator) Material() string {
+	if it.index == 0 || it.index > len(it.meta) {
+		return ""
+	}
+	return it.meta[it.index-1].material
+}
+
+// Opacity returns the opacity of the layer last returned by Next, including the opacity of
+// the groups containing it. Renderers multiply it with Visibility for the layer's alpha.
+func (it *Iterator) Opacity() float32 {
+	if it.index == 0 || it.index > len(it.meta) {
+		return 1
+	}
+	return it.meta[it.index-1].opacity
+}
+
+// LayerIndex returns the index in Tmx.Layers of the layer last returned by Next, or -1
+// before the first call.
+func (it *Iterator) LayerIndex() int {
+	if it.index == 0 || it.index > len(it.meta) {
+		return -1
+	}
+	return it.index - 1
+}
+
+// Name returns the name of the layer last returned by Next.
+func (it *Iterator) Name() string {
+	if i := it.LayerIndex(); i >= 0 {
+		return it.tmx.Layers[i].Name
+	}
+	return ""
+}
+
+// Properties returns the custom properties of the layer last returned by Next. Renderers can
+// read per-layer shader parameters or sort keys from them.
+func (it *Iterator) Properties() tiled.Properties {
+	if i := it.LayerIndex(); i >= 0 {
+		return it.tmx.Layers[i].Properties
+	}
+	return nil
+}
+
+// Tint returns the tint color of the layer last returned by Next, multiplied with the tint
+// of the groups containing it. It is white for layers without a tint.
+func (it *Iterator) Tint() color.RGBA {
+	if it.index == 0 || it.index > len(it.meta) {
+		return color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	return it.meta[it.index-1].tint
+}
+
 // ====================== Frame =====================
 
 // Frame represents the visible region of a tilemap in world coordinates.
 type Frame struct {
 	bounds [4]float32
+	prev   [4]float32 // bounds before the last call to Set
+	snap   int32      // chunk size in tiles the buffered region snaps to, 0 = off
+	zoom   float32    // screen pixels per world unit, 0 = 1
 }
 
 func (f *Frame) Width() float32 {
@@ -143,10 +387,50 @@ func (f *Frame) Bounds() (minX, minY, maxX, maxY float32) {
 	return f.bounds[0], f.bounds[1], f.bounds[2], f.bounds[3]
 }
 
+// Set moves the frame, keeping the replaced bounds as the previous frame.
 func (f *Frame) Set(frame [4]float32) {
+	f.prev = f.bounds
 	f.bounds = frame
 }
 
+// Reset moves the frame without keeping history, so no interpolation occurs
+// between the old and new bounds. Use it for camera cuts and teleports.
+func (f *Frame) Reset(frame [4]float32) {
+	f.prev = frame
+	f.bounds = frame
+}
+
+// SetChunkSnap makes the buffered region grow outward to chunk boundaries of the given size
+// in tiles, so the cache only rebuilds when the frame crosses into another chunk.
+// This trades a few more buffered tiles for far fewer rebuilds while scrolling.
+// A size of 0 disables snapping. Use DefaultChunkSize when in doubt.
+func (f *Frame) SetChunkSnap(size int32) {
+	f.snap = max(size, 0)
+}
+
+// ChunkSnap returns the chunk size in tiles the buffered region snaps to, or 0 if disabled.
+func (f *Frame) ChunkSnap() int32 {
+	return f.snap
+}
+
+// Previous returns the bounds the frame had before the last call to Set.
+func (f *Frame) Previous() (minX, minY, maxX, maxY float32) {
+	return f.prev[0], f.prev[1], f.prev[2], f.prev[3]
+}
+
+// Interpolate blends the previous and current bounds, where an alpha of 0 is the
+// previous frame and 1 is the current frame.
+func (f *Frame) Interpolate(alpha float32) (minX, minY, maxX, maxY float32) {
+	return lerp(f.prev[0], f.bounds[0], alpha),
+		lerp(f.prev[1], f.bounds[1], alpha),
+		lerp(f.prev[2], f.bounds[2], alpha),
+		lerp(f.prev[3], f.bounds[3], alpha)
+}
+
+func lerp(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
+
 // ====================== Map =====================
 
 func init() {
@@ -169,13 +453,57 @@ type Map struct {
 	frame Frame // current frame
 
 	cachedRegion    Region
+	extent          Region     // union of the chunks of every layer, in tile coordinates
+	offsetBounds    [4]float32 // min and max layer offsets as minX, minY, maxX, maxY, in map pixels
 	cachedData      []Data
 	cachedPositions []int
+	dirty           bool // forces the next BufferFrame to rebuild the cache
+
+	tilesetSwapFuncs  callbacks[TilesetSwapFunc]
+	tileChangeFuncs   callbacks[TileChangeFunc]
+	objectChangeFuncs callbacks[ObjectChangeFunc]
+	objectLocs        map[int32]objectLoc // objects by ID, built on demand
+	objects           objectIndex         // spatial index of the objects, built by SetTmx
+	generation        uint64              // incremented whenever the layers are rebuilt
+
+	materialProperty string
+	packed           bool
+	originX, originY float32 // world origin in map coordinates
+	yUp              bool
+	pixelsPerUnit    float32 // 0 means 1
+
+	decoder        *asyncDecoder // nil unless async decoding is enabled
+	eagerDecode    bool          // SetTmx decodes every chunk
+	budget         BufferBudget
+	maxRegionTiles int     // 0 means no cap
+	staging        staging // in-progress budgeted rebuild
+	stats          bufferStats
+	animators      map[*tiled.Tsx]*tiled.Animator // built on demand by AnimatedTileID
+	lod            LOD
+	lodStep        int32 // step of the buffered frame, 0 = 1
+	tileColorFunc  TileColorFunc
+	chunkColors    []ChunkColor                    // reused by ChunkColors
+	colorCounts    map[color.RGBA]int              // scratch for dominant colors
+	collisions     map[collisionKey][]tiled.Object // flipped collision shapes
+	opaque         OpaqueFunc                      // occlusion culling, nil when disabled
+	cover          map[uint64]int                  // highest opaque layer per cell of the region
+	opaqueGIDs     map[uint32]bool                 // memoized opacity per GID
+	fallback       bool                            // buffer placeholders for tiles that can't be drawn
+	drawable       map[uint32]bool                 // memoized per GID while fallback is enabled
+	bakeSize       int32                           // bake chunk size in tiles, 0 = DefaultChunkSize
+	sortFunc       SortFunc                        // sorts each layer after the render order, nil for none
+	filter         filter                          // predicate tiles are buffered by
+	staleLayers    []int                           // layers to rebuild within the buffered region
+	cacheGen       uint64                          // bumped whenever the buffered tiles are rewritten
+	diffEntered    []Data                          // reused by DiffFrames
+	diffExited     []Data                          // reused by DiffFrames
+	layerScratch   []Data                          // reused by rebuildStaleLayers
 }
 
 func NewMap() *Map {
 	return &Map{
-		Tmx: nil,
+		Tmx:              nil,
+		materialProperty: DefaultMaterialProperty,
 		frame: Frame{
 			bounds: [4]float32{0, 0, 0, 0},
 		},
@@ -187,9 +515,13 @@ func NewMap() *Map {
 // Use this for iterating over tiles in the visible frame.
 func (tm *Map) Itr() Iterator {
 	return Iterator{
-		tiles:  tm.cachedData,
-		layers: tm.cachedPositions,
-		index:  0,
+		tiles:    tm.cachedData,
+		layers:   tm.cachedPositions,
+		index:    0,
+		meta:     tm.layers,
+		tmx:      tm.Tmx,
+		cacheGen: &tm.cacheGen,
+		gen:      tm.cacheGen,
 	}
 }
 
@@ -202,12 +534,20 @@ func (tm *Map) Frame() *Frame {
 	return &tm.frame
 }
 
+// Region returns the region of tile coordinates the last BufferFrame buffered.
+func (tm *Map) Region() Region {
+	return tm.cachedRegion
+}
+
 // Flush clears all layers and their chunks from the map.
 func (tm *Map) Flush() {
 	tm.flush()
 }
 
 // BufferFrame buffers tile data for current frame.
+//
+// A frame with zero or negative area is not an error; it buffers no tiles, and the iterator
+// returns an empty slice for every layer. Errors are reserved for maps that can't be buffered.
 func (tm *Map) BufferFrame() error {
 	if tm.Tmx == nil {
 		return ErrNoTmxData
@@ -217,17 +557,41 @@ func (tm *Map) BufferFrame() error {
 		return ErrInvalidTmxData
 	}
 
+	tm.collectDecodes()
+	tm.updateLOD()
+	tm.checkFilter()
+
 	region := tm.computeTileRegion()
-	if region.Equals(&tm.cachedRegion) {
+	if err := tm.checkRegionCap(region); err != nil {
+		return err
+	}
+	if !tm.dirty && region.Equals(&tm.cachedRegion) && !tm.staging.pending(region) {
+		tm.staging.active = false
+		tm.rebuildStaleLayers()
 		return nil
 	}
 
+	start := time.Now()
+	tm.stats.chunks = 0
+
+	err := tm.buffer(region)
+	tm.stats.record(time.Since(start), region)
+	return err
+}
+
+// buffer rebuilds the cache for a region, within the buffer budget if one is set.
+func (tm *Map) buffer(region Region) error {
+	if tm.budget.enabled() {
+		return tm.bufferBudgeted(region)
+	}
+
 	width := region.MaxX - region.MinX
 	height := region.MaxY - region.MinY
 
-	size := int(width*height) * len(tm.layers)
+	size := int(max(width*height, 0)) * len(tm.layers)
 	if cap(tm.cachedData) < size {
-		tm.cachedData = make([]Data, 0, size)
+		dataArena.put(tm.cachedData)
+		tm.cachedData = dataArena.get(size)
 	}
 
 	return tm.updateCache(region)
@@ -236,14 +600,142 @@ func (tm *Map) BufferFrame() error {
 // SetTmx sets the Tmx data for the map and builds the underlying structures of the map.
 // Setting a new Tmx will clear any existing layers data, but will not reset the frame.
 func (tm *Map) SetTmx(tmx *tiled.Tmx) error {
-	if tmx == nil || len(tmx.Layers) == 0 {
+	if tmx == nil || len(tmx.Layers) == 0 || tmx.TileWidth <= 0 || tmx.TileHeight <= 0 {
 		return ErrInvalidTmxData
 	}
 
 	tm.flush()
 	tm.Tmx = tmx
+	tm.buildObjectIndex()
+
+	if err := tm.buildLayers(); err != nil {
+		return err
+	}
+	if tm.eagerDecode {
+		return tm.DecodeAllChunks()
+	}
+	return nil
+}
+
+// SetLayerVisible shows or hides a layer, transitioning its visibility over the given duration.
+// A zero duration applies the change immediately.
+//
+// The transition is advanced by Update and exposed through Iterator.Visibility.
+func (tm *Map) SetLayerVisible(index int, visible bool, duration time.Duration) error {
+	if index < 0 || index >= len(tm.layers) {
+		return ErrLayerNotFound
+	}
+
+	layer := tm.layers[index]
+	if duration <= 0 {
+		wasEmitting := layer.visibility > 0
+		layer.setVisible(visible)
+		tm.dirty = tm.dirty || wasEmitting != (layer.visibility > 0)
+		return nil
+	}
+
+	layer.target = 0
+	if visible {
+		layer.target = 1
+	}
+	layer.rate = float32(1 / duration.Seconds())
+	return nil
+}
+
+// TileChangeFunc is called after the GID of a cell has been changed by a mutation of the map.
+type TileChangeFunc func(layer int, x, y int32, old, new uint32)
+
+// OnTileChanged registers a callback invoked for every cell changed by a mutation of the map.
+// Systems deriving data from tiles use it to update incrementally.
+//
+// It returns a func unregistering the callback, to be called when the system is discarded so
+// the map no longer keeps it alive. Calling it more than once has no effect.
+func (tm *Map) OnTileChanged(fn TileChangeFunc) func() {
+	if fn == nil {
+		return func() {}
+	}
+	return tm.tileChangeFuncs.add(fn)
+}
+
+// callback is a registered callback, identified so it can be unregistered.
+type callback[F any] struct {
+	id uint64
+	fn F
+}
+
+// callbacks is a list of registered callbacks of one kind.
+type callbacks[F any] struct {
+	list []callback[F]
+	seq  uint64 // ID of the last callback registered
+}
 
-	return tm.buildLayers()
+// add registers a callback and returns a func unregistering it.
+func (c *callbacks[F]) add(fn F) func() {
+	c.seq++
+	id := c.seq
+	c.list = append(c.list, callback[F]{id: id, fn: fn})
+	return func() {
+		// A copy, so unregistering from a callback doesn't disturb the running notification.
+		c.list = slices.DeleteFunc(slices.Clone(c.list), func(cb callback[F]) bool {
+			return cb.id == id
+		})
+	}
+}
+
+// Generation returns a counter that changes whenever the map's layers are rebuilt,
+// e.g. by SetTmx. Data derived from the map should be rebuilt when it changes.
+func (tm *Map) Generation() uint64 {
+	return tm.generation
+}
+
+// SetMaterialProperty sets the name of the layer property exposed through Iterator.Material.
+func (tm *Map) SetMaterialProperty(name string) {
+	tm.materialProperty = name
+	if tm.Tmx == nil {
+		return
+	}
+	for i := range tm.layers {
+		tm.layers[i].material = tm.layerMaterial(&tm.Tmx.Layers[i])
+	}
+}
+
+// SetLayerParallax sets the scroll factor of a layer relative to the frame.
+// A factor of 1 scrolls with the frame, smaller factors scroll slower.
+func (tm *Map) SetLayerParallax(index int, factorX, factorY float32) error {
+	if index < 0 || index >= len(tm.layers) {
+		return ErrLayerNotFound
+	}
+	tm.layers[index].parallaxX = factorX
+	tm.layers[index].parallaxY = factorY
+	return nil
+}
+
+// LayerOffset returns the parallax shift of a layer for the frame interpolated by alpha (see
+// Frame.Interpolate). The static offset of the layer is already applied to tile positions.
+//
+// Renderers using fixed timestep interpolation should pass the same alpha they use for
+// the camera, so layer offsets move in lockstep with it.
+func (tm *Map) LayerOffset(index int, alpha float32) (x, y float32, err error) {
+	if index < 0 || index >= len(tm.layers) {
+		return 0, 0, ErrLayerNotFound
+	}
+
+	layer := tm.layers[index]
+	minX, minY, _, _ := tm.frame.Interpolate(alpha)
+
+	x = minX * (1 - layer.parallaxX)
+	y = minY * (1 - layer.parallaxY)
+	return x, y, nil
+}
+
+// Update advances time dependent state of the map, such as layer visibility transitions.
+func (tm *Map) Update(dt time.Duration) {
+	seconds := float32(dt.Seconds())
+	for i := range tm.layers {
+		if tm.layers[i].step(seconds) {
+			tm.dirty = true
+		}
+	}
 }
 
 func (tm *Map) GetTileset(index int) (*tiled.Tileset, error) {
@@ -256,7 +748,7 @@ func (tm *Map) GetTileset(index int) (*tiled.Tileset, error) {
 	}
 
 	ts := &tm.Tmx.Tilesets[index]
-	if ts.Source == "" {
+	if ts.Source == "" && ts.Tsx == nil {
 		return nil, ErrTilesetSource
 	}
 
@@ -273,90 +765,134 @@ func (tm *Map) flush() {
 	tm.layers = tm.layers[:0]
 	tm.cachedData = tm.cachedData[:0]
 	tm.cachedPositions = tm.cachedPositions[:0]
+	tm.staleLayers = tm.staleLayers[:0]
+	tm.cacheGen++
+	tm.extent = Region{}
+	tm.offsetBounds = [4]float32{}
+	tm.dirty = true
+	tm.generation++
+	clear(tm.animators)
+	clear(tm.collisions)
+	clear(tm.drawable)
+	tm.objectLocs = nil
+	tm.resetObjectIndex()
+
+	if tm.decoder != nil {
+		tm.decoder.reset()
+	}
 }
 
 func (tm *Map) buildLayers() error {
+	// Tile layers nested in groups take the display attributes of their groups.
+	flat := make([]tiled.FlatLayer, len(tm.Tmx.Layers))
+	for i := range flat {
+		l := &tm.Tmx.Layers[i]
+		flat[i] = tiled.FlatLayer{
+			OffsetX:   l.OffsetX,
+			OffsetY:   l.OffsetY,
+			Opacity:   l.Opacity,
+			ParallaxX: l.ParallaxX,
+			ParallaxY: l.ParallaxY,
+			Tint:      l.Tint(),
+			Visible:   l.IsVisible(),
+		}
+	}
+	for _, fl := range tm.Tmx.FlattenLayers() {
+		if fl.Kind == tiled.LayerKindTile {
+			flat[fl.Index] = fl
+		}
+	}
+
 	for i := range tm.Tmx.Layers {
 		if tm.Tmx.IsInfinite() {
-			tm.multiChunklayer(&tm.Tmx.Layers[i], tm.Tmx.TileWidth, tm.Tmx.TileHeight)
+			tm.multiChunklayer(&tm.Tmx.Layers[i])
 		} else {
-			tm.singleChunkLayer(&tm.Tmx.Layers[i], tm.Tmx.TileWidth, tm.Tmx.TileHeight)
+			tm.singleChunkLayer(&tm.Tmx.Layers[i])
+		}
+		tm.layers[i].setVisible(flat[i].Visible)
+		tm.layers[i].parallaxX, tm.layers[i].parallaxY = flat[i].ParallaxX, flat[i].ParallaxY
+		tm.layers[i].offsetX, tm.layers[i].offsetY = flat[i].OffsetX, flat[i].OffsetY
+		tm.layers[i].opacity = flat[i].Opacity
+		tm.layers[i].tint = flat[i].Tint
+		tm.offsetBounds = [4]float32{
+			min(tm.offsetBounds[0], flat[i].OffsetX), min(tm.offsetBounds[1], flat[i].OffsetY),
+			max(tm.offsetBounds[2], flat[i].OffsetX), max(tm.offsetBounds[3], flat[i].OffsetY),
+		}
+		tm.layers[i].class = tm.Tmx.Layers[i].Class
+		tm.layers[i].material = tm.layerMaterial(&tm.Tmx.Layers[i])
+		tm.layers[i].setPacked(tm.packed)
+
+		for _, chunk := range tm.layers[i].chunks {
+			tm.extent, _ = unionRegion(tm.extent, !tm.extent.IsEmpty(), Region{
+				MinX: chunk.x,
+				MinY: chunk.y,
+				MaxX: chunk.x + chunk.w,
+				MaxY: chunk.y + chunk.h,
+			})
 		}
 	}
 	return nil
 }
 
-func (tm *Map) multiChunklayer(data *tiled.Layer, tileWidth, tileHeight int32) {
-	width := data.Data.Chunks[0].Width * tileWidth
-	height := data.Data.Chunks[0].Height * tileHeight
+func (tm *Map) layerMaterial(data *tiled.Layer) string {
+	if prop := tiled.PropertyByName(data.Properties, tm.materialProperty); prop != nil {
+		return prop.Value
+	}
+	return ""
+}
 
+func (tm *Map) multiChunklayer(data *tiled.Layer) {
 	layer := layerPool.Get().(*Layer)
-	layer.Grid.Resize(float32(width), float32(height))
 
 	for _, c := range data.Data.Chunks {
 		chunk := chunkPool.Get().(*Chunk)
 		chunk.raw = c.Content
-
-		minX := float32(c.X * tileWidth)
-		minY := float32(c.Y * tileHeight)
-		maxX := float32((c.X + c.Width) * tileWidth)
-		maxY := float32((c.Y + c.Height) * tileHeight)
+		chunk.encoding = data.Data.Encoding
+		chunk.compression = data.Data.Compression
+		chunk.layer = layer
 
 		chunk.x, chunk.y = c.X, c.Y
 		chunk.w, chunk.h = c.Width, c.Height
-
-		layer.Grid.Insert(chunk, [4]float32{minX, minY, maxX, maxY}, hash.NoGridPadding)
+		layer.addChunk(chunk)
 	}
 
+	layer.sortChunks()
 	tm.layers = append(tm.layers, layer)
 }
 
-func (tm *Map) singleChunkLayer(data *tiled.Layer, tileWidth, tileHeight int32) {
-	width := data.Width * tileWidth
-	height := data.Height * tileHeight
-
+func (tm *Map) singleChunkLayer(data *tiled.Layer) {
 	layer := layerPool.Get().(*Layer)
-	layer.Grid.Resize(float32(width), float32(height))
 
 	chunk := chunkPool.Get().(*Chunk)
 	chunk.raw = data.Data.Content
+	chunk.encoding = data.Data.Encoding
+	chunk.compression = data.Data.Compression
+	chunk.layer = layer
 	chunk.x, chunk.y = 0, 0
 	chunk.w, chunk.h = data.Width, data.Height
-
-	layer.Grid.Insert(chunk, [4]float32{0, 0, float32(width), float32(height)}, hash.NoGridPadding)
+	layer.addChunk(chunk)
 	tm.layers = append(tm.layers, layer)
 }
 
 func (tm *Map) updateCache(region Region) error {
 	tm.cachedRegion = region
+	tm.dirty = false
+	tm.staleLayers = tm.staleLayers[:0]
+	tm.cacheGen++
 
 	tm.cachedData = tm.cachedData[:0]
 	tm.cachedPositions = tm.cachedPositions[:0]
+	tm.computeOcclusion(region)
 
 	for i := range tm.layers {
 		tm.cachedPositions = append(tm.cachedPositions, len(tm.cachedData))
 
-		if tm.Tmx.Layers[i].IsVisible() {
-			chunks := tm.layers[i].Grid.Query([4]float32{
-				float32(region.MinX) * float32(tm.Tmx.TileWidth),
-				float32(region.MinY) * float32(tm.Tmx.TileHeight),
-				float32(region.MaxX) * float32(tm.Tmx.TileWidth),
-				float32(region.MaxY) * float32(tm.Tmx.TileHeight),
-			})
+		if tm.layers[i].visibility > 0 {
+			chunks := tm.queryChunks(i, region)
 			for j := range chunks {
-				sX := max(region.MinX, chunks[j].x)
-				sY := max(region.MinY, chunks[j].y)
-				eX := min(region.MaxX, chunks[j].x+chunks[j].w)
-				eY := min(region.MaxY, chunks[j].y+chunks[j].h)
-
-				for x := sX; x < eX; x++ {
-					for y := sY; y < eY; y++ {
-						if tile, ok := tm.getTileFromChunk(chunks[j], x, y); ok {
-							tm.cachedData = append(tm.cachedData, tile)
-						}
-					}
-				}
+				tm.cachedData = tm.appendChunkTiles(tm.cachedData, i, chunks[j], region)
 			}
+			tm.sortLayer(tm.cachedData[tm.cachedPositions[i]:])
 		}
 	}
 
@@ -364,49 +900,257 @@ func (tm *Map) updateCache(region Region) error {
 	return nil
 }
 
+// appendChunkTiles appends the tiles of a chunk of a layer within a region to dst.
+func (tm *Map) appendChunkTiles(dst []Data, layer int, chunk *Chunk, region Region) []Data {
+	if !tm.startChunk(chunk) {
+		return dst
+	}
+	return tm.appendChunkRegion(dst, layer, chunk, region, true)
+}
+
+// startChunk counts a chunk about to be converted. It returns false for chunks that aren't
+// decoded yet with async decoding enabled, after queueing them.
+func (tm *Map) startChunk(chunk *Chunk) bool {
+	if !chunk.isDecoded && tm.decoder != nil {
+		tm.decoder.request(chunk, tm.generation)
+		return false
+	}
+
+	tm.stats.chunks++
+	return true
+}
+
+// appendChunkRegion appends the tiles of a chunk within a region to dst. With cached set, they
+// are copied from the chunk's tile list when possible, building it first if needed.
+func (tm *Map) appendChunkRegion(dst []Data, layer int, chunk *Chunk, region Region, cached bool) []Data {
+	step := tm.LODStep()
+	if cached && step == 1 && len(tm.cover) == 0 && chunk.decode() == nil {
+		return tm.appendCachedTiles(dst, layer, chunk, region)
+	}
+
+	// Layer offsets shift the tiles of the layer, the same as in Tiled.
+	offsetX, offsetY := tm.layerWorldOffset(layer)
+
+	sX := lodStart(max(region.MinX, chunk.x), step)
+	sY := lodStart(max(region.MinY, chunk.y), step)
+	eX := min(region.MaxX, chunk.x+chunk.w)
+	eY := min(region.MaxY, chunk.y+chunk.h)
+
+	for y := sY; y < eY; y += step {
+		for x := sX; x < eX; x += step {
+			if tm.occluded(layer, x, y) {
+				continue
+			}
+			if tile, ok := tm.getTileFromChunk(chunk, x, y); ok {
+				tile.X += offsetX
+				tile.Y += offsetY
+				tile.LayerIdx = int32(layer)
+				if tm.filtered(tile) {
+					continue
+				}
+				dst = append(dst, tile)
+			}
+		}
+	}
+	return dst
+}
+
+// layerWorldOffset returns the static offset of a layer in world units.
+func (tm *Map) layerWorldOffset(layer int) (x, y float32) {
+	l := tm.layers[layer]
+	x = l.offsetX / tm.ppu()
+	y = l.offsetY / tm.ppu()
+	if tm.yUp {
+		y = -y
+	}
+	return x, y
+}
+
 func (tm *Map) getTileFromChunk(chunk *Chunk, x, y int32) (Data, bool) {
 	var zero Data
 
-	if x < chunk.x || x >= chunk.x+chunk.w || y < chunk.y || y >= chunk.y+chunk.h {
+	if !chunk.contains(x, y) {
 		return zero, false
 	}
 
-	if !chunk.isDecoded {
-		data, err := tiled.DecodeContent(chunk.raw, chunk.encoding, chunk.compression)
-		if err != nil {
-			return Data{}, false
+	if err := chunk.decode(); err != nil {
+		return zero, false
+	}
+
+	i := chunk.index(x, y)
+	if i < 0 || i >= chunk.len() {
+		return zero, false
+	}
+
+	memoized := int(i) < len(chunk.memoState)
+	if memoized {
+		switch chunk.memoState[i] {
+		case memoTile:
+			return chunk.memo[i], true
+		case memoEmpty:
+			return zero, false
 		}
-		chunk.data = data
-		chunk.isDecoded = true
 	}
 
-	localx := x - chunk.x
-	localy := y - chunk.y
+	rect := tm.tileRectToWorld(x, y, x+1, y+1)
+	worldX, worldY := rect[0], rect[1]
 
-	key := hash.EncodeGridKey(localx, localy)
-	if tile, ok := chunk.tiles[key]; ok {
-		return tile, true
+	tile, ok := tm.resolveTile(chunk.at(i), worldX, worldY)
+	if memoized {
+		chunk.memo[i] = tile
+		chunk.memoState[i] = memoEmpty
+		if ok {
+			chunk.memoState[i] = memoTile
+		}
 	}
+	return tile, ok
+}
 
-	i := localy*(chunk.w) + localx
-	if i < 0 || i >= int32(len(chunk.data)) {
-		return zero, false
+// chunkAt returns the decoded chunk of a layer containing the tile coordinate.
+func (tm *Map) chunkAt(layer int, x, y int32) (*Chunk, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
 	}
 
-	x = localx * tm.Tmx.TileWidth
-	y = localy * tm.Tmx.TileHeight
+	if layer < 0 || layer >= len(tm.layers) {
+		return nil, ErrLayerNotFound
+	}
 
-	return GetTileData(chunk.data[i], tm.Tmx, float32(x), float32(y))
+	for _, chunk := range tm.queryChunks(layer, Region{MinX: x, MinY: y, MaxX: x + 1, MaxY: y + 1}) {
+		if chunk.contains(x, y) {
+			if err := chunk.decode(); err != nil {
+				return nil, err
+			}
+			return chunk, nil
+		}
+	}
+	return nil, ErrTileNotFound
+}
+
+// gidAt returns the raw GID, including flip flags, stored at a tile coordinate.
+func (tm *Map) gidAt(layer int, x, y int32) (uint32, error) {
+	chunk, err := tm.chunkAt(layer, x, y)
+	if err != nil {
+		return 0, err
+	}
+
+	i := chunk.index(x, y)
+	if i >= chunk.len() {
+		return 0, ErrTileNotFound
+	}
+	return chunk.at(i), nil
+}
+
+// setGID stores a raw GID at a tile coordinate and invalidates anything cached for it.
+// It returns the GID that was replaced.
+func (tm *Map) setGID(layer int, x, y int32, gid uint32) (uint32, error) {
+	chunk, err := tm.chunkAt(layer, x, y)
+	if err != nil {
+		return 0, err
+	}
+
+	i := chunk.index(x, y)
+	if i >= chunk.len() {
+		return 0, ErrTileNotFound
+	}
+
+	old := chunk.at(i)
+	if old == gid {
+		return old, nil
+	}
+
+	chunk.set(i, gid)
+	tm.invalidateTile(layer, x, y)
+	tm.notifyTileChange(layer, x, y, old, gid)
+	return old, nil
+}
+
+func (tm *Map) notifyTileChange(layer int, x, y int32, old, gid uint32) {
+	tm.markDirty(layer, x, y)
+	for _, cb := range tm.tileChangeFuncs.list {
+		cb.fn(layer, x, y, old, gid)
+	}
 }
 
 func (tm *Map) computeTileRegion() Region {
-	minX, minY, maxX, maxY := tm.frame.Bounds()
-	return Region{
-		MinX: int32(math.Floor(float64(minX) / float64(tm.Tmx.TileWidth))),
-		MinY: int32(math.Floor(float64(minY) / float64(tm.Tmx.TileHeight))),
-		MaxX: int32(math.Ceil(float64(maxX) / float64(tm.Tmx.TileWidth))),
-		MaxY: int32(math.Ceil(float64(maxY) / float64(tm.Tmx.TileHeight))),
+	f := tm.frame.bounds
+
+	// NaN and infinite bounds come from broken camera math. They are counted in the buffer
+	// stats; infinite bounds are clamped to the map's extent below and NaN buffers nothing.
+	finite := true
+	for _, v := range f {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			finite = false
+		}
+	}
+	if !finite {
+		tm.stats.invalidFrames++
+	}
+
+	// Zero and negative area frames, e.g. a collapsed camera, buffer nothing.
+	if !(f[2] > f[0]) || !(f[3] > f[1]) {
+		return Region{}
+	}
+
+	// Tiles of offset layers are drawn away from their cell, so the frame is widened by
+	// the layer offsets to buffer every tile that ends up inside it.
+	bounds := tm.RectFromWorld(tm.frame.bounds)
+	minX, minY := bounds[0]-tm.offsetBounds[2], bounds[1]-tm.offsetBounds[3]
+	maxX, maxY := bounds[2]-tm.offsetBounds[0], bounds[3]-tm.offsetBounds[1]
+
+	cellW := float64(tm.Tmx.TileWidth)
+	cellH := float64(tm.Tmx.TileHeight)
+	switch tm.Tmx.Orientation {
+	case tiled.OrientationIsometric:
+		// The frame's tile coordinates span the cells of every diamond it intersects.
+		minX, minY, maxX, maxY = tm.isoFrameTiles(minX, minY, maxX, maxY, finite)
+		cellW, cellH = 1, 1
+	case tiled.OrientationStaggered, tiled.OrientationHexagonal:
+		minX, minY, maxX, maxY = tm.hexFrameTiles(minX, minY, maxX, maxY)
+		cellW, cellH = 1, 1
+	}
+	snap := int32(1)
+	if tm.frame.snap > 0 {
+		snap = tm.frame.snap
+		cellW *= float64(snap)
+		cellH *= float64(snap)
+	}
+
+	// Clamp to the extent of the layers (in snapped cells) before converting to integers, so
+	// huge or infinite frames cover only tiles that exist instead of overflowing.
+	ext := tm.extent
+	loX, hiX := float64(floorDivInt(ext.MinX, snap)), float64(-floorDivInt(-ext.MaxX, snap))
+	loY, hiY := float64(floorDivInt(ext.MinY, snap)), float64(-floorDivInt(-ext.MaxY, snap))
+
+	region := Region{
+		MinX: int32(min(max(math.Floor(float64(minX)/cellW), loX), hiX)) * snap,
+		MinY: int32(min(max(math.Floor(float64(minY)/cellH), loY), hiY)) * snap,
+		MaxX: int32(min(max(math.Ceil(float64(maxX)/cellW), loX), hiX)) * snap,
+		MaxY: int32(min(max(math.Ceil(float64(maxY)/cellH), loY), hiY)) * snap,
+	}
+
+	if region.IsEmpty() {
+		return Region{}
+	}
+	return region
+}
+
+// isoFrameTiles returns the range of fractional tile coordinates covering a pixel rectangle of
+// an isometric map. Infinite rectangles cover every tile.
+func (tm *Map) isoFrameTiles(minX, minY, maxX, maxY float32, finite bool) (float32, float32, float32, float32) {
+	if !finite {
+		inf := float32(math.Inf(1))
+		return -inf, -inf, inf, inf
+	}
+
+	tMinX, tMinY := tm.pixelToIso(minX, minY)
+	tMaxX, tMaxY := tMinX, tMinY
+	for _, c := range [3][2]float32{{maxX, minY}, {minX, maxY}, {maxX, maxY}} {
+		tx, ty := tm.pixelToIso(c[0], c[1])
+		tMinX, tMaxX = min(tMinX, tx), max(tMaxX, tx)
+		tMinY, tMaxY = min(tMinY, ty), max(tMaxY, ty)
 	}
+	return tMinX, tMinY, tMaxX, tMaxY
 }
 
 func GetTileData(gid uint32, tmx *tiled.Tmx, x, y float32) (Data, bool) {
@@ -0,0 +1,86 @@
+package tilemap
+
+import "unsafe"
+
+// Approximate size, in bytes, of the entries of the GID memo maps, including the bucket
+// overhead of Go maps.
+const gidMemoEntrySize = int64(unsafe.Sizeof(uint32(0))+unsafe.Sizeof(false)) + 8
+
+// ====================== Memory Footprint =====================
+
+// LayerFootprint is the memory held by a layer, in bytes. Sizes are estimates from the
+// lengths and capacities of the buffers involved, not measured allocations; they are meant for
+// budgeting levels and comparing layers, e.g. to spot a layer that grew after an editor change.
+type LayerFootprint struct {
+	Name string
+
+	Chunks        int // every chunk of the layer
+	DecodedChunks int // chunks whose content has been decoded
+
+	Raw     int64 // encoded chunk content kept for decoding
+	Decoded int64 // decoded cells, unpacked or packed, and the layer palette
+	Memo    int64 // tiles memoized by Warm
+	Cache   int64 // resolved tiles cached per chunk for buffering
+}
+
+// Total returns the sum of the sizes of the layer.
+func (f LayerFootprint) Total() int64 {
+	return f.Raw + f.Decoded + f.Memo + f.Cache
+}
+
+// MemoryFootprint is the memory held by a map, broken down by layer.
+type MemoryFootprint struct {
+	Layers []LayerFootprint // indexed the same way as Tmx.Layers
+
+	Buffered int64 // buffered tiles, including a budgeted rebuild in progress
+	Scratch  int64 // buffers reused between calls, e.g. by DiffFrames
+	Memo     int64 // memoized tile opacity and drawability
+}
+
+// Total returns the sum of the sizes of the map and its layers.
+func (f MemoryFootprint) Total() int64 {
+	total := f.Buffered + f.Scratch + f.Memo
+	for _, l := range f.Layers {
+		total += l.Total()
+	}
+	return total
+}
+
+// MemoryFootprint estimates the memory held by the map. It walks every chunk, so it is meant
+// for tooling and diagnostics rather than every frame.
+func (tm *Map) MemoryFootprint() MemoryFootprint {
+	var f MemoryFootprint
+	f.Layers = make([]LayerFootprint, len(tm.layers))
+	for i, l := range tm.layers {
+		lf := &f.Layers[i]
+		if tm.Tmx != nil && i < len(tm.Tmx.Layers) {
+			lf.Name = tm.Tmx.Layers[i].Name
+		}
+		lf.Chunks = len(l.chunks)
+
+		if l.palette != nil {
+			lf.Decoded += int64(cap(l.palette.gids))*4 + int64(len(l.palette.index))*gidMemoEntrySize
+		}
+		for _, c := range l.chunks {
+			if c.isDecoded {
+				lf.DecodedChunks++
+			}
+			lf.Raw += int64(len(c.raw))
+			lf.Decoded += int64(cap(c.data))*4 + int64(cap(c.packed))*2
+			lf.Memo += dataSize(cap(c.memo)) + int64(cap(c.memoState))
+			lf.Cache += dataSize(cap(c.emit)) + int64(cap(c.emitCols)+cap(c.emitRows))*4
+		}
+	}
+
+	f.Buffered = dataSize(cap(tm.cachedData)) + int64(cap(tm.cachedPositions))*8 +
+		dataSize(cap(tm.staging.data)) + int64(cap(tm.staging.positions))*8
+	f.Scratch = dataSize(cap(tm.diffEntered)+cap(tm.diffExited)+cap(tm.layerScratch)) +
+		int64(cap(tm.chunkColors))*int64(unsafe.Sizeof(ChunkColor{}))
+	f.Memo = int64(len(tm.opaqueGIDs)+len(tm.drawable)) * gidMemoEntrySize
+	return f
+}
+
+// dataSize returns the size of n tiles.
+func dataSize(n int) int64 {
+	return int64(n) * int64(unsafe.Sizeof(Data{}))
+}
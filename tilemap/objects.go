@@ -0,0 +1,150 @@
+package tilemap
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/adm87/tiled"
+)
+
+var (
+	ErrObjectGroupNotFound = errors.New("object group not found")
+	ErrObjectIDTaken       = errors.New("object ID already in use")
+)
+
+// ====================== Objects =====================
+
+// ObjectChangeFunc is called after an object has been added, moved or removed through the map.
+// Old is a copy of the object before the change, nil when it was added; new is the object in
+// its group, nil when it was removed. Both are only valid during the call.
+type ObjectChangeFunc func(group int, old, new *tiled.Object)
+
+// objectLoc locates an object in Tmx.ObjectGroups.
+type objectLoc struct {
+	group, index int
+}
+
+// OnObjectChanged registers a callback invoked for every object added, moved or removed
+// through the map, so systems tracking objects, e.g. triggers, stay in sync with runtime
+// entities as they do with authored ones. It returns a func unregistering the callback, as
+// OnTileChanged does.
+func (tm *Map) OnObjectChanged(fn ObjectChangeFunc) func() {
+	if fn == nil {
+		return func() {}
+	}
+	return tm.objectChangeFuncs.add(fn)
+}
+
+// ObjectByID returns the object with an ID from any object group, or nil.
+//
+// The object points into its group, and is invalidated when objects are added to or removed
+// from that group.
+func (tm *Map) ObjectByID(id int32) *tiled.Object {
+	loc, ok := tm.objectLocations()[id]
+	if !ok {
+		return nil
+	}
+	return &tm.Tmx.ObjectGroups[loc.group].Objects[loc.index]
+}
+
+// AddObject appends an object to an object group at runtime and returns its ID. Objects
+// without an ID are given Tmx.NextObjectID; objects with one keep it if no other object
+// uses it. Either way, NextObjectID stays above every ID, so saved maps remain valid.
+//
+// The object's position is in map coordinates, like the positions of authored objects.
+// Adding objects may move the objects of the group in memory. Objects added to or removed
+// from the Tmx directly are only tracked by the map after SetTmx.
+func (tm *Map) AddObject(group int, obj tiled.Object) (int32, error) {
+	if tm.Tmx == nil {
+		return 0, ErrNoTmxData
+	}
+	if group < 0 || group >= len(tm.Tmx.ObjectGroups) {
+		return 0, ErrObjectGroupNotFound
+	}
+
+	index := tm.objectLocations()
+	if obj.ID == 0 {
+		obj.ID = max(tm.Tmx.NextObjectID, 1)
+		for _, taken := index[obj.ID]; taken; _, taken = index[obj.ID] {
+			obj.ID++
+		}
+	} else if _, taken := index[obj.ID]; taken {
+		return 0, ErrObjectIDTaken
+	}
+	tm.Tmx.NextObjectID = max(tm.Tmx.NextObjectID, obj.ID+1)
+
+	og := &tm.Tmx.ObjectGroups[group]
+	og.Objects = append(og.Objects, obj)
+	index[obj.ID] = objectLoc{group: group, index: len(og.Objects) - 1}
+
+	tm.objectChanged(group, nil, &og.Objects[len(og.Objects)-1])
+	return obj.ID, nil
+}
+
+// MoveObject moves an object to a world position, taken as its x and y in Tiled's terms: the
+// top-left corner of most objects, and the bottom-left corner of tile objects.
+func (tm *Map) MoveObject(id int32, x, y float32) error {
+	if tm.Tmx == nil {
+		return ErrNoTmxData
+	}
+	loc, ok := tm.objectLocations()[id]
+	if !ok {
+		return ErrObjectNotFound
+	}
+
+	obj := &tm.Tmx.ObjectGroups[loc.group].Objects[loc.index]
+	old := *obj
+	obj.X, obj.Y = tm.pixelToObject(tm.FromWorld(x, y))
+
+	tm.objectChanged(loc.group, &old, obj)
+	return nil
+}
+
+// RemoveObject removes an object from its group. The IDs of removed objects are not reused.
+func (tm *Map) RemoveObject(id int32) error {
+	if tm.Tmx == nil {
+		return ErrNoTmxData
+	}
+	index := tm.objectLocations()
+	loc, ok := index[id]
+	if !ok {
+		return ErrObjectNotFound
+	}
+
+	og := &tm.Tmx.ObjectGroups[loc.group]
+	old := og.Objects[loc.index]
+	og.Objects = slices.Delete(og.Objects, loc.index, loc.index+1)
+
+	delete(index, id)
+	for i := loc.index; i < len(og.Objects); i++ {
+		index[og.Objects[i].ID] = objectLoc{group: loc.group, index: i}
+	}
+
+	tm.objectChanged(loc.group, &old, nil)
+	return nil
+}
+
+// objectLocations returns the location of every object by ID, building it on first use.
+func (tm *Map) objectLocations() map[int32]objectLoc {
+	if tm.objectLocs != nil {
+		return tm.objectLocs
+	}
+
+	tm.objectLocs = make(map[int32]objectLoc)
+	if tm.Tmx == nil {
+		return tm.objectLocs
+	}
+	for g := range tm.Tmx.ObjectGroups {
+		for i := range tm.Tmx.ObjectGroups[g].Objects {
+			tm.objectLocs[tm.Tmx.ObjectGroups[g].Objects[i].ID] = objectLoc{group: g, index: i}
+		}
+	}
+	return tm.objectLocs
+}
+
+func (tm *Map) objectChanged(group int, old, new *tiled.Object) {
+	tm.updateObjectIndex(old, new)
+	for _, cb := range tm.objectChangeFuncs.list {
+		cb.fn(group, old, new)
+	}
+}
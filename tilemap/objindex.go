@@ -0,0 +1,199 @@
+package tilemap
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/adm87/tiled"
+	"github.com/adm87/tiled/internal/hash"
+)
+
+// ====================== Object Index =====================
+
+// objectIndex is a spatial hash of the objects of every object group, by their bounds in map
+// pixels. Cells hold object IDs, which stay valid as groups grow and shrink.
+type objectIndex struct {
+	cellW, cellH float32
+	cells        map[uint64][]int32
+	bounds       map[int32][4]float32
+	seen         map[int32]struct{} // scratch for deduplicating query results
+	result       []*tiled.Object
+	world        []tiled.Object // reused by GetObjectsWorld
+}
+
+// GetObjects returns the objects of every object group whose bounds intersect a rectangle in
+// world coordinates (minX, minY, maxX, maxY), filtered by the query flags the same way as
+// tiled.Objects. Objects are returned in document order: by group, then as listed in it.
+//
+// Bounds take the object's rotation into account, but not the exact shape of ellipses and
+// polygons. The index is built by SetTmx and kept up to date by AddObject, MoveObject and
+// RemoveObject. The returned slice is reused by the next call.
+//
+// The objects are the ones of the Tmx, in map coordinates; GetObjectsWorld returns copies in
+// world coordinates.
+func (tm *Map) GetObjects(minX, minY, maxX, maxY float32, flags tiled.QueryFlag) []*tiled.Object {
+	idx := &tm.objects
+	idx.result = idx.result[:0]
+	if tm.Tmx == nil || idx.cells == nil {
+		return idx.result
+	}
+
+	rect := tm.RectFromWorld([4]float32{minX, minY, maxX, maxY})
+	cx0, cy0, cx1, cy1 := idx.cellRange(rect)
+
+	locs := tm.objectLocations()
+	clear(idx.seen)
+	for cy := cy0; cy <= cy1; cy++ {
+		for cx := cx0; cx <= cx1; cx++ {
+			for _, id := range idx.cells[hash.EncodeGridKey(cx, cy)] {
+				if _, ok := idx.seen[id]; ok {
+					continue
+				}
+				idx.seen[id] = struct{}{}
+
+				b := idx.bounds[id]
+				if b[0] > rect[2] || b[2] < rect[0] || b[1] > rect[3] || b[3] < rect[1] {
+					continue
+				}
+
+				loc := locs[id]
+				og := &tm.Tmx.ObjectGroups[loc.group]
+				obj := &og.Objects[loc.index]
+				if flags&tiled.QueryFlagIncludeHidden == 0 && (!og.IsVisible() || !obj.IsVisible()) {
+					continue
+				}
+				if flags&tiled.QueryFlagExcludeLocked != 0 && og.IsLocked() {
+					continue
+				}
+				idx.result = append(idx.result, obj)
+			}
+		}
+	}
+
+	slices.SortFunc(idx.result, func(a, b *tiled.Object) int {
+		la, lb := locs[a.ID], locs[b.ID]
+		if la.group != lb.group {
+			return cmp.Compare(la.group, lb.group)
+		}
+		return cmp.Compare(la.index, lb.index)
+	})
+	return idx.result
+}
+
+// buildObjectIndex indexes every object of the map. Cells are the size of Tiled's default
+// chunks, which suits objects about the size of a few tiles.
+func (tm *Map) buildObjectIndex() {
+	idx := &tm.objects
+	idx.cellW = float32(DefaultChunkSize * tm.Tmx.TileWidth)
+	idx.cellH = float32(DefaultChunkSize * tm.Tmx.TileHeight)
+	if idx.cells == nil {
+		idx.cells = make(map[uint64][]int32)
+		idx.bounds = make(map[int32][4]float32)
+		idx.seen = make(map[int32]struct{})
+	}
+
+	for g := range tm.Tmx.ObjectGroups {
+		for i := range tm.Tmx.ObjectGroups[g].Objects {
+			tm.indexObject(&tm.Tmx.ObjectGroups[g].Objects[i])
+		}
+	}
+}
+
+// resetObjectIndex empties the index, keeping its memory for reuse.
+func (tm *Map) resetObjectIndex() {
+	clear(tm.objects.cells)
+	clear(tm.objects.bounds)
+	clear(tm.objects.result)
+	tm.objects.result = tm.objects.result[:0]
+}
+
+// updateObjectIndex moves an object changed through the map to its new cells.
+func (tm *Map) updateObjectIndex(old, new *tiled.Object) {
+	if tm.objects.cells == nil {
+		return
+	}
+	if old != nil {
+		tm.unindexObject(old.ID)
+	}
+	if new != nil {
+		tm.indexObject(new)
+	}
+}
+
+func (tm *Map) indexObject(obj *tiled.Object) {
+	idx := &tm.objects
+	b := tm.objectPixelBounds(obj)
+	idx.bounds[obj.ID] = b
+
+	cx0, cy0, cx1, cy1 := idx.cellRange(b)
+	for cy := cy0; cy <= cy1; cy++ {
+		for cx := cx0; cx <= cx1; cx++ {
+			key := hash.EncodeGridKey(cx, cy)
+			idx.cells[key] = append(idx.cells[key], obj.ID)
+		}
+	}
+}
+
+func (tm *Map) unindexObject(id int32) {
+	idx := &tm.objects
+	b, ok := idx.bounds[id]
+	if !ok {
+		return
+	}
+	delete(idx.bounds, id)
+
+	cx0, cy0, cx1, cy1 := idx.cellRange(b)
+	for cy := cy0; cy <= cy1; cy++ {
+		for cx := cx0; cx <= cx1; cx++ {
+			key := hash.EncodeGridKey(cx, cy)
+			ids := slices.DeleteFunc(idx.cells[key], func(v int32) bool { return v == id })
+			if len(ids) == 0 {
+				delete(idx.cells, key)
+			} else {
+				idx.cells[key] = ids
+			}
+		}
+	}
+}
+
+// cellRange returns the cells covered by a rectangle in map pixels, clamped so huge
+// rectangles can't overflow the cell coordinates.
+func (idx *objectIndex) cellRange(b [4]float32) (minX, minY, maxX, maxY int32) {
+	cell := func(v, size float32) int32 {
+		return int32(min(max(math.Floor(float64(v/size)), math.MinInt32/2), math.MaxInt32/2))
+	}
+	return cell(b[0], idx.cellW), cell(b[1], idx.cellH), cell(b[2], idx.cellW), cell(b[3], idx.cellH)
+}
+
+// objectPixelBounds returns the bounding box of an object in map pixels. Tile objects are
+// anchored at their bottom-left corner, every other object at its top-left, and objects rotate
+// about their anchor.
+func (tm *Map) objectPixelBounds(obj *tiled.Object) [4]float32 {
+	var local [][2]float32
+	points := obj.Polygon.Points
+	if len(points) == 0 {
+		points = obj.Polyline.Points
+	}
+	switch {
+	case len(points) > 0:
+		for i := 0; i+1 < len(points); i += 2 {
+			local = append(local, [2]float32{points[i], points[i+1]})
+		}
+	case obj.GID != 0:
+		local = [][2]float32{{0, -obj.Height}, {obj.Width, -obj.Height}, {0, 0}, {obj.Width, 0}}
+	default:
+		local = [][2]float32{{0, 0}, {obj.Width, 0}, {0, obj.Height}, {obj.Width, obj.Height}}
+	}
+
+	sin, cos := math.Sincos(float64(obj.Rotation) * math.Pi / 180)
+	b := [4]float32{math.MaxFloat32, math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for _, p := range local {
+		x := obj.X + float32(float64(p[0])*cos-float64(p[1])*sin)
+		y := obj.Y + float32(float64(p[0])*sin+float64(p[1])*cos)
+		x, y = tm.objectToPixel(x, y)
+		b[0], b[1] = min(b[0], x), min(b[1], y)
+		b[2], b[3] = max(b[2], x), max(b[3], y)
+	}
+	return b
+}
@@ -0,0 +1,220 @@
+package tilemap
+
+import (
+	"slices"
+
+	"github.com/adm87/tiled"
+)
+
+// OpaqueFunc reports whether a tile blocks light.
+// It receives the tileset index and the tile ID local to that tileset.
+type OpaqueFunc func(tsIdx int, tileID uint32) bool
+
+// ====================== Occluders =====================
+
+// Occluders is the set of rectangles covered by opaque tiles in a layer, in world coordinates,
+// suitable as input for 2D shadow casting.
+//
+// Adjacent opaque tiles are merged into runs per row, and identical runs on consecutive rows
+// are merged into a single rectangle. Tile mutations only rescan the affected chunk row. On
+// isometric maps, each rectangle is the bounding box of its tiles' diamonds.
+type Occluders struct {
+	tm         *Map
+	layer      int
+	opaque     OpaqueFunc
+	generation uint64
+
+	runs        map[rowKey][][2]int32 // opaque runs [minX, maxX) per chunk row
+	rects       [][4]float32
+	dirty       bool
+	unsubscribe func() // stops tile change notifications, see Close
+}
+
+type rowKey struct {
+	chunkX, y int32
+}
+
+// NewOccluders builds the occluder set of a layer and keeps it updated as tiles change, until
+// Close is called. A nil opaque func treats every non-empty tile as opaque.
+func (tm *Map) NewOccluders(layer int, opaque OpaqueFunc) (*Occluders, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
+	}
+
+	if layer < 0 || layer >= len(tm.layers) {
+		return nil, ErrLayerNotFound
+	}
+
+	o := &Occluders{
+		tm:     tm,
+		layer:  layer,
+		opaque: opaque,
+		runs:   make(map[rowKey][][2]int32),
+	}
+
+	if err := o.rebuild(); err != nil {
+		return nil, err
+	}
+
+	o.unsubscribe = tm.OnTileChanged(o.tileChanged)
+	return o, nil
+}
+
+// Close stops updating the occluders as tiles change, releasing them from the map. Rects
+// keeps returning the rectangles as they were, unless the map's layers are rebuilt.
+func (o *Occluders) Close() {
+	o.unsubscribe()
+}
+
+// Rects returns the merged occluder rectangles as minX, minY, maxX, maxY in world coordinates.
+// The returned slice is reused between calls. The occluders are rebuilt first when the map's
+// layers were rebuilt, e.g. by SetTmx, which fails if the layer no longer exists.
+func (o *Occluders) Rects() ([][4]float32, error) {
+	if o.generation != o.tm.generation {
+		if err := o.rebuild(); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.dirty {
+		o.merge()
+	}
+	return o.rects, nil
+}
+
+func (o *Occluders) rebuild() error {
+	clear(o.runs)
+	o.generation = o.tm.generation
+	o.dirty = true
+
+	if o.tm.Tmx == nil || o.layer >= len(o.tm.layers) {
+		return ErrLayerNotFound
+	}
+
+	var err error
+	o.tm.layers[o.layer].ForEach(func(chunk *Chunk) {
+		if err != nil {
+			return
+		}
+		if err = chunk.decode(); err != nil {
+			return
+		}
+		for y := chunk.y; y < chunk.y+chunk.h; y++ {
+			o.scanRow(chunk, y)
+		}
+	})
+	return err
+}
+
+func (o *Occluders) tileChanged(layer int, x, y int32, _, _ uint32) {
+	if layer != o.layer || o.generation != o.tm.generation {
+		return
+	}
+
+	chunk, err := o.tm.chunkAt(layer, x, y)
+	if err != nil {
+		return
+	}
+
+	o.scanRow(chunk, y)
+	o.dirty = true
+}
+
+// scanRow recomputes the opaque runs of a single row within a chunk.
+func (o *Occluders) scanRow(chunk *Chunk, y int32) {
+	key := rowKey{chunk.x, y}
+	runs := o.runs[key][:0]
+
+	start := int32(-1)
+	for x := chunk.x; x < chunk.x+chunk.w; x++ {
+		if o.isOpaque(chunk, x, y) {
+			if start < 0 {
+				start = x
+			}
+			continue
+		}
+		if start >= 0 {
+			runs = append(runs, [2]int32{start, x})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		runs = append(runs, [2]int32{start, chunk.x + chunk.w})
+	}
+
+	if len(runs) == 0 {
+		delete(o.runs, key)
+		return
+	}
+	o.runs[key] = runs
+}
+
+func (o *Occluders) isOpaque(chunk *Chunk, x, y int32) bool {
+	i := chunk.index(x, y)
+	if i >= chunk.len() {
+		return false
+	}
+
+	tileID, _ := tiled.DecodeGID(chunk.at(i))
+	if tileID == 0 {
+		return false
+	}
+
+	if o.opaque == nil {
+		return true
+	}
+
+	_, tileID, tsIdx := tiled.TilesetByGID(o.tm.Tmx, tileID)
+	if tsIdx == -1 {
+		return false
+	}
+	return o.opaque(tsIdx, tileID)
+}
+
+// merge joins runs across chunk borders and then stacks identical runs of consecutive rows.
+func (o *Occluders) merge() {
+	o.dirty = false
+	o.rects = o.rects[:0]
+
+	// Collect runs as [y, minX, maxX], sorted by row then column.
+	var rows [][3]int32
+	for key, runs := range o.runs {
+		for _, r := range runs {
+			rows = append(rows, [3]int32{key.y, r[0], r[1]})
+		}
+	}
+	slices.SortFunc(rows, func(a, b [3]int32) int {
+		if a[0] != b[0] {
+			return int(a[0] - b[0])
+		}
+		return int(a[1] - b[1])
+	})
+
+	// Join runs touching across chunk borders.
+	joined := rows[:0]
+	for _, r := range rows {
+		if n := len(joined); n > 0 && joined[n-1][0] == r[0] && joined[n-1][2] == r[1] {
+			joined[n-1][2] = r[2]
+			continue
+		}
+		joined = append(joined, r)
+	}
+
+	// Stack identical runs of consecutive rows into tile rectangles [minX, minY, maxX, maxY).
+	type span struct{ minX, maxX int32 }
+	open := make(map[span]int)
+	var tileRects [][4]int32
+	for _, r := range joined {
+		s := span{r[1], r[2]}
+		if i, ok := open[s]; ok && tileRects[i][3] == r[0] {
+			tileRects[i][3] = r[0] + 1
+			continue
+		}
+		open[s] = len(tileRects)
+		tileRects = append(tileRects, [4]int32{r[1], r[0], r[2], r[0] + 1})
+	}
+
+	for _, r := range tileRects {
+		o.rects = append(o.rects, o.tm.tileRectToWorld(r[0], r[1], r[2], r[3]))
+	}
+}
@@ -0,0 +1,73 @@
+package tilemap
+
+import (
+	"slices"
+
+	"github.com/adm87/tiled"
+)
+
+// SortFunc compares two buffered tiles of the same layer, like the comparison of
+// slices.SortFunc. Tiles carry their layer in LayerIdx, so one function can sort layers
+// differently.
+type SortFunc func(a, b Data) int
+
+// ====================== Render Order =====================
+
+// SetSortFunc sets a comparison applied to the tiles of each layer when they are buffered,
+// e.g. sorting decorations by their base for a faux-3D look. Tiles are sorted once per
+// buffered region, after the render order, which is kept among tiles comparing equal. Pass
+// nil to only apply the render order.
+func (tm *Map) SetSortFunc(fn SortFunc) {
+	tm.sortFunc = fn
+	tm.dirty = true
+}
+
+// sortLayer sorts the buffered tiles of one layer into the render order, then by the map's
+// sort function.
+func (tm *Map) sortLayer(tiles []Data) {
+	tm.sortRenderOrder(tiles)
+	if tm.sortFunc != nil && len(tiles) > 1 {
+		slices.SortStableFunc(tiles, tm.sortFunc)
+	}
+}
+
+// sortRenderOrder sorts the buffered tiles of one layer into the render order of the map, so
+// tiles overlapping their neighbours, e.g. taller than the grid, are drawn over each other the
+// same as in Tiled. Chunks emit their tiles row by row, left to right, so the tiles only need
+// sorting for other render orders or when several chunks share rows.
+//
+// Tiled only honors the render order of orthogonal maps; the tiles of other orientations are
+// left in chunk order.
+func (tm *Map) sortRenderOrder(tiles []Data) {
+	if tm.Tmx.Orientation != tiled.OrientationOrthogonal || len(tiles) < 2 {
+		return
+	}
+
+	order := tm.Tmx.RenderOrder
+	down := order == tiled.RenderOrderRightDown || order == tiled.RenderOrderLeftDown
+	right := order == tiled.RenderOrderRightDown || order == tiled.RenderOrderRightUp
+	if tm.yUp {
+		// Rows further down the map have a lower world y.
+		down = !down
+	}
+
+	// Tiles of a layer share its offset, so their world positions order them like their cells.
+	cmp := func(a, b Data) int {
+		if a.Y != b.Y {
+			if (a.Y < b.Y) == down {
+				return -1
+			}
+			return 1
+		}
+		if a.X != b.X {
+			if (a.X < b.X) == right {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	}
+	if !slices.IsSortedFunc(tiles, cmp) {
+		slices.SortFunc(tiles, cmp)
+	}
+}
@@ -0,0 +1,264 @@
+package tilemap
+
+import (
+	"errors"
+	"math"
+
+	"github.com/adm87/tiled"
+)
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrInvalidScale   = errors.New("pixels per unit must be positive and finite")
+)
+
+// ====================== Origin =====================
+
+// SetOrigin sets the point, in Tiled's map coordinates, that becomes the world origin.
+//
+// The frame, buffered tile positions and every world position taken or returned by the map
+// are translated accordingly. Objects from the Tmx are in map coordinates; ObjectToWorld,
+// GetObjectsWorld and GetTileCollisionWorld return copies in world coordinates. Map
+// coordinates are the pixels the map is drawn in, which for isometric maps is the projected
+// view, not the tile-axis coordinates of their objects.
+func (tm *Map) SetOrigin(x, y float32) {
+	if tm.originX == x && tm.originY == y {
+		return
+	}
+
+	tm.originX, tm.originY = x, y
+	tm.invalidatePositions()
+}
+
+// SetYUp makes world coordinates point Y up, as in Box2D and many engines, instead of Tiled's
+// Y down. World Y becomes the negated distance from the origin, and world rectangles (the
+// frame, buffered tiles, occluders) have their min corner at the bottom-left.
+func (tm *Map) SetYUp(yUp bool) {
+	if tm.yUp == yUp {
+		return
+	}
+
+	tm.yUp = yUp
+	tm.invalidatePositions()
+}
+
+// YUp reports whether world coordinates point Y up.
+func (tm *Map) YUp() bool {
+	return tm.yUp
+}
+
+// SetOriginCenter moves the world origin to the center of the map.
+// Infinite maps use the center of their content bounds.
+func (tm *Map) SetOriginCenter() error {
+	if tm.Tmx == nil {
+		return ErrNoTmxData
+	}
+
+	if !tm.Tmx.IsInfinite() {
+		tm.SetOrigin(tm.tileToPixel(float32(tm.Tmx.Width)/2, float32(tm.Tmx.Height)/2))
+		return nil
+	}
+
+	bounds, ok, err := tm.ContentBounds()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		tm.SetOrigin(0, 0)
+		return nil
+	}
+	tm.SetOrigin(tm.tileToPixel(float32(bounds.MinX+bounds.MaxX)/2, float32(bounds.MinY+bounds.MaxY)/2))
+	return nil
+}
+
+// SetOriginObject moves the world origin to the position of the first object with the given
+// name, e.g. a marker placed by the level designer.
+func (tm *Map) SetOriginObject(name string) error {
+	if tm.Tmx == nil {
+		return ErrNoTmxData
+	}
+
+	for _, obj := range tiled.Objects(tm.Tmx, tiled.QueryFlagIncludeHidden) {
+		if obj.Name == name {
+			tm.SetOrigin(tm.objectToPixel(obj.X, obj.Y))
+			return nil
+		}
+	}
+	return ErrObjectNotFound
+}
+
+// Origin returns the world origin in map coordinates.
+func (tm *Map) Origin() (x, y float32) {
+	return tm.originX, tm.originY
+}
+
+// SetPixelsPerUnit sets how many map pixels make one world unit, e.g. 32 for physics engines
+// working in meters. World positions and sizes taken or returned by the map are divided by
+// it, including the objects and collision shapes returned by ObjectToWorld, GetObjectsWorld
+// and GetTileCollisionWorld. Sizes that come straight from the Tmx or Tsx, like tile sizes,
+// stay in pixels.
+func (tm *Map) SetPixelsPerUnit(ppu float32) error {
+	if !(ppu > 0) || math.IsInf(float64(ppu), 0) {
+		return ErrInvalidScale
+	}
+	if tm.ppu() == ppu {
+		return nil
+	}
+
+	tm.pixelsPerUnit = ppu
+	tm.invalidatePositions()
+	return nil
+}
+
+// PixelsPerUnit returns how many map pixels make one world unit.
+func (tm *Map) PixelsPerUnit() float32 {
+	return tm.ppu()
+}
+
+// ToWorld converts a position in map coordinates, e.g. an object position, to world coordinates.
+func (tm *Map) ToWorld(x, y float32) (float32, float32) {
+	ppu := tm.ppu()
+	if tm.yUp {
+		return (x - tm.originX) / ppu, (tm.originY - y) / ppu
+	}
+	return (x - tm.originX) / ppu, (y - tm.originY) / ppu
+}
+
+// FromWorld converts a position in world coordinates to map coordinates.
+func (tm *Map) FromWorld(x, y float32) (float32, float32) {
+	ppu := tm.ppu()
+	if tm.yUp {
+		return x*ppu + tm.originX, tm.originY - y*ppu
+	}
+	return x*ppu + tm.originX, y*ppu + tm.originY
+}
+
+// ppu returns the pixels per unit, treating the zero value as 1.
+func (tm *Map) ppu() float32 {
+	if tm.pixelsPerUnit == 0 {
+		return 1
+	}
+	return tm.pixelsPerUnit
+}
+
+// RectToWorld converts a rectangle in map coordinates, e.g. object bounds, to world
+// coordinates, keeping min below max on both axes.
+func (tm *Map) RectToWorld(rect [4]float32) [4]float32 {
+	minX, minY := tm.ToWorld(rect[0], rect[1])
+	maxX, maxY := tm.ToWorld(rect[2], rect[3])
+	return [4]float32{min(minX, maxX), min(minY, maxY), max(minX, maxX), max(minY, maxY)}
+}
+
+// RectFromWorld converts a rectangle in world coordinates to map coordinates.
+func (tm *Map) RectFromWorld(rect [4]float32) [4]float32 {
+	minX, minY := tm.FromWorld(rect[0], rect[1])
+	maxX, maxY := tm.FromWorld(rect[2], rect[3])
+	return [4]float32{min(minX, maxX), min(minY, maxY), max(minX, maxX), max(minY, maxY)}
+}
+
+// invalidatePositions rebuilds the cache after the world coordinate system changed.
+func (tm *Map) invalidatePositions() {
+	// Memoized tiles carry world positions.
+	for _, layer := range tm.layers {
+		layer.ForEach(func(chunk *Chunk) {
+			chunk.Flush()
+		})
+	}
+	tm.dirty = true
+}
+
+// TileToWorld converts a (fractional) tile coordinate to world coordinates, following the
+// map's orientation. Whole coordinates give the top-left corner of the cell on orthogonal
+// maps, the top corner of its diamond on isometric maps, and the top-left corner of the
+// cell's bounding box on staggered and hexagonal maps. The map must have a Tmx set.
+func (tm *Map) TileToWorld(x, y float32) (float32, float32) {
+	return tm.tileToWorld(x, y)
+}
+
+// WorldToTile converts a world position to a fractional tile coordinate, following the map's
+// orientation. Floor the result to get the cell containing the position. The map must have
+// a Tmx set.
+func (tm *Map) WorldToTile(x, y float32) (float32, float32) {
+	return tm.pixelToTile(tm.FromWorld(x, y))
+}
+
+// tileToWorld returns the world position of a (fractional) tile coordinate.
+func (tm *Map) tileToWorld(x, y float32) (float32, float32) {
+	return tm.ToWorld(tm.tileToPixel(x, y))
+}
+
+// tileToPixel returns the map pixel position of a (fractional) tile coordinate.
+func (tm *Map) tileToPixel(x, y float32) (float32, float32) {
+	switch tm.Tmx.Orientation {
+	case tiled.OrientationIsometric:
+		return tm.isoToPixel(x, y)
+	case tiled.OrientationStaggered, tiled.OrientationHexagonal:
+		return tm.hexToPixel(x, y)
+	}
+	return x * float32(tm.Tmx.TileWidth), y * float32(tm.Tmx.TileHeight)
+}
+
+// pixelToTile returns the fractional tile coordinate of a map pixel position.
+func (tm *Map) pixelToTile(x, y float32) (float32, float32) {
+	switch tm.Tmx.Orientation {
+	case tiled.OrientationIsometric:
+		return tm.pixelToIso(x, y)
+	case tiled.OrientationStaggered, tiled.OrientationHexagonal:
+		return tm.pixelToHex(x, y)
+	}
+	return x / float32(tm.Tmx.TileWidth), y / float32(tm.Tmx.TileHeight)
+}
+
+// tileRectToWorld returns the world rectangle covering a range of tile coordinates. On
+// isometric maps, it is the bounding box of the range's diamond, and on staggered and
+// hexagonal maps the bounding box of its cells.
+func (tm *Map) tileRectToWorld(minX, minY, maxX, maxY int32) [4]float32 {
+	switch tm.Tmx.Orientation {
+	case tiled.OrientationStaggered, tiled.OrientationHexagonal:
+		return tm.RectToWorld(tm.hexRectToPixel(minX, minY, maxX, maxY))
+	case tiled.OrientationIsometric:
+		_, top := tm.isoToPixel(float32(minX), float32(minY))
+		right, _ := tm.isoToPixel(float32(maxX), float32(minY))
+		_, bottom := tm.isoToPixel(float32(maxX), float32(maxY))
+		left, _ := tm.isoToPixel(float32(minX), float32(maxY))
+		return tm.RectToWorld([4]float32{left, top, right, bottom})
+	}
+
+	tw, th := float32(tm.Tmx.TileWidth), float32(tm.Tmx.TileHeight)
+	return tm.RectToWorld([4]float32{float32(minX) * tw, float32(minY) * th, float32(maxX) * tw, float32(maxY) * th})
+}
+
+// worldToTile returns the tile coordinate containing a world position.
+func (tm *Map) worldToTile(x, y float32) (int32, int32) {
+	x, y = tm.FromWorld(x, y)
+	switch tm.Tmx.Orientation {
+	case tiled.OrientationIsometric:
+		tx, ty := tm.pixelToIso(x, y)
+		return int32(math.Floor(float64(tx))), int32(math.Floor(float64(ty)))
+	case tiled.OrientationStaggered, tiled.OrientationHexagonal:
+		l := tm.hexLayout()
+		return l.pixelToCell(x, y)
+	}
+	return int32(math.Floor(float64(x) / float64(tm.Tmx.TileWidth))),
+		int32(math.Floor(float64(y) / float64(tm.Tmx.TileHeight)))
+}
+
+// objectToPixel returns the map pixel position of an object position. Tiled stores object
+// positions of isometric maps along the tile axes, in units of the tile height.
+func (tm *Map) objectToPixel(x, y float32) (float32, float32) {
+	if tm.Tmx.Orientation == tiled.OrientationIsometric {
+		th := float32(tm.Tmx.TileHeight)
+		return tm.isoToPixel(x/th, y/th)
+	}
+	return x, y
+}
+
+// pixelToObject is the inverse of objectToPixel.
+func (tm *Map) pixelToObject(x, y float32) (float32, float32) {
+	if tm.Tmx.Orientation == tiled.OrientationIsometric {
+		th := float32(tm.Tmx.TileHeight)
+		tx, ty := tm.pixelToIso(x, y)
+		return tx * th, ty * th
+	}
+	return x, y
+}
@@ -0,0 +1,159 @@
+package tilemap
+
+import "math"
+
+// ====================== Palette =====================
+
+// palette maps the GIDs of a layer to 16-bit indices for packed chunk storage.
+// Most maps use far fewer than 65536 distinct GIDs per layer, so packed chunks need
+// half the memory of decoded GIDs.
+type palette struct {
+	gids  []uint32
+	index map[uint32]uint16
+}
+
+func newPalette() *palette {
+	return &palette{
+		gids:  []uint32{0},
+		index: map[uint32]uint16{0: 0},
+	}
+}
+
+// lookup returns the index of a GID, adding it to the palette if needed.
+// It returns false when the palette is full.
+func (p *palette) lookup(gid uint32) (uint16, bool) {
+	if i, ok := p.index[gid]; ok {
+		return i, true
+	}
+	if len(p.gids) > math.MaxUint16 {
+		return 0, false
+	}
+	i := uint16(len(p.gids))
+	p.gids = append(p.gids, gid)
+	p.index[gid] = i
+	return i, true
+}
+
+func (p *palette) reset() {
+	p.gids = p.gids[:1]
+	clear(p.index)
+	p.index[0] = 0
+}
+
+// ====================== Packed storage =====================
+
+// SetPackedStorage enables or disables packed storage of decoded layers.
+//
+// Packed layers store each cell as a 16-bit index into a per-layer GID palette instead of
+// a full GID, roughly halving the memory of decoded layers on huge maps. Queries behave the
+// same either way. Chunks using more distinct GIDs than fit the palette stay unpacked.
+// Already decoded chunks are converted in place, keeping any runtime modifications.
+func (tm *Map) SetPackedStorage(enabled bool) {
+	tm.packed = enabled
+	for _, layer := range tm.layers {
+		layer.setPacked(enabled)
+	}
+}
+
+// PackedStorage reports whether packed storage is enabled.
+func (tm *Map) PackedStorage() bool {
+	return tm.packed
+}
+
+func (l *Layer) setPacked(enabled bool) {
+	l.ForEach(func(chunk *Chunk) {
+		if chunk.palette != nil {
+			chunk.unpack()
+		}
+	})
+
+	if !enabled {
+		l.palette = nil
+		return
+	}
+
+	if l.palette == nil {
+		l.palette = newPalette()
+	} else {
+		l.palette.reset()
+	}
+
+	l.ForEach(func(chunk *Chunk) {
+		if chunk.isDecoded {
+			chunk.pack(l.palette)
+		}
+	})
+}
+
+// ====================== Chunk storage =====================
+
+// len returns the number of decoded cells of the chunk.
+func (c *Chunk) len() int32 {
+	if c.palette != nil {
+		return int32(len(c.packed))
+	}
+	return int32(len(c.data))
+}
+
+// at returns the GID stored at a cell index.
+func (c *Chunk) at(i int32) uint32 {
+	if c.palette != nil {
+		return c.palette.gids[c.packed[i]]
+	}
+	return c.data[i]
+}
+
+// set stores a GID at a cell index, unpacking the chunk if the layer palette is full.
+func (c *Chunk) set(i int32, gid uint32) {
+	c.stale = true
+	c.modified = true
+	c.unsynced = true
+	c.summarized = false
+	c.emitted = false
+	if int(i) < len(c.memoState) {
+		c.memoState[i] = memoUnknown
+	}
+
+	if c.palette != nil {
+		if idx, ok := c.palette.lookup(gid); ok {
+			c.packed[i] = idx
+			return
+		}
+		c.unpack()
+	}
+	c.data[i] = gid
+}
+
+// pack moves decoded GIDs into packed storage, releasing the decoded GIDs. Chunks whose
+// GIDs do not fit the layer palette stay unpacked, and the GIDs they added to the palette are
+// removed again.
+func (c *Chunk) pack(pal *palette) {
+	n := len(pal.gids)
+	packed := c.packed[:0]
+	for _, gid := range c.data {
+		idx, ok := pal.lookup(gid)
+		if !ok {
+			for _, added := range pal.gids[n:] {
+				delete(pal.index, added)
+			}
+			pal.gids = pal.gids[:n]
+			c.packed = nil
+			return
+		}
+		packed = append(packed, idx)
+	}
+	c.packed = packed
+	c.palette = pal
+	c.data = nil
+}
+
+// unpack moves packed GIDs back into decoded storage, releasing the packed indices.
+func (c *Chunk) unpack() {
+	data := make([]uint32, 0, len(c.packed))
+	for _, idx := range c.packed {
+		data = append(data, c.palette.gids[idx])
+	}
+	c.data = data
+	c.packed = nil
+	c.palette = nil
+}
@@ -0,0 +1,179 @@
+package tilemap
+
+import (
+	"slices"
+
+	"github.com/adm87/tiled"
+)
+
+// ====================== MapPatch =====================
+
+// PatchEntry records a single cell change in a layer.
+type PatchEntry struct {
+	Layer    int
+	X, Y     int32 // tile coordinate
+	Old, New uint32
+}
+
+// MapPatch is an ordered list of cell changes, produced by the region operations of a Map.
+// Apply it to redo the changes, or apply its inverse to undo them.
+type MapPatch struct {
+	Entries []PatchEntry
+}
+
+func (p *MapPatch) IsEmpty() bool {
+	return len(p.Entries) == 0
+}
+
+// Invert returns a patch that reverts the changes of p.
+func (p *MapPatch) Invert() MapPatch {
+	inv := MapPatch{
+		Entries: make([]PatchEntry, len(p.Entries)),
+	}
+	for i, e := range p.Entries {
+		inv.Entries[len(p.Entries)-1-i] = PatchEntry{
+			Layer: e.Layer,
+			X:     e.X,
+			Y:     e.Y,
+			Old:   e.New,
+			New:   e.Old,
+		}
+	}
+	return inv
+}
+
+// ApplyPatch writes the new value of every entry in the patch to the map.
+func (tm *Map) ApplyPatch(patch MapPatch) error {
+	for _, e := range patch.Entries {
+		if _, err := tm.setGID(e.Layer, e.X, e.Y, e.New); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FillRegion sets every cell of a layer within a region (in tile coordinates) to gid.
+// Cells outside of the layer's chunks are skipped.
+func (tm *Map) FillRegion(layer int, region Region, gid uint32) (MapPatch, error) {
+	return tm.mapRegion(layer, region, func(uint32) uint32 {
+		return gid
+	})
+}
+
+// ReplaceGID replaces every occurrence of from with to within a region of a layer.
+// GIDs are compared including their flip flags.
+func (tm *Map) ReplaceGID(layer int, region Region, from, to uint32) (MapPatch, error) {
+	return tm.mapRegion(layer, region, func(gid uint32) uint32 {
+		if gid == from {
+			return to
+		}
+		return gid
+	})
+}
+
+// ClearRegion empties every cell of a layer within a region.
+func (tm *Map) ClearRegion(layer int, region Region) (MapPatch, error) {
+	return tm.FillRegion(layer, region, 0)
+}
+
+// mapRegion rewrites the cells of a region through fn, recording every cell that changed.
+func (tm *Map) mapRegion(layer int, region Region, fn func(gid uint32) uint32) (MapPatch, error) {
+	var patch MapPatch
+
+	if tm.Tmx == nil {
+		return patch, ErrNoTmxData
+	}
+
+	if layer < 0 || layer >= len(tm.layers) {
+		return patch, ErrLayerNotFound
+	}
+
+	for y := region.MinY; y < region.MaxY; y++ {
+		for x := region.MinX; x < region.MaxX; x++ {
+			old, err := tm.gidAt(layer, x, y)
+			if err == ErrTileNotFound {
+				continue
+			}
+			if err != nil {
+				return patch, err
+			}
+
+			gid := fn(old)
+			if gid == old {
+				continue
+			}
+
+			if _, err := tm.setGID(layer, x, y, gid); err != nil {
+				return patch, err
+			}
+			patch.Entries = append(patch.Entries, PatchEntry{
+				Layer: layer,
+				X:     x,
+				Y:     y,
+				Old:   old,
+				New:   gid,
+			})
+		}
+	}
+	return patch, nil
+}
+
+// DiffFromOriginal compares the current cells of every layer against the pristine Tmx content
+// and returns a patch of the differences, so save games can store only player changes.
+// Apply the patch to a freshly loaded map to restore them.
+func (tm *Map) DiffFromOriginal() (MapPatch, error) {
+	var patch MapPatch
+
+	if tm.Tmx == nil {
+		return patch, ErrNoTmxData
+	}
+
+	var err error
+	for layer := range tm.layers {
+		tm.layers[layer].ForEach(func(chunk *Chunk) {
+			// Chunks that were never decoded cannot have been modified.
+			if err != nil || !chunk.isDecoded {
+				return
+			}
+
+			var original []uint32
+			original, err = tiled.DecodeContent(chunk.raw, chunk.encoding, chunk.compression)
+			if err != nil {
+				return
+			}
+
+			for i := range chunk.len() {
+				gid := chunk.at(i)
+
+				var old uint32
+				if int(i) < len(original) {
+					old = original[i]
+				}
+				if gid == old {
+					continue
+				}
+				patch.Entries = append(patch.Entries, PatchEntry{
+					Layer: layer,
+					X:     chunk.x + i%chunk.w,
+					Y:     chunk.y + i/chunk.w,
+					Old:   old,
+					New:   gid,
+				})
+			}
+		})
+		if err != nil {
+			return patch, err
+		}
+	}
+
+	slices.SortFunc(patch.Entries, func(a, b PatchEntry) int {
+		if a.Layer != b.Layer {
+			return a.Layer - b.Layer
+		}
+		if a.Y != b.Y {
+			return int(a.Y - b.Y)
+		}
+		return int(a.X - b.X)
+	})
+	return patch, nil
+}
@@ -0,0 +1,133 @@
+package tilemap
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/adm87/tiled/internal/hash"
+)
+
+// ====================== Chunk Index =====================
+
+// addChunk registers a chunk in the layer's chunk index.
+//
+// Tiled writes the chunks of a layer on a regular grid, so chunks are indexed by grid cell.
+// Layers whose chunks don't line up fall back to scanning every chunk.
+func (l *Layer) addChunk(c *Chunk) {
+	if l.index == nil {
+		l.index = make(map[uint64]*Chunk)
+	}
+
+	if len(l.chunks) == 0 {
+		l.chunkW, l.chunkH = c.w, c.h
+	}
+	l.chunks = append(l.chunks, c)
+
+	if l.chunkW <= 0 || c.w != l.chunkW || c.h != l.chunkH ||
+		floorDivInt(c.x, l.chunkW)*l.chunkW != c.x || floorDivInt(c.y, l.chunkH)*l.chunkH != c.y {
+		l.chunkW, l.chunkH = 0, 0
+		return
+	}
+	l.index[hash.EncodeGridKey(c.x/l.chunkW, c.y/l.chunkH)] = c
+}
+
+// ForEach calls fn for every chunk of the layer, row-major by position, so work done chunk by
+// chunk happens in the same order on every run.
+func (l *Layer) ForEach(fn func(chunk *Chunk)) {
+	for _, c := range l.chunks {
+		fn(c)
+	}
+}
+
+// sortChunks orders the chunks row-major by position.
+func (l *Layer) sortChunks() {
+	slices.SortFunc(l.chunks, func(a, b *Chunk) int {
+		if a.y != b.y {
+			return cmp.Compare(a.y, b.y)
+		}
+		return cmp.Compare(a.x, b.x)
+	})
+}
+
+// resetChunks clears the chunk index, keeping its memory for reuse.
+func (l *Layer) resetChunks() {
+	clear(l.index)
+	clear(l.chunks)
+	l.chunks = l.chunks[:0]
+	l.scratch = l.scratch[:0]
+	clear(l.dirty)
+	l.chunkW, l.chunkH = 0, 0
+}
+
+// query appends the chunks intersecting a region (in tile coordinates) to dst, in row-major
+// order of their position.
+func (l *Layer) query(dst []*Chunk, region Region) []*Chunk {
+	if region.IsEmpty() {
+		return dst
+	}
+
+	if l.chunkW <= 0 {
+		// Chunks are kept in the order Tiled wrote them, which is row-major.
+		for _, c := range l.chunks {
+			if c.intersects(region) {
+				dst = append(dst, c)
+			}
+		}
+		return dst
+	}
+
+	minX, maxX := floorDivInt(region.MinX, l.chunkW), floorDivInt(region.MaxX-1, l.chunkW)
+	minY, maxY := floorDivInt(region.MinY, l.chunkH), floorDivInt(region.MaxY-1, l.chunkH)
+
+	// Scan the chunks instead when the region covers more cells than there are chunks.
+	if len(l.index) < int((maxX-minX+1)*(maxY-minY+1)) {
+		for _, c := range l.chunks {
+			if c.intersects(region) {
+				dst = append(dst, c)
+			}
+		}
+		return dst
+	}
+
+	for cy := minY; cy <= maxY; cy++ {
+		for cx := minX; cx <= maxX; cx++ {
+			if c, ok := l.index[hash.EncodeGridKey(cx, cy)]; ok {
+				dst = append(dst, c)
+			}
+		}
+	}
+	return dst
+}
+
+// queryChunks returns the chunks of a layer intersecting a region, in row-major order.
+// The result is only valid until the next query on the same layer.
+func (tm *Map) queryChunks(layer int, region Region) []*Chunk {
+	l := tm.layers[layer]
+	l.scratch = l.query(l.scratch[:0], region)
+	return l.scratch
+}
+
+// ====================== Tile Lookup =====================
+
+// TileAt returns the tile of a layer at a tile coordinate, the same as it would be buffered,
+// without going through the buffered region. It works on finite and infinite maps alike and
+// only decodes the chunk holding the tile, so gameplay code can query single tiles, e.g. for
+// collision checks or picking, anywhere on the map. It returns false for empty cells, cells
+// outside the layer's chunks, and invalid layers.
+func (tm *Map) TileAt(layer int, x, y int32) (Data, bool) {
+	chunk, err := tm.chunkAt(layer, x, y)
+	if err != nil {
+		return Data{}, false
+	}
+
+	tile, ok := tm.getTileFromChunk(chunk, x, y)
+	if !ok {
+		return Data{}, false
+	}
+
+	offsetX, offsetY := tm.layerWorldOffset(layer)
+	tile.X += offsetX
+	tile.Y += offsetY
+	tile.LayerIdx = int32(layer)
+	return tile, true
+}
@@ -0,0 +1,45 @@
+package tilemap
+
+import (
+	"image"
+	"math"
+)
+
+// ====================== image.Rectangle =====================
+
+// Rectangle returns the region as an image.Rectangle in tile coordinates.
+func (r *Region) Rectangle() image.Rectangle {
+	return image.Rect(int(r.MinX), int(r.MinY), int(r.MaxX), int(r.MaxY))
+}
+
+// RegionFromRectangle returns the region covering an image.Rectangle in tile coordinates.
+func RegionFromRectangle(rect image.Rectangle) Region {
+	rect = rect.Canon()
+	return Region{
+		MinX: int32(rect.Min.X),
+		MinY: int32(rect.Min.Y),
+		MaxX: int32(rect.Max.X),
+		MaxY: int32(rect.Max.Y),
+	}
+}
+
+// Rectangle returns the smallest image.Rectangle in world coordinates containing the frame.
+func (f *Frame) Rectangle() image.Rectangle {
+	return image.Rect(
+		int(math.Floor(float64(f.bounds[0]))),
+		int(math.Floor(float64(f.bounds[1]))),
+		int(math.Ceil(float64(f.bounds[2]))),
+		int(math.Ceil(float64(f.bounds[3]))),
+	)
+}
+
+// SetRectangle sets the frame from an image.Rectangle in world coordinates.
+func (f *Frame) SetRectangle(rect image.Rectangle) {
+	rect = rect.Canon()
+	f.Set([4]float32{
+		float32(rect.Min.X),
+		float32(rect.Min.Y),
+		float32(rect.Max.X),
+		float32(rect.Max.Y),
+	})
+}
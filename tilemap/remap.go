@@ -0,0 +1,105 @@
+package tilemap
+
+import "github.com/adm87/tiled"
+
+// GIDHistogram counts how often each GID (without flip flags) occurs in a layer.
+// Empty cells are not counted. It is useful for building remap tables.
+func (tm *Map) GIDHistogram(layer int) (map[uint32]int, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
+	}
+
+	if layer < 0 || layer >= len(tm.layers) {
+		return nil, ErrLayerNotFound
+	}
+
+	var err error
+	histogram := make(map[uint32]int)
+
+	tm.layers[layer].ForEach(func(chunk *Chunk) {
+		if err != nil {
+			return
+		}
+		if err = chunk.decode(); err != nil {
+			return
+		}
+		for i := range chunk.len() {
+			if id := chunk.at(i) & tiled.GIDMask; id != 0 {
+				histogram[id]++
+			}
+		}
+	})
+
+	return histogram, err
+}
+
+// RemapGIDs replaces GIDs in bulk using a mapping table, e.g. to swap a summer tileset for a
+// winter variant sharing the same layout. Keys and values are GIDs without flip flags; the
+// flip flags of each cell are kept. When no layers are given, every layer is remapped.
+//
+// All chunks of the affected layers are decoded and rewritten in a single pass, and the
+// buffered frame is invalidated once. It returns the number of cells changed.
+func (tm *Map) RemapGIDs(table map[uint32]uint32, layers ...int) (int, error) {
+	if tm.Tmx == nil {
+		return 0, ErrNoTmxData
+	}
+
+	if len(layers) == 0 {
+		layers = make([]int, len(tm.layers))
+		for i := range layers {
+			layers[i] = i
+		}
+	}
+
+	for _, layer := range layers {
+		if layer < 0 || layer >= len(tm.layers) {
+			return 0, ErrLayerNotFound
+		}
+	}
+
+	var err error
+	changed := 0
+
+	for _, layer := range layers {
+		tm.layers[layer].ForEach(func(chunk *Chunk) {
+			if err != nil {
+				return
+			}
+			if err = chunk.decode(); err != nil {
+				return
+			}
+
+			remapped := false
+			for i := range chunk.len() {
+				gid := chunk.at(i)
+				to, ok := table[gid&tiled.GIDMask]
+				if !ok {
+					continue
+				}
+				to = to&tiled.GIDMask | gid&^tiled.GIDMask
+				if to == gid {
+					continue
+				}
+				chunk.set(i, to)
+				remapped = true
+				changed++
+
+				x := chunk.x + i%chunk.w
+				y := chunk.y + i/chunk.w
+				tm.notifyTileChange(layer, x, y, gid, to)
+			}
+
+			if remapped {
+				chunk.Flush()
+			}
+		})
+		if err != nil {
+			return changed, err
+		}
+	}
+
+	if changed > 0 {
+		tm.dirty = true
+	}
+	return changed, nil
+}
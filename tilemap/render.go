@@ -0,0 +1,182 @@
+package tilemap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/adm87/tiled"
+)
+
+// ImageProvider returns the image holding a tile of a tileset, by index in Tmx.Tilesets: the
+// tileset image, or the tile's own image in image collections. The tile is read from the
+// image's Tsx.TileBounds. It returns nil for images that aren't loaded, whose tiles are skipped.
+type ImageProvider func(tileset int, tileID uint32) image.Image
+
+// TilesetImages returns an ImageProvider for maps whose tilesets each use a single image,
+// indexed the same way as Tmx.Tilesets. Entries may be nil.
+func TilesetImages(images []image.Image) ImageProvider {
+	return func(tileset int, _ uint32) image.Image {
+		if tileset < 0 || tileset >= len(images) {
+			return nil
+		}
+		return images[tileset]
+	}
+}
+
+// ====================== Render =====================
+
+// RenderToImage composites every tile layer of a map into a new image, for minimaps,
+// thumbnails and previews rendered without a game engine. The image covers the map's tiles,
+// or the content of infinite maps, in map pixels, extended up and right so tiles taller or
+// wider than the map's tiles fit. Layers are drawn with their offsets, opacity and visibility.
+func RenderToImage(tmx *tiled.Tmx, images ImageProvider) (*image.RGBA, error) {
+	tm := NewMap()
+	if err := tm.SetTmx(tmx); err != nil {
+		return nil, err
+	}
+
+	region := Region{MaxX: tmx.Width, MaxY: tmx.Height}
+	if tmx.IsInfinite() {
+		bounds, ok, err := tm.ContentBounds()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return image.NewRGBA(image.Rectangle{}), nil
+		}
+		region = bounds
+	}
+
+	// Tiles are anchored at the bottom-left of their cell, so the largest overhang of any
+	// tileset is all the image needs to grow by.
+	var extraW, extraH int32
+	for i := range tmx.Tilesets {
+		if tsx := tmx.Tilesets[i].Tsx; tsx != nil {
+			extraW = max(extraW, tsx.TileWidth-tmx.TileWidth)
+			extraH = max(extraH, tsx.TileHeight-tmx.TileHeight)
+		}
+	}
+	rect := tm.tileRectToWorld(region.MinX, region.MinY, region.MaxX, region.MaxY)
+	rect[1] -= float32(extraH)
+	rect[2] += float32(extraW)
+	return tm.renderRect(rect, images)
+}
+
+// RenderRegionToImage composites the tiles of a region of a map into a new image covering
+// the region in map pixels. Parts of tiles outside the region are clipped.
+func RenderRegionToImage(tmx *tiled.Tmx, images ImageProvider, region Region) (*image.RGBA, error) {
+	tm := NewMap()
+	if err := tm.SetTmx(tmx); err != nil {
+		return nil, err
+	}
+	if region.IsEmpty() {
+		return image.NewRGBA(image.Rectangle{}), nil
+	}
+	return tm.renderRect(tm.tileRectToWorld(region.MinX, region.MinY, region.MaxX, region.MaxY), images)
+}
+
+// renderRect buffers a world rectangle of a map with the default coordinate system and draws
+// it into a new image.
+func (tm *Map) renderRect(rect [4]float32, images ImageProvider) (*image.RGBA, error) {
+	defer tm.Release()
+
+	tm.Frame().Set(rect)
+	if err := tm.BufferFrame(); err != nil {
+		return nil, err
+	}
+
+	bounds := tm.Frame().Rectangle()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	tm.DrawFrame(dst, images)
+	return dst, nil
+}
+
+// DrawFrame draws the buffered frame of the map into dst, the frame's top-left corner at the
+// origin of dst. It applies flips and tile offsets exactly as TileTransform describes them,
+// and layer opacity and visibility, sampling the nearest source pixel. Placeholder tiles fill
+// their cell with PlaceholderColor.
+//
+// The map must use its default coordinate system, and BufferFrame must have been called.
+func (tm *Map) DrawFrame(dst *image.RGBA, images ImageProvider) {
+	origin := tm.Frame().Rectangle().Min
+
+	itr := tm.Itr()
+	for tiles := itr.Next(); tiles != nil; tiles = itr.Next() {
+		alpha := itr.Visibility() * itr.Opacity()
+		for i := range tiles {
+			tile := &tiles[i]
+			if tile.IsPlaceholder() {
+				tm.drawPlaceholder(dst, tile, origin, alpha)
+				continue
+			}
+
+			tsx := tm.Tmx.Tilesets[tile.TsIdx].Tsx
+			if tsx == nil {
+				continue
+			}
+			img := images(tile.TsIdx, tile.TileID)
+			if img == nil {
+				continue
+			}
+
+			m := tm.TileTransform(tile, tsx)
+			m[4] -= float64(origin.X)
+			m[5] -= float64(origin.Y)
+			drawTile(dst, img, tsx.TileBounds(tile.TileID), m, alpha)
+		}
+	}
+}
+
+// drawPlaceholder fills the map cell of a placeholder tile with PlaceholderColor.
+func (tm *Map) drawPlaceholder(dst *image.RGBA, tile *Data, origin image.Point, alpha float32) {
+	x, y := int(math.Floor(float64(tile.X)))-origin.X, int(math.Floor(float64(tile.Y)))-origin.Y
+	cell := image.Rect(x, y, x+int(tm.Tmx.TileWidth), y+int(tm.Tmx.TileHeight))
+
+	draw.DrawMask(dst, cell, image.NewUniform(PlaceholderColor), image.Point{}, alphaMask(alpha), image.Point{}, draw.Over)
+}
+
+// drawTile composites the source rectangle of img onto dst through the transform m, which
+// maps tile-local source pixels to destination pixels.
+func drawTile(dst *image.RGBA, img image.Image, src image.Rectangle, m [6]float64, alpha float32) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 {
+		return
+	}
+	inv := [4]float64{m[3] / det, -m[1] / det, -m[2] / det, m[0] / det}
+
+	// Destination bounds of the transformed tile.
+	w, h := float64(src.Dx()), float64(src.Dy())
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range [4][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+		x := m[0]*p[0] + m[1]*p[1] + m[4]
+		y := m[2]*p[0] + m[3]*p[1] + m[5]
+		minX, minY = min(minX, x), min(minY, y)
+		maxX, maxY = max(maxX, x), max(maxY, y)
+	}
+
+	bounds := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).
+		Intersect(dst.Bounds())
+
+	mask := alphaMask(alpha)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Sample at the pixel center.
+			dx, dy := float64(x)+0.5-m[4], float64(y)+0.5-m[5]
+			sx := inv[0]*dx + inv[1]*dy
+			sy := inv[2]*dx + inv[3]*dy
+			if sx < 0 || sy < 0 || sx >= w || sy >= h {
+				continue
+			}
+
+			p := image.Pt(src.Min.X+int(sx), src.Min.Y+int(sy))
+			draw.DrawMask(dst, image.Rect(x, y, x+1, y+1), img, p, mask, image.Point{}, draw.Over)
+		}
+	}
+}
+
+func alphaMask(alpha float32) *image.Uniform {
+	return image.NewUniform(color.Alpha{A: uint8(math.Round(float64(max(0, min(alpha, 1))) * 255))})
+}
@@ -0,0 +1,41 @@
+package tilemap
+
+import (
+	"image"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adm87/tiled"
+)
+
+func TestRenderToImageOverhang(t *testing.T) {
+	// A 2x2 map of 16px tiles with two tilesets larger than its tiles: the image grows by the
+	// largest overhang on each axis, not by their sum.
+	const src = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="2" height="2" tilewidth="16" tileheight="16" infinite="0">
+ <tileset firstgid="1" name="wide" tilewidth="32" tileheight="24" tilecount="1" columns="1">
+  <image source="wide.png" width="32" height="24"/>
+ </tileset>
+ <tileset firstgid="2" name="tall" tilewidth="24" tileheight="48" tilecount="1" columns="1">
+  <image source="tall.png" width="24" height="48"/>
+ </tileset>
+ <layer id="1" name="Ground" width="2" height="2">
+  <data encoding="csv">1,2,0,0</data>
+ </layer>
+</map>`
+
+	loader := tiled.NewLoaderFS(fstest.MapFS{"map.tmx": {Data: []byte(src)}})
+	tmx, err := loader.LoadTmx("map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := RenderToImage(tmx, TilesetImages(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := image.Rect(0, 0, 32+16, 32+32)
+	if got := img.Bounds(); got.Dx() != want.Dx() || got.Dy() != want.Dy() {
+		t.Fatalf("image size = %dx%d, want %dx%d", got.Dx(), got.Dy(), want.Dx(), want.Dy())
+	}
+}
@@ -0,0 +1,139 @@
+package tilemap
+
+import "github.com/adm87/tiled"
+
+// ScriptView returns a plain view of the map built only from maps, slices, strings, bools,
+// int64 and float64 values, so scripting bridges (gopher-lua, goja) can expose map data
+// without reflection or per-game marshaling.
+//
+// The view is a snapshot; it does not change when the map is modified. It returns nil when
+// no Tmx is set.
+func (tm *Map) ScriptView() map[string]any {
+	if tm.Tmx == nil {
+		return nil
+	}
+
+	tmx := tm.Tmx
+
+	layers := make([]any, 0, len(tmx.Layers))
+	for i := range tmx.Layers {
+		l := &tmx.Layers[i]
+		layers = append(layers, map[string]any{
+			"index":      int64(i),
+			"id":         int64(l.ID),
+			"name":       l.Name,
+			"class":      l.Class,
+			"width":      int64(l.Width),
+			"height":     int64(l.Height),
+			"visible":    l.IsVisible(),
+			"locked":     l.IsLocked(),
+			"properties": scriptProperties(l.Properties),
+		})
+	}
+
+	groups := make([]any, 0, len(tmx.ObjectGroups))
+	for i := range tmx.ObjectGroups {
+		og := &tmx.ObjectGroups[i]
+
+		objects := make([]any, 0, len(og.Objects))
+		for j := range og.Objects {
+			objects = append(objects, scriptObject(&og.Objects[j]))
+		}
+
+		groups = append(groups, map[string]any{
+			"id":         int64(og.ID),
+			"name":       og.Name,
+			"class":      og.Class,
+			"visible":    og.IsVisible(),
+			"locked":     og.IsLocked(),
+			"objects":    objects,
+			"properties": scriptProperties(og.Properties),
+		})
+	}
+
+	tilesets := make([]any, 0, len(tmx.Tilesets))
+	for i := range tmx.Tilesets {
+		tilesets = append(tilesets, map[string]any{
+			"firstgid": int64(tmx.Tilesets[i].FirstGID),
+			"source":   tmx.Tilesets[i].Source,
+		})
+	}
+
+	return map[string]any{
+		"width":        int64(tmx.Width),
+		"height":       int64(tmx.Height),
+		"tilewidth":    int64(tmx.TileWidth),
+		"tileheight":   int64(tmx.TileHeight),
+		"infinite":     tmx.IsInfinite(),
+		"orientation":  tmx.Orientation.String(),
+		"renderorder":  tmx.RenderOrder.String(),
+		"layers":       layers,
+		"objectgroups": groups,
+		"tilesets":     tilesets,
+		"properties":   scriptProperties(tmx.Properties),
+	}
+}
+
+func scriptObject(obj *tiled.Object) map[string]any {
+	shape := "rectangle"
+	var points []float32
+
+	switch {
+	case obj.GID != 0:
+		shape = "tile"
+	case obj.Text != nil:
+		shape = "text"
+	case obj.IsPoint():
+		shape = "point"
+	case obj.IsEllipse():
+		shape = "ellipse"
+	case !obj.Polygon.IsEmpty():
+		shape = "polygon"
+		points = obj.Polygon.Points
+	case !obj.Polyline.IsEmpty():
+		shape = "polyline"
+		points = obj.Polyline.Points
+	}
+
+	view := map[string]any{
+		"id":         int64(obj.ID),
+		"name":       obj.Name,
+		"class":      obj.Class,
+		"shape":      shape,
+		"x":          float64(obj.X),
+		"y":          float64(obj.Y),
+		"width":      float64(obj.Width),
+		"height":     float64(obj.Height),
+		"rotation":   float64(obj.Rotation),
+		"gid":        int64(obj.GID),
+		"visible":    obj.IsVisible(),
+		"properties": scriptProperties(obj.Properties),
+	}
+
+	if points != nil {
+		list := make([]any, 0, len(points))
+		for _, p := range points {
+			list = append(list, float64(p))
+		}
+		view["points"] = list
+	}
+
+	if obj.Text != nil {
+		view["text"] = obj.Text.Content
+	}
+	return view
+}
+
+// scriptProperties flattens properties into a name to value map.
+// Class properties with members become nested maps.
+func scriptProperties(props []tiled.Property) map[string]any {
+	view := make(map[string]any, len(props))
+	for i := range props {
+		if len(props[i].Properties) > 0 {
+			view[props[i].Name] = scriptProperties(props[i].Properties)
+			continue
+		}
+		view[props[i].Name] = props[i].Value
+	}
+	return view
+}
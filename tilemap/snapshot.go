@@ -0,0 +1,68 @@
+package tilemap
+
+import (
+	"slices"
+
+	"github.com/adm87/tiled"
+)
+
+// ====================== Snapshot =====================
+
+// Snapshot is a read-only copy of the buffered frame of a map. A Map is not safe for
+// concurrent use: BufferFrame rewrites the tiles its iterators return. A snapshot owns its
+// tiles instead, so a render goroutine can draw a snapshot while the goroutine owning the map
+// buffers the next frame, and any number of goroutines can iterate the same snapshot.
+type Snapshot struct {
+	tiles     []Data
+	positions []int
+	layers    []*Layer
+	tmx       *tiled.Tmx
+	region    Region
+	bounds    [4]float32
+}
+
+// Snapshot copies the buffered frame of the map, with the state of its layers the iterator
+// accessors read, e.g. Visibility and Opacity. It must be called from the goroutine using the
+// map, after BufferFrame. The Tmx of the map is shared with the snapshot, not copied; it must
+// not be modified while the snapshot is in use.
+func (tm *Map) Snapshot() *Snapshot {
+	s := &Snapshot{
+		tiles:     slices.Clone(tm.cachedData),
+		positions: slices.Clone(tm.cachedPositions),
+		layers:    make([]*Layer, len(tm.layers)),
+		tmx:       tm.Tmx,
+		region:    tm.cachedRegion,
+		bounds:    tm.frame.bounds,
+	}
+	for i, l := range tm.layers {
+		s.layers[i] = &Layer{
+			visibility: l.visibility,
+			opacity:    l.opacity,
+			tint:       l.tint,
+			class:      l.class,
+			material:   l.material,
+		}
+	}
+	return s
+}
+
+// Itr returns an iterator over the tiles of the snapshot. Iterators of the same snapshot may
+// be used from different goroutines.
+func (s *Snapshot) Itr() Iterator {
+	return Iterator{
+		tiles:  s.tiles,
+		layers: s.positions,
+		meta:   s.layers,
+		tmx:    s.tmx,
+	}
+}
+
+// Region returns the buffered region the snapshot was taken of, in tile coordinates.
+func (s *Snapshot) Region() Region {
+	return s.region
+}
+
+// Bounds returns the frame bounds the snapshot was taken with, in world coordinates.
+func (s *Snapshot) Bounds() [4]float32 {
+	return s.bounds
+}
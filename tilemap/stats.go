@@ -0,0 +1,94 @@
+package tilemap
+
+import (
+	"slices"
+	"time"
+)
+
+// bufferStatsSamples is the number of BufferFrame calls kept for statistics.
+const bufferStatsSamples = 128
+
+// SlowBufferFunc is called when a BufferFrame call exceeds the slow buffer threshold, with
+// the time it took, the tile region it buffered and the number of chunks it converted.
+type SlowBufferFunc func(d time.Duration, region Region, chunks int)
+
+// ====================== BufferStats =====================
+
+// BufferStats summarizes the duration of the most recent BufferFrame calls that rebuilt the
+// cache. Calls that found the cache up to date are not counted.
+//
+// InvalidFrames counts the frames, buffered or not, whose bounds held NaN or infinite values.
+type BufferStats struct {
+	Samples int
+	P50     time.Duration
+	P95     time.Duration
+	Max     time.Duration
+
+	InvalidFrames int
+}
+
+// BufferStats returns statistics over the most recent cache rebuilds.
+func (tm *Map) BufferStats() BufferStats {
+	return tm.stats.summary()
+}
+
+// ResetBufferStats discards the recorded samples, e.g. after a loading screen.
+func (tm *Map) ResetBufferStats() {
+	tm.stats.count = 0
+	tm.stats.next = 0
+	tm.stats.invalidFrames = 0
+}
+
+// OnSlowBuffer registers a callback invoked whenever a BufferFrame call takes longer than
+// threshold. A nil callback disables it.
+func (tm *Map) OnSlowBuffer(threshold time.Duration, fn SlowBufferFunc) {
+	tm.stats.threshold = threshold
+	tm.stats.slow = fn
+}
+
+// bufferStats keeps the durations of recent rebuilds in a ring buffer.
+type bufferStats struct {
+	samples [bufferStatsSamples]time.Duration
+	next    int
+	count   int
+	chunks  int // chunks converted by the current call
+
+	invalidFrames int
+
+	threshold time.Duration
+	slow      SlowBufferFunc
+}
+
+func (s *bufferStats) record(d time.Duration, region Region) {
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % bufferStatsSamples
+	s.count = min(s.count+1, bufferStatsSamples)
+
+	if s.slow != nil && d > s.threshold {
+		s.slow(d, region, s.chunks)
+	}
+}
+
+func (s *bufferStats) summary() BufferStats {
+	if s.count == 0 {
+		return BufferStats{InvalidFrames: s.invalidFrames}
+	}
+
+	sorted := s.samples
+	window := sorted[:s.count]
+	slices.Sort(window)
+
+	return BufferStats{
+		Samples: s.count,
+		P50:     window[percentileIndex(s.count, 50)],
+		P95:     window[percentileIndex(s.count, 95)],
+		Max:     window[s.count-1],
+
+		InvalidFrames: s.invalidFrames,
+	}
+}
+
+// percentileIndex returns the nearest-rank index of the p-th percentile in n sorted samples.
+func percentileIndex(n, p int) int {
+	return max((n*p+99)/100-1, 0)
+}
@@ -0,0 +1,147 @@
+package tilemap
+
+import (
+	"image/color"
+
+	"github.com/adm87/tiled"
+)
+
+const DefaultColorProperty = "color" // tile or tileset property read by the default TileColorFunc
+
+// ====================== ChunkColor =====================
+
+// ChunkColor summarizes the colors of the tiles of a chunk, for minimaps and zoomed-out
+// rendering that draw a chunk as a single block.
+type ChunkColor struct {
+	Region   Region     // bounds of the chunk, in tile coordinates
+	Average  color.RGBA // mean color of the colored tiles
+	Dominant color.RGBA // most common color of the colored tiles
+	Coverage float32    // fraction of the chunk's cells with a colored tile, 0..1
+}
+
+// TileColorFunc returns the color representing a tile, e.g. the average color of its image.
+// Tiles it returns false for are left out of chunk summaries.
+type TileColorFunc func(tsIdx int, tileID uint32) (color.RGBA, bool)
+
+// SetTileColorFunc sets the function giving the color of tiles for chunk summaries.
+// A nil function restores the default, which reads the DefaultColorProperty of the tile
+// from its tileset, falling back to the same property on the tileset itself.
+func (tm *Map) SetTileColorFunc(fn TileColorFunc) {
+	tm.tileColorFunc = fn
+	for _, layer := range tm.layers {
+		for _, chunk := range layer.chunks {
+			chunk.summarized = false
+		}
+	}
+}
+
+// ChunkColors returns the color summaries of the chunks of a layer intersecting a region.
+//
+// Summaries are computed the first time a chunk is asked for, decoding it if needed, and
+// kept with the chunk until one of its tiles changes. The returned slice is reused
+// between calls.
+func (tm *Map) ChunkColors(layer int, region Region) ([]ChunkColor, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
+	}
+
+	if layer < 0 || layer >= len(tm.layers) {
+		return nil, ErrLayerNotFound
+	}
+
+	tm.chunkColors = tm.chunkColors[:0]
+	for _, chunk := range tm.queryChunks(layer, region) {
+		if !chunk.summarized {
+			if err := chunk.decode(); err != nil {
+				return nil, err
+			}
+			chunk.summary = tm.summarize(chunk)
+			chunk.summarized = true
+		}
+		tm.chunkColors = append(tm.chunkColors, chunk.summary)
+	}
+	return tm.chunkColors, nil
+}
+
+// summarize computes the color summary of a decoded chunk.
+func (tm *Map) summarize(chunk *Chunk) ChunkColor {
+	summary := ChunkColor{
+		Region: Region{MinX: chunk.x, MinY: chunk.y, MaxX: chunk.x + chunk.w, MaxY: chunk.y + chunk.h},
+	}
+
+	if tm.colorCounts == nil {
+		tm.colorCounts = make(map[color.RGBA]int)
+	}
+	clear(tm.colorCounts)
+
+	var r, g, b, a uint64
+	var colored, best int
+
+	n := chunk.len()
+	for i := int32(0); i < n; i++ {
+		c, ok := tm.tileColor(chunk.at(i))
+		if !ok {
+			continue
+		}
+
+		r += uint64(c.R)
+		g += uint64(c.G)
+		b += uint64(c.B)
+		a += uint64(c.A)
+		colored++
+
+		count := tm.colorCounts[c] + 1
+		tm.colorCounts[c] = count
+		if count > best {
+			best = count
+			summary.Dominant = c
+		}
+	}
+
+	if colored == 0 {
+		return summary
+	}
+
+	k := uint64(colored)
+	summary.Average = color.RGBA{R: uint8(r / k), G: uint8(g / k), B: uint8(b / k), A: uint8(a / k)}
+	if n > 0 {
+		summary.Coverage = float32(colored) / float32(n)
+	}
+	return summary
+}
+
+// tileColor resolves the color of a GID through the tile color function.
+func (tm *Map) tileColor(gid uint32) (color.RGBA, bool) {
+	tileID, _ := tiled.DecodeGID(gid)
+	if tileID == 0 {
+		return color.RGBA{}, false
+	}
+
+	_, tileID, tsIdx := tiled.TilesetByGID(tm.Tmx, tileID)
+	if tsIdx == -1 {
+		return color.RGBA{}, false
+	}
+
+	if tm.tileColorFunc != nil {
+		return tm.tileColorFunc(tsIdx, tileID)
+	}
+	return tm.propertyColor(tsIdx, tileID)
+}
+
+// propertyColor is the default TileColorFunc.
+func (tm *Map) propertyColor(tsIdx int, tileID uint32) (color.RGBA, bool) {
+	tsx := tm.Tmx.Tilesets[tsIdx].Tsx
+	if tsx == nil {
+		return color.RGBA{}, false
+	}
+
+	if tile := tsx.TileByID(tileID); tile != nil {
+		if prop := tiled.PropertyByName(tile.Properties, DefaultColorProperty); prop != nil {
+			return tiled.ParseColor(prop.Value)
+		}
+	}
+	if prop := tiled.PropertyByName(tsx.Properties, DefaultColorProperty); prop != nil {
+		return tiled.ParseColor(prop.Value)
+	}
+	return color.RGBA{}, false
+}
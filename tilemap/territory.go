@@ -0,0 +1,149 @@
+package tilemap
+
+import "github.com/adm87/tiled"
+
+// LabelFunc returns the label of a tile, such as its class or a property value.
+// Tiles labeled "" do not belong to any territory.
+type LabelFunc func(tsIdx int, tileID uint32) string
+
+// ====================== Territory =====================
+
+// Territory is a 4-connected group of cells sharing a label, e.g. a room or a biome.
+type Territory struct {
+	ID     int
+	Label  string
+	Cells  int
+	Bounds Region // in tile coordinates, max exclusive
+
+	CentroidX, CentroidY float32 // in world coordinates
+}
+
+// Territories is the result of labeling the connected regions of a layer.
+type Territories struct {
+	Region Region
+	List   []Territory
+	ids    []int32 // territory ID per cell, row-major, -1 when unlabeled
+}
+
+// LabelTerritories groups contiguous cells of a layer within a region whose tiles share a
+// label into territories, computing their bounds and centroids.
+func (tm *Map) LabelTerritories(layer int, region Region, label LabelFunc) (*Territories, error) {
+	if tm.Tmx == nil {
+		return nil, ErrNoTmxData
+	}
+
+	if layer < 0 || layer >= len(tm.layers) {
+		return nil, ErrLayerNotFound
+	}
+
+	width := max(region.MaxX-region.MinX, 0)
+	height := max(region.MaxY-region.MinY, 0)
+
+	// Resolve the label of every cell once.
+	labels := make([]string, width*height)
+	for y := region.MinY; y < region.MaxY; y++ {
+		for x := region.MinX; x < region.MaxX; x++ {
+			gid, err := tm.gidAt(layer, x, y)
+			if err != nil {
+				continue
+			}
+			tileID, _ := tiled.DecodeGID(gid)
+			if tileID == 0 {
+				continue
+			}
+			if _, id, tsIdx := tiled.TilesetByGID(tm.Tmx, tileID); tsIdx != -1 {
+				labels[(y-region.MinY)*width+(x-region.MinX)] = label(tsIdx, id)
+			}
+		}
+	}
+
+	t := &Territories{
+		Region: region,
+		ids:    make([]int32, width*height),
+	}
+	for i := range t.ids {
+		t.ids[i] = -1
+	}
+
+	var stack []int32
+	for start := range int32(len(labels)) {
+		if labels[start] == "" || t.ids[start] != -1 {
+			continue
+		}
+
+		id := int32(len(t.List))
+		terr := Territory{
+			ID:     int(id),
+			Label:  labels[start],
+			Bounds: Region{MinX: width, MinY: height, MaxX: -1, MaxY: -1},
+		}
+
+		var sumX, sumY float64
+		t.ids[start] = id
+		stack = append(stack[:0], start)
+
+		for len(stack) > 0 {
+			cell := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			cx, cy := cell%width, cell/width
+			terr.Cells++
+			terr.Bounds.MinX = min(terr.Bounds.MinX, cx)
+			terr.Bounds.MinY = min(terr.Bounds.MinY, cy)
+			terr.Bounds.MaxX = max(terr.Bounds.MaxX, cx+1)
+			terr.Bounds.MaxY = max(terr.Bounds.MaxY, cy+1)
+			sumX += float64(cx) + 0.5
+			sumY += float64(cy) + 0.5
+
+			for _, d := range cardinalOffsets {
+				nx, ny := cx+d[0], cy+d[1]
+				if nx < 0 || ny < 0 || nx >= width || ny >= height {
+					continue
+				}
+				n := ny*width + nx
+				if t.ids[n] == -1 && labels[n] == terr.Label {
+					t.ids[n] = id
+					stack = append(stack, n)
+				}
+			}
+		}
+
+		terr.Bounds.MinX += region.MinX
+		terr.Bounds.MinY += region.MinY
+		terr.Bounds.MaxX += region.MinX
+		terr.Bounds.MaxY += region.MinY
+		terr.CentroidX, terr.CentroidY = tm.tileToWorld(
+			float32(sumX/float64(terr.Cells))+float32(region.MinX),
+			float32(sumY/float64(terr.Cells))+float32(region.MinY),
+		)
+
+		t.List = append(t.List, terr)
+	}
+
+	return t, nil
+}
+
+// At returns the territory containing a tile coordinate, or nil if the cell is unlabeled.
+func (t *Territories) At(x, y int32) *Territory {
+	r := t.Region
+	if x < r.MinX || x >= r.MaxX || y < r.MinY || y >= r.MaxY {
+		return nil
+	}
+
+	id := t.ids[(y-r.MinY)*(r.MaxX-r.MinX)+(x-r.MinX)]
+	if id == -1 {
+		return nil
+	}
+	return &t.List[id]
+}
+
+// ByLabel returns every territory with the given label.
+func (t *Territories) ByLabel(label string) []*Territory {
+	var result []*Territory
+	for i := range t.List {
+		if t.List[i].Label == label {
+			result = append(result, &t.List[i])
+		}
+	}
+	return result
+}
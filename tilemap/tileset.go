@@ -0,0 +1,64 @@
+package tilemap
+
+import (
+	"errors"
+
+	"github.com/adm87/tiled"
+)
+
+var ErrTilesetLayout = errors.New("tileset layout mismatch")
+
+// TilesetSwapFunc is called after the Tsx attached to a tileset has been replaced.
+// Renderers use it to refresh texture bindings for the tileset index.
+type TilesetSwapFunc func(index int, old, new *tiled.Tsx)
+
+// OnTilesetSwap registers a callback invoked whenever SwapTsx replaces a tileset's Tsx. It
+// returns a func unregistering the callback, as OnTileChanged does.
+func (tm *Map) OnTilesetSwap(fn TilesetSwapFunc) func() {
+	if fn == nil {
+		return func() {}
+	}
+	return tm.tilesetSwapFuncs.add(fn)
+}
+
+// SwapTsx attaches a different Tsx to a tileset of the live map, e.g. to switch between day
+// and night art. The new Tsx must share the layout (tile size, tile count and columns) of
+// the one it replaces, since tile IDs in the map are not rewritten.
+//
+// Tile data is unaffected, so the buffered frame stays valid, except that placeholders of
+// the fallback tile are rebuffered; registered swap callbacks are notified so renderers can
+// rebind textures.
+func (tm *Map) SwapTsx(index int, tsx *tiled.Tsx) error {
+	if tm.Tmx == nil {
+		return ErrNoTmxData
+	}
+
+	if index < 0 || index >= len(tm.Tmx.Tilesets) {
+		return ErrTilesetNotFound
+	}
+
+	if tsx == nil {
+		return ErrInvalidTmxData
+	}
+
+	ts := &tm.Tmx.Tilesets[index]
+	old := ts.Tsx
+
+	if old != nil && !sameLayout(old, tsx) {
+		return ErrTilesetLayout
+	}
+
+	ts.Tsx = tsx
+	tm.invalidateFallback()
+	for _, cb := range tm.tilesetSwapFuncs.list {
+		cb.fn(index, old, tsx)
+	}
+	return nil
+}
+
+func sameLayout(a, b *tiled.Tsx) bool {
+	return a.TileWidth == b.TileWidth &&
+		a.TileHeight == b.TileHeight &&
+		a.TileCount == b.TileCount &&
+		a.Columns == b.Columns
+}
@@ -0,0 +1,34 @@
+package tilemap
+
+import "github.com/adm87/tiled"
+
+// TileTransform returns the full world transform of a buffered tile: the flips of its
+// FlipFlag, the tileset's tile offset, alignment of tiles taller than the map grid to the
+// bottom of their cell, and the tile's position.
+//
+// The matrix uses the layout of tiled.FlipFlag.Apply, so it can be loaded directly into any
+// affine-matrix renderer, e.g. ebiten.GeoM. It assumes the map's default coordinate system:
+// pixels, Y down. The flags are read the way orthogonal maps define them; use the
+// Map.TileTransform method for hexagonal maps.
+func TileTransform(tile *Data, tsx *tiled.Tsx, mapTileH int32) [6]float64 {
+	m := tile.FlipFlag.Apply(float64(tsx.TileWidth), float64(tsx.TileHeight))
+	return placeTile(m, tile, tsx, mapTileH)
+}
+
+// TileTransform returns the full world transform of a buffered tile, like the TileTransform
+// function, reading its flags the way the map's orientation defines them: on hexagonal maps
+// the diagonal and hex flags rotate the tile by 60 and 120 degrees.
+func (tm *Map) TileTransform(tile *Data, tsx *tiled.Tsx) [6]float64 {
+	if tm.Tmx.Orientation != tiled.OrientationHexagonal {
+		return TileTransform(tile, tsx, tm.Tmx.TileHeight)
+	}
+	m := tile.FlipFlag.ApplyHex(float64(tsx.TileWidth), float64(tsx.TileHeight))
+	return placeTile(m, tile, tsx, tm.Tmx.TileHeight)
+}
+
+// placeTile moves the flipped image of a tile to the tile's position.
+func placeTile(m [6]float64, tile *Data, tsx *tiled.Tsx, mapTileH int32) [6]float64 {
+	m[4] += float64(tile.X) + float64(tsx.TileOffset.X)
+	m[5] += float64(tile.Y) + float64(tsx.TileOffset.Y) - float64(tsx.TileHeight-mapTileH)
+	return m
+}
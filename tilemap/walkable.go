@@ -0,0 +1,211 @@
+package tilemap
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+
+	"github.com/adm87/tiled"
+)
+
+var ErrNoWalkableTile = errors.New("no walkable tile found")
+
+const (
+	DefaultSearchRadius int32 = 64 // in tiles, used by NearestWalkable on infinite maps
+	randomSampleTries         = 32
+)
+
+// BlockingFunc reports whether a tile blocks movement.
+// It receives the tileset index and the tile ID local to that tileset.
+type BlockingFunc func(tsIdx int, tileID uint32) bool
+
+// WalkRules describes which tiles of a map can be walked on.
+//
+// A cell is walkable when it lies inside the map and no checked layer holds a blocking tile
+// at it. Empty cells never block.
+type WalkRules struct {
+	Layers   []int        // layers checked for blocking tiles, nil checks every layer
+	Blocking BlockingFunc // nil treats every non-empty tile as blocking
+}
+
+// IsWalkable reports whether the tile coordinate is walkable under the given rules.
+func (tm *Map) IsWalkable(x, y int32, rules WalkRules) bool {
+	if tm.Tmx == nil {
+		return false
+	}
+
+	if rules.Layers == nil {
+		for i := range tm.layers {
+			if !tm.walkableIn(i, x, y, rules.Blocking) {
+				return false
+			}
+		}
+		return tm.inside(x, y)
+	}
+
+	for _, layer := range rules.Layers {
+		if !tm.walkableIn(layer, x, y, rules.Blocking) {
+			return false
+		}
+	}
+	return tm.inside(x, y)
+}
+
+// RandomWalkablePoint returns the world position of the center of a random walkable tile
+// within a region (in tile coordinates), e.g. for respawns and item drops. A nil rng uses the
+// global source of math/rand/v2.
+func (tm *Map) RandomWalkablePoint(region Region, rng *rand.Rand, rules WalkRules) (x, y float32, err error) {
+	if tm.Tmx == nil {
+		return 0, 0, ErrNoTmxData
+	}
+
+	int32N, intN := rand.Int32N, rand.IntN
+	if rng != nil {
+		int32N, intN = rng.Int32N, rng.IntN
+	}
+
+	width := region.MaxX - region.MinX
+	height := region.MaxY - region.MinY
+	if width <= 0 || height <= 0 {
+		return 0, 0, ErrNoWalkableTile
+	}
+
+	// Most regions are largely walkable, so try a few random cells before scanning.
+	for range randomSampleTries {
+		tx := region.MinX + int32N(width)
+		ty := region.MinY + int32N(height)
+		if tm.IsWalkable(tx, ty, rules) {
+			x, y = tm.tileCenter(tx, ty)
+			return x, y, nil
+		}
+	}
+
+	var cells [][2]int32
+	for ty := region.MinY; ty < region.MaxY; ty++ {
+		for tx := region.MinX; tx < region.MaxX; tx++ {
+			if tm.IsWalkable(tx, ty, rules) {
+				cells = append(cells, [2]int32{tx, ty})
+			}
+		}
+	}
+
+	if len(cells) == 0 {
+		return 0, 0, ErrNoWalkableTile
+	}
+
+	c := cells[intN(len(cells))]
+	x, y = tm.tileCenter(c[0], c[1])
+	return x, y, nil
+}
+
+// NearestWalkable returns the world position of the center of the walkable tile closest to a
+// world position, searching outward ring by ring. Cells on the corners of a ring are farther
+// than cells on the sides of the next rings, so the search continues until no further ring
+// can hold a closer cell. The search is bounded by the map size, or by DefaultSearchRadius
+// for infinite maps.
+func (tm *Map) NearestWalkable(x, y float32, rules WalkRules) (float32, float32, error) {
+	if tm.Tmx == nil {
+		return 0, 0, ErrNoTmxData
+	}
+
+	cx, cy := tm.worldToTile(x, y)
+	if tm.IsWalkable(cx, cy, rules) {
+		wx, wy := tm.tileCenter(cx, cy)
+		return wx, wy, nil
+	}
+
+	radius := DefaultSearchRadius
+	if !tm.Tmx.IsInfinite() {
+		radius = max(tm.Tmx.Width, tm.Tmx.Height)
+	}
+
+	found := false
+	var bestX, bestY int32
+	var bestDist float32
+	for r := int32(1); r <= radius; r++ {
+		if found && tm.ringDistance(r) > bestDist {
+			break
+		}
+
+		// Walk the square ring at distance r and keep the closest walkable cell.
+		for ty := cy - r; ty <= cy+r; ty++ {
+			step := 2 * r
+			if ty == cy-r || ty == cy+r {
+				step = 1
+			}
+			for tx := cx - r; tx <= cx+r; tx += step {
+				if !tm.IsWalkable(tx, ty, rules) {
+					continue
+				}
+				wx, wy := tm.tileCenter(tx, ty)
+				dist := hypot(wx-x, wy-y)
+				if !found || dist < bestDist {
+					found, bestX, bestY, bestDist = true, tx, ty, dist
+				}
+			}
+		}
+	}
+
+	if !found {
+		return 0, 0, ErrNoWalkableTile
+	}
+	wx, wy := tm.tileCenter(bestX, bestY)
+	return wx, wy, nil
+}
+
+// ringDistance returns a lower bound of the world distance from any position in a cell to the
+// center of a cell r rings away from it. Cell centers r rings apart are at least r times the
+// shortest step between neighboring cells apart, less the distance from the position to the
+// center of its own cell.
+func (tm *Map) ringDistance(r int32) float32 {
+	tw, th := float32(tm.Tmx.TileWidth), float32(tm.Tmx.TileHeight)
+
+	step, slack := min(tw, th), hypot(tw, th)/2
+	switch tm.Tmx.Orientation {
+	case tiled.OrientationIsometric:
+		step = min(tw, th) / math.Sqrt2
+	case tiled.OrientationStaggered, tiled.OrientationHexagonal:
+		// Staggered rows and columns advance by half a cell, shifted by up to half a cell.
+		step, slack = min(tw, th)/2, hypot(tw, th)
+	}
+	return (step*float32(r) - slack) / tm.ppu()
+}
+
+func (tm *Map) walkableIn(layer int, x, y int32, blocking BlockingFunc) bool {
+	gid, err := tm.gidAt(layer, x, y)
+	if err != nil {
+		return err == ErrTileNotFound
+	}
+
+	tileID, _ := tiled.DecodeGID(gid)
+	if tileID == 0 {
+		return true
+	}
+
+	if blocking == nil {
+		return false
+	}
+
+	_, tileID, tsIdx := tiled.TilesetByGID(tm.Tmx, tileID)
+	if tsIdx == -1 {
+		return true
+	}
+	return !blocking(tsIdx, tileID)
+}
+
+// inside reports whether a tile coordinate lies within any layer's chunks.
+func (tm *Map) inside(x, y int32) bool {
+	if !tm.Tmx.IsInfinite() {
+		return x >= 0 && y >= 0 && x < tm.Tmx.Width && y < tm.Tmx.Height
+	}
+	for i := range tm.layers {
+		if _, err := tm.chunkAt(i, x, y); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (tm *Map) tileCenter(x, y int32) (float32, float32) {
+	return tm.tileToWorld(float32(x)+0.5, float32(y)+0.5)
+}
@@ -0,0 +1,193 @@
+package tilemap
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/adm87/tiled"
+)
+
+// Warm decodes the chunks intersecting the given regions (in tile coordinates) and converts
+// their tiles ahead of time, e.g. behind a loading screen, so the first frames of gameplay
+// don't pay for cold chunks.
+func (tm *Map) Warm(regions ...Region) error {
+	if tm.Tmx == nil {
+		return ErrNoTmxData
+	}
+
+	for _, region := range regions {
+		for i := range tm.layers {
+			for _, chunk := range tm.queryChunks(i, region) {
+				if err := chunk.decode(); err != nil {
+					return err
+				}
+				tm.memoizeChunk(chunk, region)
+			}
+		}
+	}
+	return nil
+}
+
+// WarmAsync decodes the chunks intersecting the given regions on a background goroutine.
+//
+// Only the decompression runs in the background; the results are attached to the map when
+// the returned job is waited on, on the caller's goroutine. Chunks needed before then are
+// decoded on demand as usual.
+func (tm *Map) WarmAsync(regions ...Region) *WarmJob {
+	job := &WarmJob{
+		tm:         tm,
+		generation: tm.generation,
+		done:       make(chan struct{}),
+	}
+
+	if tm.Tmx == nil {
+		job.err = ErrNoTmxData
+		close(job.done)
+		return job
+	}
+
+	for _, region := range regions {
+		for i := range tm.layers {
+			for _, chunk := range tm.queryChunks(i, region) {
+				if !chunk.isDecoded {
+					job.pending = append(job.pending, warmChunk{
+						chunk:       chunk,
+						raw:         chunk.raw,
+						encoding:    chunk.encoding,
+						compression: chunk.compression,
+					})
+				}
+			}
+		}
+		job.regions = append(job.regions, region)
+	}
+
+	go job.run()
+	return job
+}
+
+// ====================== WarmJob =====================
+
+// WarmJob is a background chunk decode started by WarmAsync.
+type WarmJob struct {
+	tm         *Map
+	generation uint64
+	regions    []Region
+	pending    []warmChunk
+	done       chan struct{}
+	err        error
+	applied    bool
+}
+
+type warmChunk struct {
+	chunk       *Chunk
+	raw         string
+	encoding    tiled.Encoding
+	compression tiled.Compression
+	data        []uint32
+	err         error
+}
+
+func (j *WarmJob) run() {
+	defer close(j.done)
+
+	var wg sync.WaitGroup
+	for i := range j.pending {
+		wg.Add(1)
+		go func(wc *warmChunk) {
+			defer wg.Done()
+			wc.data, wc.err = tiled.DecodeContent(wc.raw, wc.encoding, wc.compression)
+		}(&j.pending[i])
+	}
+	wg.Wait()
+}
+
+// Done reports whether the background decode has finished without blocking.
+// Call Wait afterwards to attach the results to the map.
+func (j *WarmJob) Done() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until the background decode finishes and attaches the decoded chunks to the
+// map. It must be called from the goroutine using the map. Results are discarded if the
+// map's layers were rebuilt in the meantime.
+func (j *WarmJob) Wait() error {
+	<-j.done
+	if j.applied || j.err != nil {
+		return j.err
+	}
+	j.applied = true
+
+	if j.generation != j.tm.generation {
+		return nil
+	}
+
+	for i := range j.pending {
+		wc := &j.pending[i]
+		if wc.err != nil {
+			j.err = wc.err
+			continue
+		}
+		wc.chunk.attach(wc.data)
+	}
+
+	for _, region := range j.regions {
+		for i := range j.tm.layers {
+			for _, chunk := range j.tm.queryChunks(i, region) {
+				if chunk.isDecoded {
+					j.tm.memoizeChunk(chunk, region)
+				}
+			}
+		}
+	}
+	return j.err
+}
+
+// attach sets content decoded elsewhere, unless the chunk was decoded in the meantime.
+func (c *Chunk) attach(data []uint32) {
+	if c.isDecoded {
+		return
+	}
+
+	c.data = data
+	c.isDecoded = true
+	c.updateContent()
+
+	if c.layer != nil && c.layer.palette != nil {
+		c.pack(c.layer.palette)
+	}
+}
+
+// States of the memoized cells of a chunk.
+const (
+	memoUnknown uint8 = iota // not resolved yet
+	memoEmpty                // resolved to no tile
+	memoTile                 // resolved to the tile in memo
+)
+
+// memoizeChunk converts the tiles of a decoded chunk within a region ahead of time. The memo
+// is dense, indexed the same way as the chunk's cells, so looking a tile up is an index
+// rather than a hash; once allocated, tiles resolved by any lookup are kept in it too.
+func (tm *Map) memoizeChunk(chunk *Chunk, region Region) {
+	if n := int(chunk.len()); len(chunk.memoState) != n {
+		chunk.memo = slices.Grow(chunk.memo[:0], n)[:n]
+		chunk.memoState = slices.Grow(chunk.memoState[:0], n)[:n]
+		clear(chunk.memoState)
+	}
+
+	sX := max(region.MinX, chunk.x)
+	sY := max(region.MinY, chunk.y)
+	eX := min(region.MaxX, chunk.x+chunk.w)
+	eY := min(region.MaxY, chunk.y+chunk.h)
+
+	for y := sY; y < eY; y++ {
+		for x := sX; x < eX; x++ {
+			tm.getTileFromChunk(chunk, x, y)
+		}
+	}
+}
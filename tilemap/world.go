@@ -0,0 +1,104 @@
+package tilemap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adm87/tiled"
+)
+
+var ErrWorldMapNotFound = errors.New("world map not found")
+
+// ====================== World =====================
+
+// World streams the maps of a tiled.World as one map. Each world map gets its own Map, with
+// its origin placed so world positions are shared by every map: a tile's position is where it
+// lies in the world. Maps are built when a view first reaches them, and release their tile
+// cache when it moves away.
+type World struct {
+	World *tiled.World
+
+	maps    []*Map // parallel to World.Maps, nil until a view reaches the map
+	visible []*Map // maps returned by the last GetTiles
+	shown   []bool // parallel to maps, whether the map is in visible
+}
+
+// NewWorld creates a world streaming the maps of a tiled.World. The world maps need their
+// Tmx attached, e.g. by tiled.Loader.LoadWorld.
+func NewWorld(world *tiled.World) *World {
+	return &World{
+		World: world,
+		maps:  make([]*Map, len(world.Maps)),
+		shown: make([]bool, len(world.Maps)),
+	}
+}
+
+// GetTiles buffers the tiles of a view rectangle (minX, minY, maxX, maxY) in world pixels from
+// every map intersecting it, and returns those maps in the order the world lists them. Iterate
+// the tiles of each with Map.Itr. Maps that left the view since the last call are released.
+//
+// The returned slice is reused by the next call.
+func (w *World) GetTiles(view [4]float32) ([]*Map, error) {
+	clear(w.shown)
+	w.visible = w.visible[:0]
+
+	for i := range w.World.Maps {
+		wm := &w.World.Maps[i]
+		r := wm.Rect()
+		if r[0] >= view[2] || r[2] <= view[0] || r[1] >= view[3] || r[3] <= view[1] {
+			continue
+		}
+
+		tm, err := w.mapAt(i)
+		if err != nil {
+			return nil, err
+		}
+		tm.Frame().Set(view)
+		if err := tm.BufferFrame(); err != nil {
+			return nil, fmt.Errorf("%s: %w", wm.FileName, err)
+		}
+		w.visible = append(w.visible, tm)
+		w.shown[i] = true
+	}
+
+	for i, tm := range w.maps {
+		if tm != nil && !w.shown[i] {
+			tm.Release()
+		}
+	}
+	return w.visible, nil
+}
+
+// Map returns the Map of a world map by index in World.Maps, building it if needed.
+func (w *World) Map(index int) (*Map, error) {
+	if index < 0 || index >= len(w.World.Maps) {
+		return nil, ErrWorldMapNotFound
+	}
+	return w.mapAt(index)
+}
+
+func (w *World) mapAt(index int) (*Map, error) {
+	if index >= len(w.maps) {
+		// Maps added to the world after NewWorld, e.g. by MatchPatterns.
+		w.maps = append(w.maps, make([]*Map, len(w.World.Maps)-len(w.maps))...)
+		w.shown = append(w.shown, make([]bool, len(w.World.Maps)-len(w.shown))...)
+	}
+	if w.maps[index] != nil {
+		return w.maps[index], nil
+	}
+
+	wm := &w.World.Maps[index]
+	if wm.Tmx == nil {
+		return nil, fmt.Errorf("%s: %w", wm.FileName, ErrNoTmxData)
+	}
+
+	tm := NewMap()
+	if err := tm.SetTmx(wm.Tmx); err != nil {
+		return nil, fmt.Errorf("%s: %w", wm.FileName, err)
+	}
+	// The world's origin is the map's origin moved to the map's world position.
+	tm.SetOrigin(-float32(wm.X), -float32(wm.Y))
+
+	w.maps[index] = tm
+	return tm, nil
+}
@@ -0,0 +1,100 @@
+package tilemap
+
+import (
+	"slices"
+
+	"github.com/adm87/tiled"
+)
+
+// ====================== World Space =====================
+
+// ObjectToWorld returns a copy of an object in world coordinates, following the origin, Y
+// direction and pixels per unit of the map, so gameplay code doesn't need to convert Tmx
+// objects itself. Sizes and vertices are divided by the pixels per unit, like positions.
+//
+// The position of the copy is the object's anchor in world coordinates: its top-left corner,
+// or its bottom-left corner for tile objects. Vertices of polygons and polylines stay relative
+// to it. With Y up, vertices have their Y negated and rotations are negated, so the shape
+// looks the same; rectangles and ellipses still extend from the anchor the way they do in
+// Tiled, which is towards -Y. On isometric maps only the anchor is projected: sizes and
+// vertices stay along the tile axes, as Tiled stores them.
+func (tm *Map) ObjectToWorld(obj *tiled.Object) tiled.Object {
+	res := *obj
+	res.X, res.Y = tm.objectToPixel(obj.X, obj.Y)
+	return tm.pixelObjectToWorld(res)
+}
+
+// ObjectBounds returns the bounding box of an object in world coordinates, taking its
+// rotation into account, the same bounds GetObjects tests against.
+func (tm *Map) ObjectBounds(obj *tiled.Object) [4]float32 {
+	return tm.RectToWorld(tm.objectPixelBounds(obj))
+}
+
+// GetObjectsWorld returns the objects GetObjects returns for the same query, converted to
+// world coordinates with ObjectToWorld. The copies are independent of the Tmx; the returned
+// slice is reused by the next call.
+func (tm *Map) GetObjectsWorld(minX, minY, maxX, maxY float32, flags tiled.QueryFlag) []tiled.Object {
+	idx := &tm.objects
+	idx.world = idx.world[:0]
+	for _, obj := range tm.GetObjects(minX, minY, maxX, maxY, flags) {
+		idx.world = append(idx.world, tm.ObjectToWorld(obj))
+	}
+	return idx.world
+}
+
+// GetTileCollisionWorld returns the collision shapes of a buffered tile, flipped the same way
+// as the tile and placed in world coordinates, as ObjectToWorld places objects. The shapes are
+// positioned relative to the top-left corner of the tile's cell, as GetTileCollision
+// describes. The returned slice is owned by the caller.
+func (tm *Map) GetTileCollisionWorld(tile *Data) ([]tiled.Object, error) {
+	shapes, err := tm.GetTileCollision(tile.TsIdx, tile.TileID, tile.FlipFlag)
+	if err != nil || len(shapes) == 0 {
+		return nil, err
+	}
+
+	// Tile positions are the min corner of the cell in world coordinates, which is its
+	// bottom-left corner with Y up.
+	left, top := tm.FromWorld(tile.X, tile.Y)
+	if tm.yUp {
+		top -= float32(tm.Tmx.TileHeight)
+	}
+
+	res := make([]tiled.Object, len(shapes))
+	for i, shape := range shapes {
+		shape.X += left
+		shape.Y += top
+		res[i] = tm.pixelObjectToWorld(shape)
+	}
+	return res, nil
+}
+
+// pixelObjectToWorld converts an object positioned in map pixels to world coordinates.
+func (tm *Map) pixelObjectToWorld(obj tiled.Object) tiled.Object {
+	obj.X, obj.Y = tm.ToWorld(obj.X, obj.Y)
+
+	scale := 1 / tm.ppu()
+	obj.Width *= scale
+	obj.Height *= scale
+
+	scaleY := scale
+	if tm.yUp {
+		obj.Rotation = -obj.Rotation
+		scaleY = -scale
+	}
+	obj.Polygon = scalePoints(obj.Polygon, scale, scaleY)
+	obj.Polyline = scalePoints(obj.Polyline, scale, scaleY)
+	return obj
+}
+
+// scalePoints returns a copy of the vertices of a polygon scaled on each axis.
+func scalePoints(p tiled.Polygon, sx, sy float32) tiled.Polygon {
+	if p.IsEmpty() {
+		return p
+	}
+	pts := slices.Clone(p.Points)
+	for i := 0; i+1 < len(pts); i += 2 {
+		pts[i] *= sx
+		pts[i+1] *= sy
+	}
+	return tiled.Polygon{Points: pts}
+}
@@ -0,0 +1,271 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adm87/tiled/internal/enum"
+)
+
+// ======================================================
+// WangSet
+// ======================================================
+
+// WangSet is a set of terrains, in Tiled called Wang colors, and the tiles that transition
+// between them. Auto-tiling tools use it to pick tiles matching their neighbors.
+type WangSet struct {
+	Name  string   `xml:"name,attr"`
+	Class string   `xml:"class,attr,omitempty"`
+	Type  WangType `xml:"-"`
+	Tile  int32    `xml:"tile,attr"` // local tile ID representing the set, -1 for none
+
+	Colors     []WangColor `xml:"wangcolor,omitempty"`
+	Tiles      []WangTile  `xml:"wangtile,omitempty"`
+	Properties Properties  `xml:"properties>property,omitempty"`
+}
+
+func (ws *WangSet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	ws.Tile = -1
+
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "type":
+			val, err := enum.UnmarshalEnum[WangType](attr.Value)
+			if err != nil {
+				return err
+			}
+			ws.Type = val
+		}
+	}
+
+	type wangsetAlias WangSet
+	aux := (*wangsetAlias)(ws)
+
+	return d.DecodeElement(aux, &start)
+}
+
+// WangID returns the Wang ID of a local tile ID, and false if the tile is not part of the set.
+func (ws *WangSet) WangID(tileID uint32) (WangID, bool) {
+	for i := range ws.Tiles {
+		if ws.Tiles[i].TileID == tileID {
+			return ws.Tiles[i].WangID, true
+		}
+	}
+	return WangID{}, false
+}
+
+// Color returns the Wang color of a color index, as used by WangID, or nil for 0 and
+// indices out of range.
+func (ws *WangSet) Color(index uint8) *WangColor {
+	if index == 0 || int(index) > len(ws.Colors) {
+		return nil
+	}
+	return &ws.Colors[index-1]
+}
+
+// ColorAt returns the Wang color at a position of a tile, or nil if the tile is not part
+// of the set or has no color there.
+func (ws *WangSet) ColorAt(tileID uint32, pos WangPosition) *WangColor {
+	id, ok := ws.WangID(tileID)
+	if !ok {
+		return nil
+	}
+	return ws.Color(id.At(pos))
+}
+
+// TilesWith returns the local tile IDs of the set whose Wang ID matches id at every position
+// where id has a color. Positions where id is 0 match any color.
+func (ws *WangSet) TilesWith(id WangID) []uint32 {
+	var tiles []uint32
+	for i := range ws.Tiles {
+		if ws.Tiles[i].WangID.Matches(id) {
+			tiles = append(tiles, ws.Tiles[i].TileID)
+		}
+	}
+	return tiles
+}
+
+// WangSetByName returns the Wang set of a tileset with the given name, or nil.
+func (t *Tsx) WangSetByName(name string) *WangSet {
+	for i := range t.WangSets {
+		if t.WangSets[i].Name == name {
+			return &t.WangSets[i]
+		}
+	}
+	return nil
+}
+
+// ======================================================
+// WangColor
+// ======================================================
+
+// WangColor is a single terrain of a Wang set.
+type WangColor struct {
+	Name        string  `xml:"name,attr"`
+	Class       string  `xml:"class,attr,omitempty"`
+	Color       string  `xml:"color,attr"`       // display color in Tiled, #RRGGBB
+	Tile        int32   `xml:"tile,attr"`        // local tile ID representing the color, -1 for none
+	Probability float32 `xml:"probability,attr"` // relative chance of being picked, defaults to 1
+
+	Properties Properties `xml:"properties>property,omitempty"`
+}
+
+func (wc *WangColor) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	wc.Tile = -1
+	wc.Probability = 1
+
+	type wangcolorAlias WangColor
+	aux := (*wangcolorAlias)(wc)
+
+	return d.DecodeElement(aux, &start)
+}
+
+// ======================================================
+// WangTile
+// ======================================================
+
+// WangTile assigns Wang colors to the corners and edges of a tile.
+type WangTile struct {
+	TileID uint32 `xml:"tileid,attr"`
+	WangID WangID `xml:"wangid,attr"`
+}
+
+// ======================================================
+// WangID
+// ======================================================
+
+// WangPosition is a corner or edge of a tile, in the order Tiled stores them in a Wang ID.
+type WangPosition uint8
+
+const (
+	WangTop WangPosition = iota
+	WangTopRight
+	WangRight
+	WangBottomRight
+	WangBottom
+	WangBottomLeft
+	WangLeft
+	WangTopLeft
+)
+
+func (wp WangPosition) String() string {
+	switch wp {
+	case WangTop:
+		return "top"
+	case WangTopRight:
+		return "topright"
+	case WangRight:
+		return "right"
+	case WangBottomRight:
+		return "bottomright"
+	case WangBottom:
+		return "bottom"
+	case WangBottomLeft:
+		return "bottomleft"
+	case WangLeft:
+		return "left"
+	case WangTopLeft:
+		return "topleft"
+	default:
+		return "unknown"
+	}
+}
+
+func (wp WangPosition) IsValid() bool {
+	return wp >= WangTop && wp <= WangTopLeft
+}
+
+// IsCorner reports whether the position is a corner rather than an edge.
+func (wp WangPosition) IsCorner() bool {
+	return wp%2 == 1
+}
+
+// WangID holds the Wang color index of each corner and edge of a tile, indexed by
+// WangPosition. Color indices start at 1; 0 means no color.
+type WangID [8]uint8
+
+// At returns the color index at a position.
+func (id WangID) At(pos WangPosition) uint8 {
+	if !pos.IsValid() {
+		return 0
+	}
+	return id[pos]
+}
+
+// Corners returns the color indices of the top-right, bottom-right, bottom-left and top-left
+// corners.
+func (id WangID) Corners() [4]uint8 {
+	return [4]uint8{id[WangTopRight], id[WangBottomRight], id[WangBottomLeft], id[WangTopLeft]}
+}
+
+// Edges returns the color indices of the top, right, bottom and left edges.
+func (id WangID) Edges() [4]uint8 {
+	return [4]uint8{id[WangTop], id[WangRight], id[WangBottom], id[WangLeft]}
+}
+
+// Matches reports whether id has the colors of pattern at every position where pattern
+// has one.
+func (id WangID) Matches(pattern WangID) bool {
+	for i := range pattern {
+		if pattern[i] != 0 && pattern[i] != id[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (id WangID) String() string {
+	var sb strings.Builder
+	for i, c := range id {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.Itoa(int(c)))
+	}
+	return sb.String()
+}
+
+func (id *WangID) UnmarshalXMLAttr(attr xml.Attr) error {
+	parsed, err := ParseWangID(attr.Value)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+func (id WangID) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: id.String()}, nil
+}
+
+// ParseWangID parses a Wang ID as stored by Tiled: 8 comma separated color indices.
+// The legacy 32-bit hexadecimal form, 0x followed by one digit per position, is also accepted.
+func ParseWangID(s string) (WangID, error) {
+	var id WangID
+
+	if hex, ok := strings.CutPrefix(s, "0x"); ok {
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return id, fmt.Errorf("invalid wang id %q: %w", s, err)
+		}
+		for i := range id {
+			id[i] = uint8(v >> (4 * i) & 0xf)
+		}
+		return id, nil
+	}
+
+	parts := strings.Split(s, ",")
+	if len(parts) != len(id) {
+		return id, fmt.Errorf("invalid wang id %q: want %d values", s, len(id))
+	}
+	for i, p := range parts {
+		v, err := strconv.ParseUint(strings.TrimSpace(p), 10, 8)
+		if err != nil {
+			return id, fmt.Errorf("invalid wang id %q: %w", s, err)
+		}
+		id[i] = uint8(v)
+	}
+	return id, nil
+}
@@ -0,0 +1,171 @@
+package tiled
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+)
+
+var ErrWorldPattern = errors.New("invalid world pattern")
+
+// ======================================================
+// World
+// ======================================================
+
+// World is a Tiled world (.world): a set of maps laid out side by side in one coordinate
+// space, so large levels can be split into maps and streamed in as the view reaches them.
+type World struct {
+	Maps     []WorldMap     `json:"maps"`
+	Patterns []WorldPattern `json:"patterns,omitempty"`
+
+	// OnlyShowAdjacentMaps tells Tiled to only show the maps next to the one being edited.
+	OnlyShowAdjacentMaps bool `json:"onlyShowAdjacentMaps"`
+}
+
+// WorldMap is a map placed in a world. Positions and sizes are in world pixels.
+type WorldMap struct {
+	FileName string `json:"fileName"`
+	X        int32  `json:"x"`
+	Y        int32  `json:"y"`
+	Width    int32  `json:"width"`
+	Height   int32  `json:"height"`
+
+	Tmx *Tmx `json:"-"` // Attached map data, if loaded
+}
+
+// Rect returns the bounds of the map in world pixels as minX, minY, maxX, maxY. Maps listed
+// without a size take the size of their attached data.
+func (wm *WorldMap) Rect() [4]float32 {
+	w, h := wm.Width, wm.Height
+	if (w <= 0 || h <= 0) && wm.Tmx != nil {
+		w, h = wm.Tmx.Width*wm.Tmx.TileWidth, wm.Tmx.Height*wm.Tmx.TileHeight
+	}
+	return [4]float32{float32(wm.X), float32(wm.Y), float32(wm.X + w), float32(wm.Y + h)}
+}
+
+// WorldPattern places every map whose file name matches Regexp, which captures the map's
+// column and row. The map is placed at the column and row times the multipliers, plus the
+// offset.
+type WorldPattern struct {
+	Regexp      string `json:"regexp"`
+	MultiplierX int32  `json:"multiplierX"`
+	MultiplierY int32  `json:"multiplierY"`
+	OffsetX     int32  `json:"offsetX"`
+	OffsetY     int32  `json:"offsetY"`
+	MapWidth    int32  `json:"mapWidth"`
+	MapHeight   int32  `json:"mapHeight"`
+}
+
+// UnmarshalWorld populates a World from a .world file.
+func UnmarshalWorld(data []byte, w *World) error {
+	return json.Unmarshal(data, w)
+}
+
+// MapsIn returns the maps intersecting a rectangle in world pixels (minX, minY, maxX, maxY),
+// in the order the world lists them.
+func (w *World) MapsIn(rect [4]float32) []*WorldMap {
+	var maps []*WorldMap
+	for i := range w.Maps {
+		r := w.Maps[i].Rect()
+		if r[0] < rect[2] && r[2] > rect[0] && r[1] < rect[3] && r[3] > rect[1] {
+			maps = append(maps, &w.Maps[i])
+		}
+	}
+	return maps
+}
+
+// MatchPatterns places the files matching the world's patterns, in the order given, and
+// appends them to Maps. Files the world already lists are skipped. Names are matched as given,
+// which for worlds saved by Tiled is relative to the world file.
+func (w *World) MatchPatterns(names []string) error {
+	listed := make(map[string]bool, len(w.Maps))
+	for i := range w.Maps {
+		listed[w.Maps[i].FileName] = true
+	}
+
+	for _, p := range w.Patterns {
+		re, err := regexp.Compile(p.Regexp)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrWorldPattern, err)
+		}
+		if re.NumSubexp() < 2 {
+			return fmt.Errorf("%w: %q doesn't capture a column and a row", ErrWorldPattern, p.Regexp)
+		}
+
+		for _, name := range names {
+			if listed[name] {
+				continue
+			}
+			m := re.FindStringSubmatch(name)
+			if m == nil {
+				continue
+			}
+			col, err1 := strconv.ParseInt(m[1], 10, 32)
+			row, err2 := strconv.ParseInt(m[2], 10, 32)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+
+			w.Maps = append(w.Maps, WorldMap{
+				FileName: name,
+				X:        int32(col)*p.MultiplierX + p.OffsetX,
+				Y:        int32(row)*p.MultiplierY + p.OffsetY,
+				Width:    p.MapWidth,
+				Height:   p.MapHeight,
+			})
+			listed[name] = true
+		}
+	}
+	return nil
+}
+
+// ======================================================
+// Loader
+// ======================================================
+
+// LoadWorld loads the world stored at name and every map it places, attached to its WorldMap.
+// Maps are resolved relative to the world file. Pattern maps are found by listing the world's
+// directory, which needs a loader created with NewLoaderFS; other loaders only load the maps
+// the world lists.
+func (l *Loader) LoadWorld(name string) (*World, error) {
+	name = path.Clean(name)
+
+	data, err := l.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &World{}
+	if err := UnmarshalWorld(data, w); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if len(w.Patterns) > 0 && l.fsys != nil {
+		entries, err := fs.ReadDir(l.fsys, path.Dir(name))
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+		if err := w.MatchPatterns(names); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	for i := range w.Maps {
+		tmx, err := l.LoadTmx(ResolvePath(name, w.Maps[i].FileName))
+		if err != nil {
+			return nil, err
+		}
+		w.Maps[i].Tmx = tmx
+	}
+	return w, nil
+}